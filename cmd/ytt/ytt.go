@@ -21,6 +21,6 @@ func main() {
 	err := command.Execute()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "ytt: Error: %s\n", uierrs.NewMultiLineError(err))
-		os.Exit(1)
+		os.Exit(cmd.ExitCode(err))
 	}
 }
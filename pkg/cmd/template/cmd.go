@@ -10,8 +10,10 @@ import (
 	"github.com/vmware-tanzu/carvel-ytt/pkg/cmd/ui"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/files"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/schema"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/validations"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/workspace"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/workspace/datavalues"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/workspace/ref"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/yamlmeta"
 )
 
@@ -23,16 +25,25 @@ type Options struct {
 	IgnoreUnknownComments   bool
 	ImplicitMapKeyOverrides bool
 
-	StrictYAML   bool
-	Debug        bool
-	InspectFiles bool
+	StrictYAML       bool
+	Debug            bool
+	InspectFiles     bool
+	AllowEnvDefaults []string
 
 	BulkFilesSourceOpts    BulkFilesSourceOpts
 	RegularFilesSourceOpts RegularFilesSourceOpts
 	FileMarksOpts          FileMarksOpts
 	DataValuesFlags        DataValuesFlags
+
+	// OpenAPITransform (optional) post-processes each OpenAPI document produced when inspecting the data values schema
+	// (e.g. to add "servers" or "security"), before it is serialized.
+	OpenAPITransform OpenAPITransform
 }
 
+// OpenAPITransform is given an OpenAPI document (as assembled from the data values schema), and returns the document
+// to output in its place (possibly, the same one, modified).
+type OpenAPITransform func(doc *yamlmeta.Document) (*yamlmeta.Document, error)
+
 type Input struct {
 	Files []*files.File
 }
@@ -59,7 +70,7 @@ func NewOptions() *Options {
 	var opts files.SymlinkAllowOpts
 	return &Options{
 		RegularFilesSourceOpts: RegularFilesSourceOpts{SymlinkAllowOpts: &opts},
-		DataValuesFlags:        DataValuesFlags{SymlinkAllowOpts: &opts},
+		DataValuesFlags:        DataValuesFlags{SymlinkAllowOpts: &opts, InspectSchemaEmptyPaths: true},
 	}
 }
 
@@ -72,6 +83,8 @@ func (o *Options) BindFlags(cmdFlags CmdFlags) {
 	cmdFlags.BoolVarP(&o.StrictYAML, "strict", "s", false, "Configure to use _strict_ YAML subset")
 	cmdFlags.BoolVar(&o.Debug, "debug", false, "Enable debug output")
 	cmdFlags.BoolVar(&o.InspectFiles, "files-inspect", false, "Determine the set of files that would be processed and display that result")
+	cmdFlags.StringArrayVar(&o.AllowEnvDefaults, "allow-env-defaults", nil,
+		"Allow reading the named environment variables via @ytt:env (e.g. to set a default in schema) (can be specified multiple times)")
 
 	o.BulkFilesSourceOpts.Set(cmdFlags)
 	o.RegularFilesSourceOpts.Set(cmdFlags)
@@ -101,7 +114,7 @@ func (o *Options) Run() error {
 	return o.pickSource(srcs, func(s FileSource) bool { return s.HasOutput() }).Output(out)
 }
 
-func (o *Options) RunWithFiles(in Input, ui ui.UI) Output {
+func (o *Options) RunWithFiles(in Input, ui ui.UI) (out Output) {
 	var err error
 
 	in.Files, err = o.FileMarksOpts.Apply(in.Files)
@@ -116,19 +129,53 @@ func (o *Options) RunWithFiles(in Input, ui ui.UI) Output {
 		return o.inspectFiles(rootLibrary)
 	}
 
+	if o.DataValuesFlags.ValidationOnly {
+		err := o.checkValidationOnly()
+		if err != nil {
+			return Output{Err: err}
+		}
+	}
+
 	valuesOverlays, libraryValuesOverlays, err := o.DataValuesFlags.AsOverlays(o.StrictYAML)
 	if err != nil {
 		return Output{Err: err}
 	}
 
+	validationMessages, err := o.DataValuesFlags.ValidationMessages(o.StrictYAML)
+	if err != nil {
+		return Output{Err: err}
+	}
+
+	jsonSchema, err := o.DataValuesFlags.JSONSchema(o.StrictYAML)
+	if err != nil {
+		return Output{Err: err}
+	}
+
 	libraryExecutionFactory := workspace.NewLibraryExecutionFactory(
 		ui,
 		workspace.TemplateLoaderOpts{
 			IgnoreUnknownComments:   o.IgnoreUnknownComments,
 			ImplicitMapKeyOverrides: o.ImplicitMapKeyOverrides,
 			StrictYAML:              o.StrictYAML,
+			AllowedEnvVars:          o.AllowEnvDefaults,
 		},
-		o.DataValuesFlags.SkipValidation)
+		o.DataValuesFlags.SkipValidation).
+		WithValidationMessages(validationMessages).
+		ThatRunsExperimentalValidations(o.DataValuesFlags.ExperimentalValidations).
+		WithValidationPaths(validations.PathFilter{Include: o.DataValuesFlags.ValidationInclude, Exclude: o.DataValuesFlags.ValidationExclude}).
+		ThatValidatesFormats(o.DataValuesFlags.ValidateFormats)
+
+	if o.DataValuesFlags.ValidationReportFile != "" {
+		report := &validationReport{}
+		libraryExecutionFactory = libraryExecutionFactory.WithValidationReporter(report.add)
+		// report validations, whether or not they (or anything else) failed.
+		defer func() {
+			err := report.WriteToFile(o.DataValuesFlags.ValidationReportFile)
+			if err != nil && out.Err == nil {
+				out.Err = err
+			}
+		}()
+	}
 
 	libraryCtx := workspace.LibraryExecutionContext{Current: rootLibrary, Root: rootLibrary}
 	rootLibraryExecution := libraryExecutionFactory.New(libraryCtx)
@@ -138,15 +185,26 @@ func (o *Options) RunWithFiles(in Input, ui ui.UI) Output {
 		return Output{Err: err}
 	}
 
+	if o.DataValuesFlags.InspectSchema && o.DataValuesFlags.InspectSchemaLibrary != "" {
+		return o.inspectLibrarySchema(rootLibraryExecution, schema, librarySchemas, valuesOverlays, libraryValuesOverlays, in.Files)
+	}
+
 	if o.DataValuesFlags.InspectSchema {
-		return o.inspectSchema(schema)
+		var values *datavalues.Envelope
+		if o.DataValuesFlags.InspectSchemaWithValues || o.DataValuesFlags.InspectSchemaInfer {
+			values, _, err = rootLibraryExecution.Values(valuesOverlays, schema)
+			if err != nil {
+				return Output{Err: err}
+			}
+		}
+		return o.inspectSchema(schema, values, in.Files)
 	}
 
 	schemaType, err := o.RegularFilesSourceOpts.OutputType.Schema()
 	if err != nil {
 		return Output{Err: err}
 	}
-	if schemaType == RegularFilesOutputTypeOpenAPI {
+	if schemaType != RegularFilesOutputTypeNone {
 		return Output{Err: fmt.Errorf("Output type currently only supported for data values schema (i.e. include --data-values-schema-inspect)")}
 	}
 
@@ -155,8 +213,24 @@ func (o *Options) RunWithFiles(in Input, ui ui.UI) Output {
 		return Output{Err: err}
 	}
 
+	if jsonSchema != nil && !o.DataValuesFlags.SkipValidation {
+		err = checkAgainstJSONSchema(jsonSchema, values)
+		if err != nil {
+			return Output{Err: err}
+		}
+	}
+
 	libraryValues = append(libraryValues, libraryValuesOverlays...)
 
+	if o.DataValuesFlags.ValidationOnly {
+		// data values are valid (or Values() would have failed): only those of private libraries remain.
+		err = rootLibraryExecution.ValidateLibraryValues(libraryValues, librarySchemas)
+		if err != nil {
+			return Output{Err: err}
+		}
+		return Output{}
+	}
+
 	if o.DataValuesFlags.Inspect {
 		return o.inspectDataValues(values)
 	}
@@ -169,6 +243,29 @@ func (o *Options) RunWithFiles(in Input, ui ui.UI) Output {
 	return Output{Files: result.Files, DocSet: result.DocSet}
 }
 
+// checkAgainstJSONSchema reports an error if (the root library's) data values do not conform to "jsonSchema".
+// Such an error is a validations.CheckError, just like that of data values that fail their validations.
+func checkAgainstJSONSchema(jsonSchema *schema.JSONSchema, values *datavalues.Envelope) error {
+	chk := jsonSchema.Check(values.Doc)
+	if chk.HasInvalidations() {
+		return fmt.Errorf("Validating final data values:\n%w", validations.CheckError{Check: chk})
+	}
+	return nil
+}
+
+// checkValidationOnly reports an error if --validation-only is combined with flags it contradicts.
+func (o *Options) checkValidationOnly() error {
+	switch {
+	case o.DataValuesFlags.SkipValidation:
+		return fmt.Errorf("Cannot both validate only (--validation-only) and skip validation (--dangerous-data-values-disable-validation)")
+	case o.RegularFilesSourceOpts.outputDir != "" || o.RegularFilesSourceOpts.OutputFiles != "":
+		return fmt.Errorf("Validating only (--validation-only) produces no output files; remove --output-files (or --dangerous-emptied-output-directory)")
+	case o.DataValuesFlags.Inspect || o.DataValuesFlags.InspectSchema:
+		return fmt.Errorf("Validating only (--validation-only) cannot be combined with inspecting data values or their schema")
+	}
+	return nil
+}
+
 func (o *Options) inspectDataValues(values *datavalues.Envelope) Output {
 	return Output{
 		DocSet: &yamlmeta.DocumentSet{
@@ -177,21 +274,244 @@ func (o *Options) inspectDataValues(values *datavalues.Envelope) Output {
 	}
 }
 
-func (o *Options) inspectSchema(dataValuesSchema *datavalues.Schema) Output {
+// inspectLibrarySchema reports the schema of the private library given via --data-values-schema-inspect-library,
+// once the schema and data values the root library addresses to it have been applied.
+func (o *Options) inspectLibrarySchema(rootLibraryExecution *workspace.LibraryExecution, rootSchema *datavalues.Schema,
+	librarySchemas []*datavalues.SchemaEnvelope, valuesOverlays, libraryValuesOverlays []*datavalues.Envelope, inputFiles []*files.File) Output {
+
+	libRefs, err := ref.LibraryRefExtractor{}.FromStr(o.DataValuesFlags.InspectSchemaLibrary)
+	if err != nil {
+		return Output{Err: fmt.Errorf("Inspecting schema of library '%s': %s", o.DataValuesFlags.InspectSchemaLibrary, err)}
+	}
+	if len(libRefs) != 1 {
+		return Output{Err: fmt.Errorf("Inspecting schema of library '%s': Expected a single library (e.g. '@lib'), but got %d", o.DataValuesFlags.InspectSchemaLibrary, len(libRefs))}
+	}
+
+	libSchema, err := rootLibraryExecution.LibrarySchema(libRefs[0], librarySchemas)
+	if err != nil {
+		return Output{Err: fmt.Errorf("Inspecting schema of library '%s': %s", o.DataValuesFlags.InspectSchemaLibrary, err)}
+	}
+
+	var libValues *datavalues.Envelope
+	if o.DataValuesFlags.InspectSchemaWithValues || o.DataValuesFlags.InspectSchemaInfer {
+		_, libraryValues, err := rootLibraryExecution.Values(valuesOverlays, rootSchema)
+		if err != nil {
+			return Output{Err: err}
+		}
+		libraryValues = append(libraryValues, libraryValuesOverlays...)
+
+		libValues, err = rootLibraryExecution.LibraryValues(libRefs[0], libSchema, libraryValues)
+		if err != nil {
+			return Output{Err: fmt.Errorf("Inspecting schema of library '%s': %s", o.DataValuesFlags.InspectSchemaLibrary, err)}
+		}
+	}
+	return o.inspectSchema(libSchema, libValues, inputFiles)
+}
+
+func (o *Options) inspectSchema(dataValuesSchema *datavalues.Schema, values *datavalues.Envelope, inputFiles []*files.File) Output {
 	format, err := o.RegularFilesSourceOpts.OutputType.Schema()
 	if err != nil {
 		return Output{Err: err}
 	}
-	if format == RegularFilesOutputTypeOpenAPI {
-		openAPIDoc := schema.NewOpenAPIDocument(dataValuesSchema.GetDocumentType())
+	if format == RegularFilesOutputTypeOpenAPI || format == RegularFilesOutputTypeOpenAPI31 || format == RegularFilesOutputTypeMarkdown || format == RegularFilesOutputTypeTS {
+		docType := dataValuesSchema.GetDocumentType()
+		if o.DataValuesFlags.InspectSchemaInfer {
+			docType, err = schema.InferTypeFromExample(values.Doc)
+			if err != nil {
+				return Output{Err: err}
+			}
+		}
+		err = schema.CheckOneOfLengths(docType)
+		if err != nil {
+			return Output{Err: err}
+		}
+		if o.DataValuesFlags.InspectSchemaCheckExamples {
+			err := schema.CheckExamples(docType)
+			if err != nil {
+				return Output{Err: err}
+			}
+		}
+		if values != nil {
+			schema.SetDefaultValuesFrom(docType, values.Doc)
+		}
+		openAPIDoc := schema.NewOpenAPIDocument(docType)
+		if format == RegularFilesOutputTypeOpenAPI31 {
+			openAPIDoc = openAPIDoc.WithVersion(schema.OpenAPIVersion31)
+		}
+		openAPIDoc, err = openAPIDoc.WithSchemaName(o.DataValuesFlags.openAPISchemaName())
+		if err != nil {
+			return Output{Err: err}
+		}
+		if o.DataValuesFlags.InspectSchemaInferMaxLen {
+			openAPIDoc = openAPIDoc.WithInferredStringMaxLength()
+		}
+		if o.DataValuesFlags.InspectSchemaValidations {
+			openAPIDoc = openAPIDoc.WithValidationExtensions()
+		}
+		if o.DataValuesFlags.InspectSchemaAddlProps {
+			openAPIDoc = openAPIDoc.WithAdditionalProperties()
+		}
+		if o.DataValuesFlags.InspectSchemaSortKeys {
+			openAPIDoc = openAPIDoc.WithSortedProperties()
+		}
+		if o.DataValuesFlags.InspectSchemaNoDeprecated {
+			openAPIDoc = openAPIDoc.WithoutDeprecated()
+		}
+		if o.DataValuesFlags.InspectSchemaDefaultsAsEx {
+			openAPIDoc = openAPIDoc.WithDefaultsAsExamples()
+		}
+		if o.DataValuesFlags.InspectSchemaXOrder {
+			openAPIDoc = openAPIDoc.WithPropertyOrder()
+		}
+		if o.DataValuesFlags.InspectSchemaNoExtensions {
+			openAPIDoc = openAPIDoc.WithoutExtensions()
+		}
+		if o.DataValuesFlags.InspectSchemaKeyTitles {
+			openAPIDoc = openAPIDoc.WithTitlesFromKeys()
+		}
+		if o.DataValuesFlags.InspectSchemaParameters {
+			openAPIDoc = openAPIDoc.WithParameters()
+		}
+		if !o.DataValuesFlags.InspectSchemaEmptyPaths {
+			openAPIDoc = openAPIDoc.WithoutEmptyPaths()
+		}
+		if o.DataValuesFlags.InspectSchemaDescComments {
+			comments, err := o.leadingComments(inputFiles)
+			if err != nil {
+				return Output{Err: err}
+			}
+			openAPIDoc = openAPIDoc.WithDescriptionsFromComments(comments)
+		}
+		if (format == RegularFilesOutputTypeMarkdown || format == RegularFilesOutputTypeTS) && o.DataValuesFlags.InspectSchemaPointer != "" {
+			return Output{Err: fmt.Errorf("Inspecting part of the schema (--data-values-schema-inspect-pointer) is only supported in OpenAPI format")}
+		}
+		if (format == RegularFilesOutputTypeMarkdown || format == RegularFilesOutputTypeTS) && o.DataValuesFlags.InspectSchemaDiffFile != "" {
+			return Output{Err: fmt.Errorf("Comparing the schema with a baseline (--openapi-diff) is only supported in OpenAPI format")}
+		}
+		if format == RegularFilesOutputTypeMarkdown {
+			markdown := schema.NewMarkdownDocument(openAPIDoc).AsBytes()
+			return Output{
+				Files: []files.OutputFile{files.NewOutputFile("data-values-schema.md", markdown, files.TypeText)},
+			}
+		}
+		if format == RegularFilesOutputTypeTS {
+			typeScript := schema.NewTypeScriptDocument(openAPIDoc).AsBytes()
+			return Output{
+				Files: []files.OutputFile{files.NewOutputFile("data-values-schema.ts", typeScript, files.TypeText)},
+			}
+		}
+		if o.DataValuesFlags.InspectSchemaParameters && (o.DataValuesFlags.InspectSchemaPointer != "" || o.DataValuesFlags.InspectSchemaSplit) {
+			return Output{Err: fmt.Errorf("Describing data values as parameters (--openapi-parameters) cannot be combined with inspecting part of the schema (--data-values-schema-inspect-pointer) or splitting it (--openapi-split-components)")}
+		}
+		if o.DataValuesFlags.InspectSchemaDiffFile != "" {
+			if o.DataValuesFlags.InspectSchemaPointer != "" || o.DataValuesFlags.InspectSchemaSplit {
+				return Output{Err: fmt.Errorf("Comparing the schema with a baseline (--openapi-diff) cannot be combined with inspecting part of the schema (--data-values-schema-inspect-pointer) or splitting it (--openapi-split-components)")}
+			}
+			return o.diffOpenAPIDocument(openAPIDoc)
+		}
+		if o.DataValuesFlags.InspectSchemaPointer != "" {
+			if o.DataValuesFlags.InspectSchemaSplit {
+				return Output{Err: fmt.Errorf("Inspecting part of the schema (--data-values-schema-inspect-pointer) cannot be combined with splitting it (--openapi-split-components)")}
+			}
+			doc, err := openAPIDoc.AsDocumentAt(o.DataValuesFlags.InspectSchemaPointer)
+			if err != nil {
+				return Output{Err: err}
+			}
+			doc, err = o.transformOpenAPI(doc)
+			if err != nil {
+				return Output{Err: err}
+			}
+			return Output{DocSet: &yamlmeta.DocumentSet{Items: []*yamlmeta.Document{doc}}}
+		}
+		if o.DataValuesFlags.InspectSchemaSplit {
+			return o.splitOpenAPIDocument(openAPIDoc)
+		}
+		doc, err := o.transformOpenAPI(openAPIDoc.AsDocument())
+		if err != nil {
+			return Output{Err: err}
+		}
 		return Output{
 			DocSet: &yamlmeta.DocumentSet{
-				Items: []*yamlmeta.Document{openAPIDoc.AsDocument()},
+				Items: []*yamlmeta.Document{doc},
 			},
 		}
 	}
-	return Output{Err: fmt.Errorf("Data values schema export only supported in OpenAPI v3 format; specify format with --output=%s flag",
-		RegularFilesOutputTypeOpenAPI)}
+	return Output{Err: fmt.Errorf("Data values schema export only supported in OpenAPI v3, Markdown or TypeScript format; specify format with --output=%s (or --output=%s, or --output=%s) flag",
+		RegularFilesOutputTypeOpenAPI, RegularFilesOutputTypeMarkdown, RegularFilesOutputTypeTS)}
+}
+
+// splitOpenAPIDocument renders "openAPIDoc" as several files (to be written via --output-files); on standard output,
+// they appear as a sequence of documents.
+func (o *Options) splitOpenAPIDocument(openAPIDoc *schema.OpenAPIDocument) Output {
+	out := Output{DocSet: &yamlmeta.DocumentSet{}}
+	for _, file := range openAPIDoc.AsSplitDocuments() {
+		doc, err := o.transformOpenAPI(file.Document)
+		if err != nil {
+			return Output{Err: err}
+		}
+		fileBs, err := (&yamlmeta.DocumentSet{Items: []*yamlmeta.Document{doc}}).AsBytes()
+		if err != nil {
+			return Output{Err: err}
+		}
+		out.Files = append(out.Files, files.NewOutputFile(file.RelativePath, fileBs, files.TypeYAML))
+		out.DocSet.Items = append(out.DocSet.Items, doc)
+	}
+	return out
+}
+
+// diffOpenAPIDocument compares "openAPIDoc" with the baseline given via --openapi-diff, reporting the changes: as an
+// error, if any is breaking.
+func (o *Options) diffOpenAPIDocument(openAPIDoc *schema.OpenAPIDocument) Output {
+	baseline, err := o.DataValuesFlags.OpenAPIBaseline(o.StrictYAML)
+	if err != nil {
+		return Output{Err: err}
+	}
+	current, err := o.transformOpenAPI(openAPIDoc.AsDocument())
+	if err != nil {
+		return Output{Err: err}
+	}
+	diff, err := schema.DiffOpenAPI(baseline, current, o.DataValuesFlags.openAPISchemaName())
+	if err != nil {
+		return Output{Err: fmt.Errorf("Comparing with '%s': %s", o.DataValuesFlags.InspectSchemaDiffFile, err)}
+	}
+	if diff.HasBreakingChanges() {
+		return Output{Err: fmt.Errorf("Schema of data values has breaking changes (compared with '%s'):\n\n%s", o.DataValuesFlags.InspectSchemaDiffFile, diff.Report())}
+	}
+	return Output{
+		Files: []files.OutputFile{files.NewOutputFile("data-values-schema-diff.txt", []byte(diff.Report()), files.TypeText)},
+	}
+}
+
+// transformOpenAPI applies the configured OpenAPITransform (if any) to "doc".
+func (o *Options) transformOpenAPI(doc *yamlmeta.Document) (*yamlmeta.Document, error) {
+	if o.OpenAPITransform == nil {
+		return doc, nil
+	}
+	transformed, err := o.OpenAPITransform(doc)
+	if err != nil {
+		return nil, fmt.Errorf("Transforming OpenAPI document: %s", err)
+	}
+	return transformed, nil
+}
+
+// leadingComments collects the comments preceding each node in the YAML files among "inputFiles".
+func (o *Options) leadingComments(inputFiles []*files.File) (schema.LeadingComments, error) {
+	comments := schema.LeadingComments{}
+	for _, file := range inputFiles {
+		if file.Type() != files.TypeYAML {
+			continue
+		}
+		fileBs, err := file.Bytes()
+		if err != nil {
+			return nil, err
+		}
+		docSet, err := yamlmeta.NewDocumentSetFromBytes(fileBs, yamlmeta.DocSetOpts{AssociatedName: file.RelativePath()})
+		if err != nil {
+			return nil, fmt.Errorf("Unmarshaling YAML template '%s': %s", file.RelativePath(), err)
+		}
+		comments.Merge(schema.NewLeadingComments(docSet))
+	}
+	return comments, nil
 }
 
 func (o *Options) pickSource(srcs []FileSource, pickFunc func(FileSource) bool) FileSource {
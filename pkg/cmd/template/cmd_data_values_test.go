@@ -4,7 +4,10 @@
 package template_test
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -12,6 +15,7 @@ import (
 	cmdtpl "github.com/vmware-tanzu/carvel-ytt/pkg/cmd/template"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/cmd/ui"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/files"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/validations"
 )
 
 func TestEmptyDataValues(t *testing.T) {
@@ -799,6 +803,293 @@ func TestDataValues_with_invalid_flags_fail(t *testing.T) {
 	})
 }
 
+func TestDataValues_validation_messages_are_localized_via_catalog(t *testing.T) {
+	dataValuesYAML := `#@ load("@ytt:assert", "assert")
+#@data/values
+---
+#@assert/validate min=1
+port: 0
+#@assert/validate min_len=1
+host: ""
+`
+	catalogYAML := `a value >= 1: un valor >= 1
+`
+	expectedErr := `  port
+    from: values.yml:5
+    - must be: un valor >= 1 (by: values.yml:4)
+      found: value < 1
+
+  host
+    from: values.yml:7
+    - must be: length >= 1 (by: values.yml:6)
+      found: length = 0
+`
+
+	opts := cmdtpl.NewOptions()
+	opts.DataValuesFlags = cmdtpl.DataValuesFlags{
+		ValidationMessagesFile: "messages.yml",
+		ReadFilesFunc: func(path string) ([]*files.File, error) {
+			switch path {
+			case "messages.yml":
+				return []*files.File{files.MustNewFileFromSource(files.NewBytesSource("messages.yml", []byte(catalogYAML)))}, nil
+			default:
+				return nil, fmt.Errorf("Unknown file '%s'", path)
+			}
+		},
+	}
+
+	filesToProcess := files.NewSortedFiles([]*files.File{
+		files.MustNewFileFromSource(files.NewBytesSource("values.yml", []byte(dataValuesYAML))),
+	})
+
+	assertFails(t, filesToProcess, expectedErr, opts)
+}
+
+func TestDataValues_are_validated_against_a_json_schema(t *testing.T) {
+	dataValuesYAML := `#@data/values
+---
+name: ""
+ports:
+- 80
+- 70000
+`
+	jsonSchema := `{
+  "type": "object",
+  "required": ["name", "tier"],
+  "properties": {
+    "name": {"type": "string", "minLength": 1},
+    "ports": {"type": "array", "items": {"$ref": "#/definitions/port"}}
+  },
+  "definitions": {
+    "port": {"type": "integer", "maximum": 65535}
+  }
+}
+`
+	expectedErr := `Validating final data values:
+  (document)
+    from: values.yml:2
+    - must be: a map with the key named "tier" (by: schema.json:3)
+      found: no key named "tier"
+
+  name
+    from: values.yml:3
+    - must be: length >= 1 (by: schema.json:5)
+      found: length 0
+
+  ports[1]
+    from: values.yml:6
+    - must be: a value <= 65535 (by: schema.json:9)
+      found: 70000
+`
+
+	opts := cmdtpl.NewOptions()
+	opts.DataValuesFlags = cmdtpl.DataValuesFlags{
+		JSONSchemaFile: "schema.json",
+		ReadFilesFunc: func(path string) ([]*files.File, error) {
+			switch path {
+			case "schema.json":
+				return []*files.File{files.MustNewFileFromSource(files.NewBytesSource("schema.json", []byte(jsonSchema)))}, nil
+			default:
+				return nil, fmt.Errorf("Unknown file '%s'", path)
+			}
+		},
+	}
+
+	filesToProcess := files.NewSortedFiles([]*files.File{
+		files.MustNewFileFromSource(files.NewBytesSource("values.yml", []byte(dataValuesYAML))),
+	})
+
+	assertFails(t, filesToProcess, expectedErr, opts)
+
+	out := opts.RunWithFiles(cmdtpl.Input{Files: filesToProcess}, ui.NewTTY(false))
+	assert.True(t, errors.As(out.Err, &validations.CheckError{}), "expected non-conformance to be a validation failure")
+}
+
+func TestDataValues_validation_failures_are_distinguishable_from_other_errors(t *testing.T) {
+	run := func(dataValuesYAML string) error {
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("values.yml", []byte(dataValuesYAML))),
+		})
+		out := cmdtpl.NewOptions().RunWithFiles(cmdtpl.Input{Files: filesToProcess}, ui.NewTTY(false))
+		require.Error(t, out.Err)
+		return out.Err
+	}
+
+	t.Run("when data values fail their validations", func(t *testing.T) {
+		err := run(`#@data/values
+---
+#@assert/validate min=1
+port: 0
+`)
+		var chkErr validations.CheckError
+		require.True(t, errors.As(err, &chkErr))
+		assert.Len(t, chkErr.Check.Invalidations, 1)
+	})
+	t.Run("when data values fail their formats, given --validate-formats", func(t *testing.T) {
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(`#@data/values-schema
+---
+#@schema/format "date"
+released: "2020-01-01"
+`))),
+			files.MustNewFileFromSource(files.NewBytesSource("values.yml", []byte(`#@data/values
+---
+released: "2022-02-30"
+`))),
+		})
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.ValidateFormats = true
+		out := opts.RunWithFiles(cmdtpl.Input{Files: filesToProcess}, ui.NewTTY(false))
+		require.Error(t, out.Err)
+
+		var chkErr validations.CheckError
+		require.True(t, errors.As(out.Err, &chkErr))
+		assert.Len(t, chkErr.Check.Invalidations, 1)
+	})
+	t.Run("when data values of a private library fail their validations", func(t *testing.T) {
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("config.yml", []byte(`#@ load("@ytt:library", "library")
+#@ load("@ytt:template", "template")
+--- #@ template.replace(library.get("lib").eval())
+`))),
+			files.MustNewFileFromSource(files.NewBytesSource("_ytt_lib/lib/values.yml", []byte(`#@data/values
+---
+#@assert/validate min=1
+replicas: 0
+`))),
+			files.MustNewFileFromSource(files.NewBytesSource("_ytt_lib/lib/config.yml", []byte(`#@ load("@ytt:data", "data")
+replicas: #@ data.values.replicas
+`))),
+		})
+		out := cmdtpl.NewOptions().RunWithFiles(cmdtpl.Input{Files: filesToProcess}, ui.NewTTY(false))
+		require.Error(t, out.Err)
+
+		var chkErr validations.CheckError
+		require.True(t, errors.As(out.Err, &chkErr))
+		assert.Len(t, chkErr.Check.Invalidations, 1)
+	})
+	t.Run("but not when templates fail", func(t *testing.T) {
+		err := run(`#@data/values
+---
+port: #@ 1/0
+`)
+		assert.False(t, errors.As(err, &validations.CheckError{}))
+	})
+}
+
+func TestDataValues_validations_are_reported_as_JUnit_XML(t *testing.T) {
+	dataValuesYAML := `#@data/values
+---
+#@assert/validate min=1
+replicas: 0
+#@assert/validate min_len=1
+host: localhost
+#@assert/validate min_len=1
+optional: null
+`
+	expectedReport := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="3" failures="1" skipped="1">
+  <testsuite name="data values validations" tests="3" failures="1" skipped="1">
+    <testcase name="replicas (by values.yml:3)" classname="data values">
+      <failure message="must be: a value &gt;= 1 (by: values.yml:3)"><![CDATA[  replicas
+    from: values.yml:4
+    - must be: a value >= 1 (by: values.yml:3)
+      found: value < 1
+
+]]></failure>
+    </testcase>
+    <testcase name="host (by values.yml:5)" classname="data values"></testcase>
+    <testcase name="optional (by values.yml:7)" classname="data values">
+      <skipped></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+	reportPath := filepath.Join(t.TempDir(), "report.xml")
+
+	opts := cmdtpl.NewOptions()
+	opts.DataValuesFlags.ValidationReportFile = reportPath
+	filesToProcess := files.NewSortedFiles([]*files.File{
+		files.MustNewFileFromSource(files.NewBytesSource("values.yml", []byte(dataValuesYAML))),
+	})
+
+	out := opts.RunWithFiles(cmdtpl.Input{Files: filesToProcess}, ui.NewTTY(false))
+	require.Error(t, out.Err)
+
+	report, err := os.ReadFile(reportPath)
+	require.NoError(t, err)
+	assert.Equal(t, expectedReport, string(report))
+}
+
+func TestDataValues_are_only_validated_when_validation_only(t *testing.T) {
+	dataValuesYAML := `#@data/values
+---
+#@assert/validate min=1
+replicas: 0
+`
+	templateYAML := `#@ load("@ytt:data", "data")
+replicas: #@ data.values.replicas
+#! were templates evaluated, this would fail.
+failure: #@ 1/0
+`
+	newFiles := func(replicas string) []*files.File {
+		return files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("values.yml", []byte(dataValuesYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("overrides.yml", []byte("#@data/values\n---\nreplicas: "+replicas+"\n"))),
+			files.MustNewFileFromSource(files.NewBytesSource("template.yml", []byte(templateYAML))),
+		})
+	}
+
+	t.Run("producing no output, when data values are valid", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.ValidationOnly = true
+
+		out := opts.RunWithFiles(cmdtpl.Input{Files: newFiles("3")}, ui.NewTTY(false))
+		require.NoError(t, out.Err)
+		assert.Nil(t, out.DocSet)
+		assert.Empty(t, out.Files)
+	})
+	t.Run("failing, when data values are invalid", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.ValidationOnly = true
+
+		out := opts.RunWithFiles(cmdtpl.Input{Files: newFiles("0")}, ui.NewTTY(false))
+		require.Error(t, out.Err)
+		assert.True(t, errors.As(out.Err, &validations.CheckError{}))
+	})
+	t.Run("failing, when data values addressed to a private library are invalid", func(t *testing.T) {
+		libFiles := func(libValuesYAML string) []*files.File {
+			return files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("template.yml", []byte(templateYAML))),
+				files.MustNewFileFromSource(files.NewBytesSource("lib-values.yml", []byte(libValuesYAML))),
+				files.MustNewFileFromSource(files.NewBytesSource("_ytt_lib/lib/values.yml", []byte(dataValuesYAML))),
+			})
+		}
+
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.ValidationOnly = true
+		out := opts.RunWithFiles(cmdtpl.Input{Files: libFiles("#@library/ref \"@lib\"\n#@data/values\n---\nreplicas: 1\n")}, ui.NewTTY(false))
+		require.NoError(t, out.Err)
+
+		opts = cmdtpl.NewOptions()
+		opts.DataValuesFlags.ValidationOnly = true
+		out = opts.RunWithFiles(cmdtpl.Input{Files: libFiles("#@library/ref \"@lib\"\n#@data/values\n---\nreplicas: 0\n")}, ui.NewTTY(false))
+		require.Error(t, out.Err)
+
+		var chkErr validations.CheckError
+		require.True(t, errors.As(out.Err, &chkErr))
+		assert.Len(t, chkErr.Check.Invalidations, 1)
+	})
+	t.Run("but not when validations are disabled", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.ValidationOnly = true
+		opts.DataValuesFlags.SkipValidation = true
+
+		out := opts.RunWithFiles(cmdtpl.Input{Files: newFiles("3")}, ui.NewTTY(false))
+		require.EqualError(t, out.Err, "Cannot both validate only (--validation-only) and skip validation (--dangerous-data-values-disable-validation)")
+	})
+}
+
 func TestDataValues_validations_are_skipped_when_disabled(t *testing.T) {
 	t.Run("via the --dangerous-data-values-disable-validation flag", func(t *testing.T) {
 		t.Run("in the root library", func(t *testing.T) {
@@ -11,7 +11,9 @@ import (
 	"github.com/k14s/starlark-go/starlark"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/filepos"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/files"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/schema"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/template"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/validations"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/workspace/datavalues"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/workspace/ref"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/yamlmeta"
@@ -34,9 +36,37 @@ type DataValuesFlags struct {
 
 	FromFiles []string
 
-	Inspect        bool
-	InspectSchema  bool
-	SkipValidation bool
+	Inspect                    bool
+	InspectSchema              bool
+	InspectSchemaWithValues    bool
+	InspectSchemaInfer         bool
+	InspectSchemaCheckExamples bool
+	InspectSchemaInferMaxLen   bool
+	InspectSchemaValidations   bool
+	InspectSchemaAddlProps     bool
+	InspectSchemaDescComments  bool
+	InspectSchemaSortKeys      bool
+	InspectSchemaNoDeprecated  bool
+	InspectSchemaDefaultsAsEx  bool
+	InspectSchemaSplit         bool
+	InspectSchemaNoExtensions  bool
+	InspectSchemaPointer       string
+	InspectSchemaKeyTitles     bool
+	InspectSchemaParameters    bool
+	InspectSchemaDiffFile      string
+	InspectSchemaLibrary       string
+	InspectSchemaXOrder        bool
+	InspectSchemaName          string
+	InspectSchemaEmptyPaths    bool // unlike most, defaults to true (as does --openapi-emit-empty-paths): see NewOptions()
+	SkipValidation             bool
+	ValidationMessagesFile     string
+	ExperimentalValidations    bool
+	ValidationInclude          []string
+	ValidationExclude          []string
+	ValidateFormats            bool
+	ValidationReportFile       string
+	ValidationOnly             bool
+	JSONSchemaFile             string
 
 	EnvironFunc   func() []string
 	ReadFilesFunc func(paths string) ([]*files.File, error)
@@ -57,7 +87,83 @@ func (s *DataValuesFlags) Set(cmdFlags CmdFlags) {
 
 	cmdFlags.BoolVar(&s.Inspect, "data-values-inspect", false, "Determine the final data values (applying any overlays) and display that result")
 	cmdFlags.BoolVar(&s.SkipValidation, "dangerous-data-values-disable-validation", false, "Skip validating data values (not recommended: may result in templates failing or invalid output)")
-	cmdFlags.BoolVar(&s.InspectSchema, "data-values-schema-inspect", false, "Determine the complete schema for data values (applying any overlays) and display the result (only OpenAPI v3.0 is supported, see --output)")
+	cmdFlags.BoolVar(&s.ValidateFormats, "validate-formats", false, "Check that data values whose schema declares a format (via @schema/format) are encoded in that format (e.g. base64 for 'byte')")
+	cmdFlags.StringVar(&s.ValidationReportFile, "validation-report-file", "", "Write the outcome of each data values validation (as a JUnit XML report) to the given file")
+	cmdFlags.BoolVar(&s.ValidationOnly, "validation-only", false, "Only validate data values (i.e. check their @schema/validation and @assert/validate rules), without rendering templates: produces no output, failing if any data value is invalid (private libraries are validated only when data values are addressed to them via @library/ref)")
+	cmdFlags.StringVar(&s.JSONSchemaFile, "data-values-json-schema", "", "Also validate data values against the JSON Schema in the given file (JSON or YAML) (e.g. one published for a chart or a CRD)")
+	cmdFlags.BoolVar(&s.ExperimentalValidations, "enable-experimental-validations", false, "Also run data values validations marked experimental (i.e. given experimental=True), which are otherwise skipped")
+	cmdFlags.StringArrayVar(&s.ValidationInclude, "validation-include", nil, "Only run the validations of data values whose path matches the given glob ('*' matches within a key, '**' across keys) (e.g. 'db.**') (can be specified multiple times)")
+	cmdFlags.StringArrayVar(&s.ValidationExclude, "validation-exclude", nil, "Skip the validations of data values whose path matches the given glob ('*' matches within a key, '**' across keys) (e.g. 'legacy.**') (can be specified multiple times)")
+	cmdFlags.StringVar(&s.ValidationMessagesFile, "data-values-validation-messages", "", "Report data values validation failures using the messages in the given YAML file (format: map of rule message to localized message; rules not in the map report their own message)")
+	cmdFlags.BoolVar(&s.InspectSchema, "data-values-schema-inspect", false, "Determine the complete schema for data values (applying any overlays) and display the result (OpenAPI v3.0, v3.1, Markdown and TypeScript are supported, see --output)")
+	cmdFlags.BoolVar(&s.InspectSchemaWithValues, "data-values-schema-inspect-with-values", false, "When inspecting schema, report as defaults the data values resulting from applying any given data values (e.g. --data-values-file)")
+	cmdFlags.BoolVar(&s.InspectSchemaInfer, "data-values-schema-inspect-infer", false, "When inspecting schema, infer it from the data values (e.g. plain YAML given via --data-values-file) rather than from data values schema")
+	cmdFlags.BoolVar(&s.InspectSchemaCheckExamples, "data-values-schema-inspect-validate-examples", false, "When inspecting schema, verify that examples (given via @schema/examples) include all values required to be not null")
+	cmdFlags.BoolVar(&s.InspectSchemaValidations, "data-values-schema-inspect-validations", false, "When inspecting schema, describe validation rules that have no equivalent OpenAPI keyword (e.g. sorted=) in the 'x-ytt-validations' extension")
+	cmdFlags.BoolVar(&s.InspectSchemaAddlProps, "openapi-additional-properties", false, "When inspecting schema, report every object as allowing additional properties (i.e. 'additionalProperties: true')")
+	cmdFlags.BoolVar(&s.InspectSchemaDescComments, "openapi-desc-from-comments", false, "When inspecting schema, describe values that have no @schema/desc with the comments (e.g. '#! ...') on the lines preceding them")
+	cmdFlags.BoolVar(&s.InspectSchemaSortKeys, "openapi-sort-keys", false, "When inspecting schema, list the properties of each object alphabetically (rather than in the order they appear in schema)")
+	cmdFlags.BoolVar(&s.InspectSchemaNoDeprecated, "openapi-exclude-deprecated", false, "When inspecting schema, omit values marked deprecated (via @schema/deprecated) rather than reporting them with 'deprecated: true'")
+	cmdFlags.BoolVar(&s.InspectSchemaDefaultsAsEx, "openapi-defaults-as-examples", false, "When inspecting schema, report the default of each value as its 'example' (unless given one via @schema/examples), omitting 'default'")
+	cmdFlags.BoolVar(&s.InspectSchemaSplit, "openapi-split-components", false, "When inspecting schema, describe each top-level data value in a file of its own (in 'schemas/'), referenced from 'openapi.yaml' (see --output-files)")
+	cmdFlags.BoolVar(&s.InspectSchemaNoExtensions, "openapi-strip-x-extensions", false, "When inspecting schema, omit all OpenAPI extensions (i.e. 'x-' keywords, such as 'x-example-description'), for consumers that reject them")
+	cmdFlags.StringVar(&s.InspectSchemaPointer, "data-values-schema-inspect-pointer", "", "When inspecting schema, output only the part of the schema at the given JSON Pointer, relative to the schema of data values (e.g. /properties/db/properties/port) (combine with --output=json for JSON)")
+	cmdFlags.BoolVar(&s.InspectSchemaInferMaxLen, "openapi-infer-string-maxlen", false, "When inspecting schema, for strings without a length constraint (via @schema/validation min_len= or max_len=), report the length of the (non-empty) default as maxLength")
+	cmdFlags.StringVar(&s.InspectSchemaDiffFile, "openapi-diff", "", "When inspecting schema, compare it with the (OpenAPI) schema in the given file (e.g. as inspected from a previous release) and report the changes, failing if any is breaking (e.g. a removed value, or a tightened type or bound)")
+	cmdFlags.StringVar(&s.InspectSchemaLibrary, "data-values-schema-inspect-library", "", "When inspecting schema, report that of the given private library (e.g. '@lib' or '@lib~alias'), as resolved when evaluated from the root library (i.e. including schema and data values addressed to it via #@library/ref)")
+	cmdFlags.BoolVar(&s.InspectSchemaEmptyPaths, "openapi-emit-empty-paths", true, "When inspecting schema, include 'paths: {}' in the OpenAPI document (set to false to omit it, for tools that reject empty paths)")
+	cmdFlags.BoolVar(&s.InspectSchemaParameters, "openapi-parameters", false, "When inspecting schema, describe each top-level data value that is a scalar as a query parameter (in 'components/parameters') rather than as a property of the schema of data values")
+	cmdFlags.BoolVar(&s.InspectSchemaXOrder, "openapi-x-order", false, "When inspecting schema, give each property its position (from 0) in schema, in the 'x-order' extension, so consumers that list properties alphabetically can restore schema order")
+	cmdFlags.StringVar(&s.InspectSchemaName, "openapi-schema-name", schema.DefaultOpenAPISchemaName, "When inspecting schema, the name under which to give the schema of data values in 'components/schemas' (e.g. to avoid collisions when merging several into one document)")
+	cmdFlags.BoolVar(&s.InspectSchemaKeyTitles, "openapi-title-from-key", false, "When inspecting schema, title each value that has no @schema/title after its key, made readable (e.g. 'db_conn' is titled 'Db Conn')")
+}
+
+// openAPISchemaName is the name under which the schema of data values is given in 'components/schemas'.
+func (s *DataValuesFlags) openAPISchemaName() string {
+	if s.InspectSchemaName == "" {
+		return schema.DefaultOpenAPISchemaName
+	}
+	return s.InspectSchemaName
+}
+
+// ValidationMessages loads the message catalog named by --data-values-validation-messages, if any.
+func (s *DataValuesFlags) ValidationMessages(strict bool) (validations.MessageCatalog, error) {
+	if s.ValidationMessagesFile == "" {
+		return nil, nil
+	}
+
+	catalogFiles, err := s.asFiles(s.ValidationMessagesFile)
+	if err != nil {
+		return nil, fmt.Errorf("Find files '%s': %s", s.ValidationMessagesFile, err)
+	}
+
+	catalog := validations.MessageCatalog{}
+	for _, catalogFile := range catalogFiles {
+		contents, err := catalogFile.Bytes()
+		if err != nil {
+			return nil, fmt.Errorf("Reading file '%s': %s", catalogFile.RelativePath(), err)
+		}
+		val, err := s.parseYAML(string(contents), strict)
+		if err != nil {
+			return nil, fmt.Errorf("Unmarshaling validation messages file '%s': %s", catalogFile.RelativePath(), err)
+		}
+		if val == nil {
+			continue
+		}
+		messages, ok := val.(*yamlmeta.Map)
+		if !ok {
+			return nil, fmt.Errorf("Expected validation messages file '%s' to contain a map, but was %s", catalogFile.RelativePath(), yamlmeta.TypeName(val))
+		}
+		for _, item := range messages.Items {
+			msg, isKeyString := item.Key.(string)
+			localized, isValString := item.Value.(string)
+			if !isKeyString || !isValString {
+				return nil, fmt.Errorf("Expected validation messages file '%s' to map strings to strings, but found '%v' (at %s)", catalogFile.RelativePath(), item.Key, item.Position.AsCompactString())
+			}
+			catalog[msg] = localized
+		}
+	}
+	return catalog, nil
 }
 
 type dataValuesFlagsSource struct {
@@ -259,6 +365,60 @@ func (s *DataValuesFlags) kv(kv string, src dataValuesFlagsSource) (*datavalues.
 	return datavalues.NewEnvelopeWithLibRef(overlay, libRef)
 }
 
+// JSONSchema loads the JSON Schema named by --data-values-json-schema, if any.
+func (s *DataValuesFlags) JSONSchema(strict bool) (*schema.JSONSchema, error) {
+	if s.JSONSchemaFile == "" {
+		return nil, nil
+	}
+
+	schemaFiles, err := s.asFiles(s.JSONSchemaFile)
+	if err != nil {
+		return nil, fmt.Errorf("Find files '%s': %s", s.JSONSchemaFile, err)
+	}
+	if len(schemaFiles) != 1 {
+		return nil, fmt.Errorf("Expected '%s' to be a file, but is a directory", s.JSONSchemaFile)
+	}
+
+	contents, err := schemaFiles[0].Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("Reading file '%s': %s", schemaFiles[0].RelativePath(), err)
+	}
+	docSet, err := yamlmeta.NewParser(yamlmeta.ParserOpts{Strict: strict}).ParseBytes(contents, schemaFiles[0].RelativePath())
+	if err != nil {
+		return nil, fmt.Errorf("Unmarshaling JSON Schema file '%s': %s", schemaFiles[0].RelativePath(), err)
+	}
+	jsonSchema, err := schema.NewJSONSchema(docSet.Items[0].Value)
+	if err != nil {
+		return nil, fmt.Errorf("Loading JSON Schema file '%s': %s", schemaFiles[0].RelativePath(), err)
+	}
+	return jsonSchema, nil
+}
+
+// OpenAPIBaseline loads the OpenAPI document named by --openapi-diff, if any.
+func (s *DataValuesFlags) OpenAPIBaseline(strict bool) (*yamlmeta.Document, error) {
+	if s.InspectSchemaDiffFile == "" {
+		return nil, nil
+	}
+
+	baselineFiles, err := s.asFiles(s.InspectSchemaDiffFile)
+	if err != nil {
+		return nil, fmt.Errorf("Find files '%s': %s", s.InspectSchemaDiffFile, err)
+	}
+	if len(baselineFiles) != 1 {
+		return nil, fmt.Errorf("Expected '%s' to be a file, but is a directory", s.InspectSchemaDiffFile)
+	}
+
+	contents, err := baselineFiles[0].Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("Reading file '%s': %s", baselineFiles[0].RelativePath(), err)
+	}
+	docSet, err := yamlmeta.NewParser(yamlmeta.ParserOpts{Strict: strict}).ParseBytes(contents, baselineFiles[0].RelativePath())
+	if err != nil {
+		return nil, fmt.Errorf("Unmarshaling OpenAPI file '%s': %s", baselineFiles[0].RelativePath(), err)
+	}
+	return docSet.Items[0], nil
+}
+
 func (s *DataValuesFlags) parseYAML(data string, strict bool) (interface{}, error) {
 	docSet, err := yamlmeta.NewParser(yamlmeta.ParserOpts{Strict: strict}).ParseBytes([]byte(data), "")
 	if err != nil {
@@ -84,6 +84,12 @@ func (s *RegularFilesSource) Output(out Output) error {
 		return files.NewOutputDirectory(s.opts.outputDir, out.Files, s.ui).Write()
 	case len(s.opts.OutputFiles) > 0:
 		return files.NewOutputDirectory(s.opts.OutputFiles, out.Files, s.ui).WriteFiles()
+	case out.DocSet == nil:
+		// output is not YAML (e.g. schema inspected as Markdown)
+		for _, file := range out.Files {
+			s.ui.Printf("%s", file.Bytes())
+		}
+		return nil
 	default:
 		for _, file := range out.Files {
 			if file.Type() != files.TypeYAML {
@@ -134,14 +140,17 @@ const (
 
 // When the FileSource are RegularFilesSource, indicates which schema type to use when rendering the output.
 const (
-	RegularFilesOutputTypeOpenAPI = "openapi-v3"
-	RegularFilesOutputTypeNone    = ""
+	RegularFilesOutputTypeOpenAPI   = "openapi-v3"
+	RegularFilesOutputTypeOpenAPI31 = "openapi-v3.1"
+	RegularFilesOutputTypeMarkdown  = "markdown"
+	RegularFilesOutputTypeTS        = "typescript"
+	RegularFilesOutputTypeNone      = ""
 )
 
 // Collections of each category of output type
 var (
 	RegularFilesOutputFormatTypes = []string{RegularFilesOutputTypeYAML, RegularFilesOutputTypeJSON, RegularFilesOutputTypePos}
-	RegularFilesOutputSchemaTypes = []string{RegularFilesOutputTypeOpenAPI}
+	RegularFilesOutputSchemaTypes = []string{RegularFilesOutputTypeOpenAPI, RegularFilesOutputTypeOpenAPI31, RegularFilesOutputTypeMarkdown, RegularFilesOutputTypeTS}
 	RegularFilesOutputTypes       = append(RegularFilesOutputFormatTypes, RegularFilesOutputSchemaTypes...)
 )
 
@@ -260,6 +260,7 @@ schema.yml:
     = found: missing keyword argument and value (by schema.yml:3)
     = expected: valid keyword argument and value
     = hint: Supported key-value pairs are 'any=True', 'any=False'
+    = hint: Supported type names are 'string'
 `
 
 			filesToProcess := files.NewSortedFiles([]*files.File{
@@ -287,6 +288,7 @@ schema.yml:
     = found: missing keyword argument and value (by schema.yml:3)
     = expected: valid keyword argument and value
     = hint: Supported key-value pairs are 'any=True', 'any=False'
+    = hint: Supported type names are 'string'
 `
 
 			filesToProcess = files.NewSortedFiles([]*files.File{
@@ -295,6 +297,63 @@ schema.yml:
 
 			assertFails(t, filesToProcess, expectedErr2, opts)
 		})
+		t.Run("is given an unknown type name", func(t *testing.T) {
+			schemaYAML := `#@data/values-schema
+---
+#@schema/type "int"
+foo: 0
+`
+
+			expectedErr := `
+Invalid schema
+==============
+
+unknown type given to @schema/type annotation
+schema.yml:
+    |
+  3 | #@schema/type "int"
+  4 | foo: 0
+    |
+
+    = found: "int" (by schema.yml:3)
+    = expected: the name of a type, alone
+    = hint: Supported type names are 'string'
+`
+
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertFails(t, filesToProcess, expectedErr, opts)
+		})
+		t.Run("names string, but the value is not one and no default is given", func(t *testing.T) {
+			schemaYAML := `#@data/values-schema
+---
+#@schema/type "string"
+version: 1.0
+`
+
+			expectedErr := `
+Invalid schema - value is not of the type given via @schema/type
+================================================================
+
+schema.yml:
+    |
+  4 | version: 1.0
+    |
+
+    = found: float
+    = expected: string (by schema.yml:4)
+    = hint: quote the value to make it a string (e.g. "1.0").
+    = hint: alternatively, give the default value via @schema/default.
+`
+
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertFails(t, filesToProcess, expectedErr, opts)
+		})
 		t.Run("has nested schema/... annotations", func(t *testing.T) {
 			schemaYAML := `#@data/values-schema
 ---
@@ -476,6 +535,34 @@ schema.yml:
 
 				assertFails(t, filesToProcess, expectedErr, opts)
 			})
+			t.Run("as null, on a value that is neither nullable nor of any type", func(t *testing.T) {
+				schemaYAML := `#@data/values-schema
+---
+#@schema/default None
+foo: a string
+`
+
+				expectedErr := `
+Invalid schema - @schema/default is wrong type
+==============================================
+
+schema.yml:
+    |
+  3 | #@schema/default None
+  4 | foo: a string
+    |
+
+    = found: null (at schema.yml:3)
+    = expected: string (by schema.yml:4)
+    = hint: to default to null, also annotate the value with @schema/nullable (or, to allow any type, @schema/type any=True)
+`
+
+				filesToProcess := files.NewSortedFiles([]*files.File{
+					files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+				})
+
+				assertFails(t, filesToProcess, expectedErr, opts)
+			})
 			t.Run("as an array (a node)", func(t *testing.T) {
 
 				schemaYAML := `#@data/values-schema
@@ -838,30 +925,98 @@ schema.yml:
 
 			assertFails(t, filesToProcess, expectedErr, opts)
 		})
-		t.Run("is on an a document", func(t *testing.T) {
+	})
+	t.Run("when schema/required-if annotation", func(t *testing.T) {
+		t.Run("is missing required keys", func(t *testing.T) {
 			schemaYAML := `#@data/values-schema
-#@schema/deprecated ""
 ---
-foo:
-- bar
+#@schema/required-if "enabled"
+tls:
+  enabled: false
 `
+			expectedErr := `Invalid schema
+==============
 
-			expectedErr := `
-Invalid schema
+syntax error in @schema/required-if annotation
+schema.yml:
+    |
+  3 | #@schema/required-if "enabled"
+  4 | tls:
+    |
+
+    = found: no required keys in @schema/required-if (by schema.yml:3)
+    = expected: at least one of 'then' or 'otherwise' keyword arguments
+`
+
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertFails(t, filesToProcess, expectedErr, opts)
+		})
+		t.Run("refers to an unknown key", func(t *testing.T) {
+			schemaYAML := `#@data/values-schema
+---
+#@schema/required-if "enabled", then=["certificate"]
+tls:
+  enabled: false
+  cert: ""
+`
+			expectedErr := `Invalid schema - @schema/required-if refers to an unknown key
+=============================================================
+
+schema.yml:
+    |
+  3 | #@schema/required-if "enabled", then=["certificate"]
+  4 | tls:
+    |
+
+    = found: certificate (by schema.yml:3)
+    = expected: a key within the annotated map
+`
+
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertFails(t, filesToProcess, expectedErr, opts)
+		})
+		t.Run("is given a non-scalar equals=", func(t *testing.T) {
+			schemaYAML := `#@data/values-schema
+---
+#@schema/required-if "enabled", equals=len, then=["cert"]
+tls:
+  enabled: false
+  cert: ""
+`
+			expectedErr := `Invalid schema
 ==============
 
-@schema/deprecated not supported on a document
+syntax error in @schema/required-if annotation
 schema.yml:
     |
-  2 | #@schema/deprecated ""
-  3 | ---
+  3 | #@schema/required-if "enabled", equals=len, then=["cert"]
+  4 | tls:
     |
 
+    = found: builtin_function_or_method value (by schema.yml:3)
+    = expected: equals= to be a string, number, boolean, or None
+`
 
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
 
-    = hint: do you mean to deprecate the entire schema document?
-    = hint: use schema/deprecated on individual keys.
+			assertFails(t, filesToProcess, expectedErr, opts)
+		})
+		t.Run("is not on a map", func(t *testing.T) {
+			schemaYAML := `#@data/values-schema
+---
+#@schema/required-if "enabled", then=["cert"]
+tls: ""
 `
+			expectedErr := `Invalid schema - @schema/required-if not supported on string`
+
 			filesToProcess := files.NewSortedFiles([]*files.File{
 				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
 			})
@@ -869,6 +1024,248 @@ schema.yml:
 			assertFails(t, filesToProcess, expectedErr, opts)
 		})
 	})
+
+	t.Run("when schema/min-properties or schema/max-properties annotation", func(t *testing.T) {
+		t.Run("is not given a non-negative integer", func(t *testing.T) {
+			schemaYAML := `#@data/values-schema
+---
+#@schema/min-properties -1
+labels:
+  app: ""
+`
+			expectedErr := `Invalid schema
+==============
+
+syntax error in @schema/min-properties annotation
+schema.yml:
+    |
+  3 | #@schema/min-properties -1
+  4 | labels:
+    |
+
+    = found: -1 in @schema/min-properties (by schema.yml:3)
+    = expected: a non-negative integer
+`
+
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertFails(t, filesToProcess, expectedErr, opts)
+		})
+		t.Run("is not on a map", func(t *testing.T) {
+			schemaYAML := `#@data/values-schema
+---
+#@schema/max-properties 3
+labels:
+- ""
+`
+			expectedErr := `Invalid schema - @schema/max-properties not supported on array`
+
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertFails(t, filesToProcess, expectedErr, opts)
+		})
+	})
+
+	t.Run("when schema/format annotation", func(t *testing.T) {
+		t.Run("is given an unknown format", func(t *testing.T) {
+			schemaYAML := `#@data/values-schema
+---
+#@schema/format "hex"
+cert: ""
+`
+			expectedErr := `Invalid schema
+==============
+
+unknown format in @schema/format annotation
+schema.yml:
+    |
+  3 | #@schema/format "hex"
+  4 | cert: ""
+    |
+
+    = found: "hex" in @schema/format (by schema.yml:3)
+    = expected: one of: "byte", "binary", or "date"
+`
+
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertFails(t, filesToProcess, expectedErr, opts)
+		})
+		t.Run("is not on a string", func(t *testing.T) {
+			schemaYAML := `#@data/values-schema
+---
+#@schema/format "byte"
+port: 443
+`
+			expectedErr := `Invalid schema - @schema/format not supported on int`
+
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertFails(t, filesToProcess, expectedErr, opts)
+		})
+	})
+
+	t.Run("when schema/unit annotation", func(t *testing.T) {
+		t.Run("is not given the name of a unit", func(t *testing.T) {
+			schemaYAML := `#@data/values-schema
+---
+#@schema/unit 30
+timeout: 30
+`
+			expectedErr := `Invalid schema
+==============
+
+syntax error in @schema/unit annotation
+schema.yml:
+    |
+  3 | #@schema/unit 30
+  4 | timeout: 30
+    |
+
+    = found: 30 in @schema/unit (by schema.yml:3)
+    = expected: the name of a unit (e.g. "seconds")
+`
+
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertFails(t, filesToProcess, expectedErr, opts)
+		})
+		t.Run("is not on a scalar", func(t *testing.T) {
+			schemaYAML := `#@data/values-schema
+---
+#@schema/unit "seconds"
+timeouts:
+- 30
+`
+			expectedErr := `Invalid schema - @schema/unit not supported on array`
+
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertFails(t, filesToProcess, expectedErr, opts)
+		})
+	})
+
+	t.Run("when schema/one-of, schema/any-of, or schema/all-of annotation", func(t *testing.T) {
+		t.Run("does not name its alternatives", func(t *testing.T) {
+			schemaYAML := `#@data/values-schema
+---
+#@schema/one-of {"radius": 0}, {"side": 0}
+shape:
+  radius: 1
+`
+			expectedErr := `Invalid schema
+==============
+
+syntax error in @schema/one-of annotation
+schema.yml:
+    |
+  3 | #@schema/one-of {"radius": 0}, {"side": 0}
+  4 | shape:
+    |
+
+    = found: 2 positional and 0 keyword argument(s) in @schema/one-of (by schema.yml:3)
+    = expected: one or more named alternatives
+    = hint: name each alternative, e.g. @schema/one-of circle=circle(), square=square()
+`
+
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertFails(t, filesToProcess, expectedErr, opts)
+		})
+		t.Run("is combined with another type annotation", func(t *testing.T) {
+			schemaYAML := `#@data/values-schema
+---
+#@schema/nullable
+#@schema/any-of name="", id=0
+owner: ""
+`
+			expectedErr := `composition cannot be combined with other type annotations`
+
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertFails(t, filesToProcess, expectedErr, opts)
+		})
+	})
+
+	t.Run("when schema/discriminator annotation", func(t *testing.T) {
+		t.Run("is not on a composition", func(t *testing.T) {
+			schemaYAML := `#@data/values-schema
+---
+#@schema/discriminator "kind"
+shape:
+  kind: circle
+`
+			expectedErr := `Invalid schema - @schema/discriminator not supported on map`
+
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertFails(t, filesToProcess, expectedErr, opts)
+		})
+		t.Run("names a property missing from an alternative", func(t *testing.T) {
+			schemaYAML := `#@data/values-schema
+---
+#@schema/one-of circle={"kind": "circle", "radius": 0}, square={"side": 0}
+#@schema/discriminator "kind"
+shape:
+  kind: circle
+  radius: 1
+`
+			expectedErr := `Invalid schema - @schema/discriminator refers to a property missing from an alternative
+=======================================================================================
+
+schema.yml:
+    |
+  4 | #@schema/discriminator "kind"
+  5 | shape:
+    |
+
+    = found: square (by schema.yml:4)
+    = expected: every alternative to be a map containing "kind"
+`
+
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertFails(t, filesToProcess, expectedErr, opts)
+		})
+		t.Run("maps to an unknown alternative", func(t *testing.T) {
+			schemaYAML := `#@data/values-schema
+---
+#@schema/one-of circle={"kind": "circle", "radius": 0}, square={"kind": "square", "side": 0}
+#@schema/discriminator "kind", mapping={"round": "ellipse"}
+shape:
+  kind: circle
+  radius: 1
+`
+			expectedErr := `@schema/discriminator maps to an unknown alternative`
+
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertFails(t, filesToProcess, expectedErr, opts)
+		})
+	})
+
 	t.Run("when schema/examples annotation value", func(t *testing.T) {
 		t.Run("is empty", func(t *testing.T) {
 			schemaYAML := `#@data/values-schema
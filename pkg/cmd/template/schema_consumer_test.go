@@ -360,6 +360,70 @@ dataValues.yml:
 
     = found: null
     = expected: integer (by schema.yml:3)
+`
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when an array is nullable, but one of its items is null", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/nullable
+ports:
+- 80
+`
+		dataValuesYAML := `#@data/values
+---
+ports:
+- 443
+- null
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("dataValues.yml", []byte(dataValuesYAML))),
+		})
+
+		expectedErr := `
+One or more data values were invalid
+====================================
+
+dataValues.yml:
+    |
+  5 | - null
+    |
+
+    = found: null
+    = expected: integer (by schema.yml:5)
+`
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when an array's items are nullable, but the array itself is null", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+ports:
+#@schema/nullable
+- 80
+`
+		dataValuesYAML := `#@data/values
+---
+ports: null
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("dataValues.yml", []byte(dataValuesYAML))),
+		})
+
+		expectedErr := `
+One or more data values were invalid
+====================================
+
+dataValues.yml:
+    |
+  3 | ports: null
+    |
+
+    = found: null
+    = expected: array (by schema.yml:3)
 `
 		assertFails(t, filesToProcess, expectedErr, opts)
 	})
@@ -1037,6 +1101,43 @@ rendered: #@ data.values
 			assertSucceeds(t, filesToProcess, expected, opts)
 		})
 	})
+	t.Run("when a default is read from the environment", func(t *testing.T) {
+		t.Setenv("YTT_TEST_DB_HOST", "db.example.com")
+		schemaYAML := `#@ load("@ytt:env", "env")
+#@data/values-schema
+---
+#@schema/default env.get("YTT_TEST_DB_HOST")
+db_host: ""
+#@schema/default env.get("YTT_TEST_DB_PORT", default=5432)
+db_port: 0
+`
+		templateYAML := `#@ load("@ytt:data", "data")
+---
+db_host: #@ data.values.db_host
+db_port: #@ data.values.db_port
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("template.yml", []byte(templateYAML))),
+		})
+
+		t.Run("uses the value of variables that are allowed", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.AllowEnvDefaults = []string{"YTT_TEST_DB_HOST", "YTT_TEST_DB_PORT"}
+
+			expected := `db_host: db.example.com
+db_port: 5432
+`
+			assertSucceeds(t, filesToProcess, expected, opts)
+		})
+		t.Run("errors when variable is not allowed", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.AllowEnvDefaults = []string{"YTT_TEST_DB_PORT"}
+
+			expectedErr := `Reading environment variable 'YTT_TEST_DB_HOST' is not allowed (hint: to allow it, also specify --allow-env-defaults=YTT_TEST_DB_HOST)`
+			assertFails(t, filesToProcess, expectedErr, opts)
+		})
+	})
 }
 
 func TestSchema_allows_null_values_via_nullable_annotation(t *testing.T) {
@@ -1255,6 +1356,57 @@ overriden:
 			files.MustNewFileFromSource(files.NewBytesSource("template.yml", []byte(templateYAML))),
 		})
 
+		assertSucceeds(t, filesToProcess, expected, opts)
+	})
+	t.Run("on a null value, allowing only null (when explicitly not of any type)", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/nullable
+#@schema/type any=False
+placeholder: null
+`
+		dataValuesYAML := `#@data/values
+---
+placeholder: set from data value
+`
+		expectedErr := `One or more data values were invalid
+====================================
+
+dataValues.yml:
+    |
+  3 | placeholder: set from data value
+    |
+
+    = found: string
+    = expected: null (by schema.yml:5)
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("dataValues.yml", []byte(dataValuesYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("on an enumerated value, defaulting to null (which need not be among its allowed values)", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/nullable
+#@schema/validation one_of=["debug", "info"]
+log_level: info
+`
+		templateYAML := `#@ load("@ytt:data", "data")
+---
+log_level: #@ data.values.log_level
+`
+		expected := `log_level: null
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("template.yml", []byte(templateYAML))),
+		})
+
 		assertSucceeds(t, filesToProcess, expected, opts)
 	})
 }
@@ -1523,10 +1675,242 @@ baz: #@ data.values.baz
 			files.MustNewFileFromSource(files.NewBytesSource("template.yml", []byte(templateYAML))),
 		})
 
+		assertSucceeds(t, filesToProcess, expected, opts)
+	})
+	t.Run("with a null default (without also being nullable)", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/type any=True
+#@schema/default None
+foo: ""
+#@schema/default None
+#@schema/type any=True
+bar:
+  a: 1
+`
+		templateYAML := `#@ load("@ytt:data", "data")
+---
+foo: #@ data.values.foo
+bar: #@ data.values.bar
+`
+		expected := `foo: null
+bar: null
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("template.yml", []byte(templateYAML))),
+		})
+
 		assertSucceeds(t, filesToProcess, expected, opts)
 	})
 }
 
+func TestSchema_forces_string_type_via_type_annotation(t *testing.T) {
+	opts := cmdtpl.NewOptions()
+	schemaYAML := `#@data/values-schema
+---
+#@schema/type "string"
+#@schema/default "1.0"
+version: 1.0
+#@schema/type "string"
+#@schema/nullable
+channel: ""
+`
+	templateYAML := `#@ load("@ytt:data", "data")
+---
+version: #@ data.values.version
+channel: #@ data.values.channel
+`
+
+	t.Run("defaulting to the given value", func(t *testing.T) {
+		expected := `version: "1.0"
+channel: null
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("template.yml", []byte(templateYAML))),
+		})
+
+		assertSucceeds(t, filesToProcess, expected, opts)
+	})
+	t.Run("accepting strings", func(t *testing.T) {
+		dataValuesYAML := `#@data/values
+---
+version: "1.10"
+channel: stable
+`
+		expected := `version: "1.10"
+channel: stable
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("values.yml", []byte(dataValuesYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("template.yml", []byte(templateYAML))),
+		})
+
+		assertSucceeds(t, filesToProcess, expected, opts)
+	})
+	t.Run("rejecting other types", func(t *testing.T) {
+		dataValuesYAML := `#@data/values
+---
+version: 1.1
+`
+		expectedErr := `One or more data values were invalid
+====================================
+
+values.yml:
+    |
+  3 | version: 1.1
+    |
+
+    = found: float
+    = expected: string (by schema.yml:5)
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("values.yml", []byte(dataValuesYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("template.yml", []byte(templateYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+}
+
+func TestSchema_allows_values_matching_composed_alternatives(t *testing.T) {
+	opts := cmdtpl.NewOptions()
+	schemaYAML := `#@ def circle():
+radius: 0
+#@ end
+
+#@ def square():
+side: 0
+#@ end
+
+#@data/values-schema
+---
+#@schema/one-of circle=circle(), square=square()
+shape:
+  radius: 1
+`
+
+	t.Run("when the value matches exactly one alternative of @schema/one-of", func(t *testing.T) {
+		dataValuesYAML := `#@data/values
+---
+#@overlay/replace
+shape:
+  side: 2
+`
+		templateYAML := `#@ load("@ytt:data", "data")
+---
+shape: #@ data.values.shape
+`
+		expected := `shape:
+  side: 2
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("dataValues.yml", []byte(dataValuesYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("template.yml", []byte(templateYAML))),
+		})
+
+		assertSucceeds(t, filesToProcess, expected, opts)
+	})
+	t.Run("but reports which alternatives did not match when the value matches none", func(t *testing.T) {
+		dataValuesYAML := `#@data/values
+---
+#@overlay/replace
+shape:
+  width: 2
+`
+		expectedErr := `One or more data values were invalid
+====================================
+
+dataValues.yml:
+    |
+  4 | shape:
+    |
+
+    = found: a value matching none of them
+    = expected: a value matching exactly one of: circle, square (by schema.yml:12)
+    = hint: not circle: found width, expected a map item with the key named "radius" (from schema.yml:1)
+    = hint: not square: found width, expected a map item with the key named "side" (from schema.yml:1)
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("dataValues.yml", []byte(dataValuesYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when the value matches at least one alternative of @schema/any-of", func(t *testing.T) {
+		schemaYAML := `#@ def named():
+name: ""
+#@ end
+
+#@data/values-schema
+---
+#@schema/any-of named=named(), labelled={"label": ""}
+target:
+  name: app
+`
+		dataValuesYAML := `#@data/values
+---
+#@overlay/replace
+target:
+  label: web
+`
+		templateYAML := `#@ load("@ytt:data", "data")
+---
+target: #@ data.values.target
+`
+		expected := `target:
+  label: web
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("dataValues.yml", []byte(dataValuesYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("template.yml", []byte(templateYAML))),
+		})
+
+		assertSucceeds(t, filesToProcess, expected, opts)
+	})
+	t.Run("but reports the alternative that did not match when the value must match all of @schema/all-of", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/all-of port=0, positive=0
+port: 8080
+`
+		dataValuesYAML := `#@data/values
+---
+port: "http"
+`
+		expectedErr := `One or more data values were invalid
+====================================
+
+dataValues.yml:
+    |
+  3 | port: "http"
+    |
+
+    = found: a value matching none of them
+    = expected: a value matching all of: port, positive (by schema.yml:4)
+    = hint: not port: found string, expected integer (by schema.yml:3)
+    = hint: not positive: found string, expected integer (by schema.yml:3)
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("dataValues.yml", []byte(dataValuesYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+}
+
 func TestSchema_reports_violations_when_DataValues_fail_validations(t *testing.T) {
 	t.Run("on a document", func(t *testing.T) {
 		schemaYAML := `#@data/values-schema
@@ -1584,6 +1968,45 @@ foo:
 `
 		assertFailsWithSchemaAndDataValues(t, schemaYAML, valuesYAML, expectedErrMsg)
 	})
+	t.Run("when keyword arguments refer to constants loaded from another file", func(t *testing.T) {
+		constsStar := `load("@ytt:struct", "struct")
+
+consts = struct.make(MIN_PORT=1024, MAX_PORT=65535, MAX_NAME_LEN=8)
+`
+		schemaYAML := `#@ load("consts.star", "consts")
+
+#@data/values-schema
+---
+#@schema/validation min=consts.MIN_PORT, max=consts.MAX_PORT
+port: 8080
+#@schema/validation max_len=consts.MAX_NAME_LEN
+name: app
+`
+		dataValuesYAML := `#@data/values
+---
+port: 80
+name: application
+`
+		expectedErrMsg := `Validating final data values:
+  port
+    from: values.yaml:3
+    - must be: a value >= 1024 (by: schema.yaml:5)
+      found: value < 1024
+
+  name
+    from: values.yaml:4
+    - must be: length <= 8 (by: schema.yaml:7)
+      found: length = 11
+
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("consts.star", []byte(constsStar))),
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yaml", []byte(schemaYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("values.yaml", []byte(dataValuesYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErrMsg, cmdtpl.NewOptions())
+	})
 
 	t.Run("when @schema/nullable, skips if value is null (unless not_null=True)", func(t *testing.T) {
 		schemaYAML := `#@data/values-schema
@@ -1607,6 +2030,144 @@ bar: 0
 		assertFailsWithSchemaAndDataValues(t, schemaYAML, valuesYAML, expectedErrMsg)
 	})
 
+	t.Run("when a nullable map is null, skips the validations of its children (and its own, unless when_null_skip=False)", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/nullable
+tls:
+  #@schema/validation min_len=1
+  cert: ""
+  #@schema/validation min_len=1
+  key: ""
+#@schema/nullable
+#@schema/validation ("configured", lambda v: v != None), when_null_skip=False
+db:
+  #@schema/validation min_len=1
+  host: ""
+#@schema/nullable
+proxy:
+  #@schema/validation min_len=1
+  url: ""
+`
+		valuesYAML := `proxy:
+  url: ""
+`
+
+		expectedErrMsg := `Validating final data values:
+  db
+    from: schema.yaml:11
+    - must be: configured (by: schema.yaml:10)
+
+  proxy.url
+    from: values.yaml:2
+    - must be: length >= 1 (by: schema.yaml:16)
+      found: length = 0
+
+`
+		assertFailsWithSchemaAndDataValues(t, schemaYAML, valuesYAML, expectedErrMsg)
+	})
+
+	t.Run("when_truthy= and when_falsy= resolve a path from data values, when it is not of a sibling", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+tls:
+  enabled: false
+server:
+  #@schema/validation min_len=1, when_truthy="tls.enabled"
+  cert: ""
+  #@schema/validation min_len=1, when_falsy="tls.enabled"
+  port_name: ""
+`
+		valuesYAML := `tls:
+  enabled: true
+`
+
+		expectedErrMsg := `Validating final data values:
+  server.cert
+    from: schema.yaml:7
+    - must be: length >= 1 (by: schema.yaml:6)
+      found: length = 0
+
+`
+		assertFailsWithSchemaAndDataValues(t, schemaYAML, valuesYAML, expectedErrMsg)
+	})
+
+	t.Run("when a value is not a key of the map at the path given via key_of=", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+databases:
+  postgres:
+    host: pg.example.com
+app:
+  #@schema/validation key_of="databases"
+  database: postgres
+`
+		valuesYAML := `app:
+  database: sqlite
+`
+
+		expectedErrMsg := `Validating final data values:
+  app.database
+    from: values.yaml:2
+    - must be: a key of databases (by: schema.yaml:7)
+      found: "sqlite" is not a key of databases (which has: "postgres")
+
+`
+		assertFailsWithSchemaAndDataValues(t, schemaYAML, valuesYAML, expectedErrMsg)
+	})
+
+	t.Run("only when --enable-experimental-validations, for rules marked experimental=True", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation min=1, experimental=True
+replicas: 0
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yaml", []byte(schemaYAML))),
+		})
+
+		opts := cmdtpl.NewOptions()
+		out := opts.RunWithFiles(cmdtpl.Input{Files: filesToProcess}, ui.NewTTY(false))
+		require.NoError(t, out.Err)
+
+		opts = cmdtpl.NewOptions()
+		opts.DataValuesFlags.ExperimentalValidations = true
+		expectedErr := `Validating final data values:
+  replicas
+    from: schema.yaml:4
+    - must be: a value >= 1 (by: schema.yaml:3)
+      found: value < 1
+`
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+
+	t.Run("only for values whose path is selected by --validation-include and not --validation-exclude", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+db_conn:
+  #@schema/validation min_len=1
+  host: ""
+  #@schema/validation min=1
+  port: 0
+#@schema/validation min=1
+replicas: 0
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yaml", []byte(schemaYAML))),
+		})
+
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.ValidationInclude = []string{"db_conn.**"}
+		opts.DataValuesFlags.ValidationExclude = []string{"db_conn.h*"}
+		expectedErr := `Validating final data values:
+  db_conn.port
+    from: schema.yaml:7
+    - must be: a value >= 1 (by: schema.yaml:6)
+      found: value < 1
+`
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+
 	t.Run("even when @schema/type any=True", func(t *testing.T) {
 		schemaYAML := `#@data/values-schema
 ---
@@ -1621,6 +2182,84 @@ foo: 0
     from: values.yaml:1
     - must be: foo > 2 (by: schema.yaml:4)
 
+`
+		assertFailsWithSchemaAndDataValues(t, schemaYAML, valuesYAML, expectedErrMsg)
+	})
+
+	t.Run("on a map, when conditionally required via @schema/required-if", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/required-if "enabled", then=["cert", "key"]
+tls:
+  enabled: false
+  #@schema/nullable
+  cert: ""
+  #@schema/nullable
+  key: ""
+`
+		valuesYAML := `tls:
+  enabled: true
+  key: some-key
+`
+
+		expectedErrMsg := `Validating final data values:
+  tls
+    from: schema.yaml:4
+    - must be: all of ["cert", "key"] to be present and not null, when "enabled" is True (by: schema.yaml:3)
+      found: ["cert"] are null
+
+`
+		assertFailsWithSchemaAndDataValues(t, schemaYAML, valuesYAML, expectedErrMsg)
+	})
+
+	t.Run("on a map, when the number of items is limited via @schema/min-properties and @schema/max-properties", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/min-properties 1
+#@schema/max-properties 2
+labels:
+  app: ""
+  tier: ""
+  team: ""
+`
+		valuesYAML := `labels:
+  app: web
+`
+
+		expectedErrMsg := `Validating final data values:
+  labels
+    from: schema.yaml:5
+    - must be: number of properties <= 2 (by: schema.yaml:4)
+      found: length = 3
+
+`
+		assertFailsWithSchemaAndDataValues(t, schemaYAML, valuesYAML, expectedErrMsg)
+	})
+	t.Run("when bounds on a date (i.e. @schema/format \"date\") are compared chronologically", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/format "date"
+#@schema/validation min="2020-01-01", max="2020-12-31"
+released: "2020-06-30"
+#@schema/format "date"
+#@schema/validation min="2020-01-01"
+expires: "2020-06-30"
+`
+		valuesYAML := `---
+released: "2021-01-01"
+expires: "2020-1-1"
+`
+		expectedErrMsg := `Validating final data values:
+  released
+    from: values.yaml:2
+    - must be: a value <= "2020-12-31" (by: schema.yaml:4)
+      found: 2021-01-01 is after 2020-12-31
+
+  expires
+    from: values.yaml:3
+    - must be: a value >= "2020-01-01" (by: schema.yaml:7)
+      found: "2020-1-1" is not a date (e.g. 2006-01-02), so cannot be compared with 2020-01-01
+
 `
 		assertFailsWithSchemaAndDataValues(t, schemaYAML, valuesYAML, expectedErrMsg)
 	})
@@ -1676,6 +2315,74 @@ existing: foo
 	})
 }
 
+func TestSchema_checks_formats_of_strings_when_enabled(t *testing.T) {
+	schemaYAML := `#@data/values-schema
+---
+#@schema/format "byte"
+cert: ""
+#@schema/format "binary"
+blob: ""
+#@schema/nullable
+#@schema/format "byte"
+key: ""
+`
+	valuesYAML := `---
+cert: not base64!
+blob: anything
+key: a2V5
+`
+	newFiles := func() []*files.File {
+		return files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("values.yml", []byte("#@data/values\n"+valuesYAML))),
+		})
+	}
+
+	t.Run("reports strings that are not so encoded, when --validate-formats", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.ValidateFormats = true
+		expectedErr := `Validating final data values:
+  cert
+    from: values.yml:3
+    - must be: a base64-encoded string (by: schema.yml:4)
+      found: string that does not decode: illegal base64 data at input byte 3
+`
+		assertFails(t, newFiles(), expectedErr, opts)
+	})
+	t.Run("reports strings that are not dates, when --validate-formats", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/format "date"
+released: "2020-01-01"
+#@schema/format "date"
+expires: "2020-01-01"
+`
+		valuesYAML := `#@data/values
+---
+released: "2022-02-28"
+expires: "2022-02-30"
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("values.yml", []byte(valuesYAML))),
+		})
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.ValidateFormats = true
+		expectedErr := `Validating final data values:
+  expires
+    from: values.yml:4
+    - must be: a date (e.g. 2006-01-02) (by: schema.yml:6)
+      found: "2022-02-30"
+`
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("ignores formats, otherwise", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.Inspect = true
+		assertSucceedsDocSet(t, newFiles(), valuesYAML[len("---\n"):], opts)
+	})
+}
+
 func TestSchema_combines_validations_with_Data_Values(t *testing.T) {
 	t.Run("ignores/skips validation rules from Data Values overlay in most cases", func(t *testing.T) {
 		schemaYAML := `#@data/values-schema
@@ -4,12 +4,14 @@
 package template_test
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	cmdtpl "github.com/vmware-tanzu/carvel-ytt/pkg/cmd/template"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/cmd/ui"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/files"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/yamlmeta"
 )
 
 func TestSchemaInspect_exports_an_OpenAPI_doc(t *testing.T) {
@@ -278,24 +280,22 @@ components:
 
 		assertSucceedsDocSet(t, filesToProcess, expected, opts)
 	})
-	t.Run("including 'any' values", func(t *testing.T) {
-		t.Run("on documents", func(t *testing.T) {
-			opts := cmdtpl.NewOptions()
-			opts.DataValuesFlags.InspectSchema = true
-			opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+	t.Run("including nullable objects, with their properties", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
 
-			schemaYAML := `#@data/values-schema
-#@schema/type any=True
+		schemaYAML := `#@data/values-schema
 ---
-foo:
-  int_key: 0
-  array_of_scalars:
-  - ""
-  array_of_maps:
-  - foo: ""
-    bar: ""
+#@schema/nullable
+db:
+  host: localhost
+  port: 5432
+replicas:
+#@schema/nullable
+- name: ""
 `
-			expected := `openapi: 3.0.0
+		expected := `openapi: 3.0.0
 info:
   version: 0.1.0
   title: Schema for data values, generated by ytt
@@ -303,38 +303,61 @@ paths: {}
 components:
   schemas:
     dataValues:
-      nullable: true
-      default:
-        foo:
-          int_key: 0
-          array_of_scalars:
-          - ""
-          array_of_maps:
-          - foo: ""
-            bar: ""
+      type: object
+      additionalProperties: false
+      properties:
+        db:
+          type: object
+          additionalProperties: false
+          nullable: true
+          properties:
+            host:
+              type: string
+              default: localhost
+            port:
+              type: integer
+              default: 5432
+          default: null
+        replicas:
+          type: array
+          items:
+            type: object
+            additionalProperties: false
+            nullable: true
+            properties:
+              name:
+                type: string
+                default: ""
+            default: null
+          default: []
 `
-			filesToProcess := files.NewSortedFiles([]*files.File{
-				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
-			})
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
 
-			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("including nullable arrays, as distinct from arrays of nullable items", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/nullable
+nullable_array:
+- name: ""
+  port: 0
+array_of_nullables:
+#@schema/nullable
+- name: ""
+  port: 0
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
 		})
-		t.Run("on map items", func(t *testing.T) {
+
+		t.Run("in OpenAPI v3.0", func(t *testing.T) {
 			opts := cmdtpl.NewOptions()
 			opts.DataValuesFlags.InspectSchema = true
 			opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
 
-			schemaYAML := `#@data/values-schema
----
-#@schema/type any=True
-foo:
-  int_key: 0
-  array_of_scalars:
-  - ""
-  array_of_maps:
-  - foo: ""
-    bar: ""
-`
 			expected := `openapi: 3.0.0
 info:
   version: 0.1.0
@@ -346,39 +369,103 @@ components:
       type: object
       additionalProperties: false
       properties:
-        foo:
+        nullable_array:
+          type: array
           nullable: true
-          default:
-            int_key: 0
-            array_of_scalars:
-            - ""
-            array_of_maps:
-            - foo: ""
-              bar: ""
+          items:
+            type: object
+            additionalProperties: false
+            properties:
+              name:
+                type: string
+                default: ""
+              port:
+                type: integer
+                default: 0
+          default: null
+        array_of_nullables:
+          type: array
+          items:
+            type: object
+            additionalProperties: false
+            nullable: true
+            properties:
+              name:
+                type: string
+                default: ""
+              port:
+                type: integer
+                default: 0
+            default: null
+          default: []
 `
-			filesToProcess := files.NewSortedFiles([]*files.File{
-				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
-			})
-
 			assertSucceedsDocSet(t, filesToProcess, expected, opts)
 		})
-		t.Run("on array items", func(t *testing.T) {
+		t.Run("in OpenAPI v3.1", func(t *testing.T) {
 			opts := cmdtpl.NewOptions()
 			opts.DataValuesFlags.InspectSchema = true
-			opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3.1"}
 
-			schemaYAML := `#@data/values-schema
+			expected := `openapi: 3.1.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        nullable_array:
+          type:
+          - array
+          - "null"
+          items:
+            type: object
+            additionalProperties: false
+            properties:
+              name:
+                type: string
+                default: ""
+              port:
+                type: integer
+                default: 0
+          default: null
+        array_of_nullables:
+          type: array
+          items:
+            type:
+            - object
+            - "null"
+            additionalProperties: false
+            properties:
+              name:
+                type: string
+                default: ""
+              port:
+                type: integer
+                default: 0
+            default: null
+          default: []
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+	})
+	t.Run("including nullable enumerated values, listing null among their allowed values", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
 ---
-foo:
-  int_key: 0
-  array_of_scalars:
-  #@schema/type any=True
-  - ""
-  array_of_maps:
-  - foo: ""
-    bar: ""
+#@schema/nullable
+#@schema/validation one_of=["debug", "info"]
+log_level: info
+#@schema/validation one_of=["json", "text"]
+log_format: json
 `
-			expected := `openapi: 3.0.0
+		expected := `openapi: 3.0.0
 info:
   version: 0.1.0
   title: Schema for data values, generated by ytt
@@ -389,40 +476,550 @@ components:
       type: object
       additionalProperties: false
       properties:
-        foo:
+        log_level:
+          type: string
+          nullable: true
+          default: null
+          enum:
+          - debug
+          - info
+          - null
+        log_format:
+          type: string
+          default: json
+          enum:
+          - json
+          - text
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3.1"}
+		expected = `openapi: 3.1.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        log_level:
+          type:
+          - string
+          - "null"
+          default: null
+          enum:
+          - debug
+          - info
+          - null
+        log_format:
+          type: string
+          default: json
+          enum:
+          - json
+          - text
+`
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("including empty objects, defaulting to an empty object (unless nullable)", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+labels: {}
+pod:
+  annotations: {}
+#@schema/nullable
+selector: {}
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        labels:
+          type: object
+          additionalProperties: false
+          properties: {}
+          default: {}
+        pod:
           type: object
           additionalProperties: false
           properties:
-            int_key:
-              type: integer
-              default: 0
-            array_of_scalars:
-              type: array
-              items:
-                nullable: true
-                default: ""
-              default: []
-            array_of_maps:
+            annotations:
+              type: object
+              additionalProperties: false
+              properties: {}
+              default: {}
+        selector:
+          type: object
+          additionalProperties: false
+          nullable: true
+          properties: {}
+          default: null
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("including tree-shaped values built recursively, to the depth they were built", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		// schema is always a finite tree (it is inferred from a YAML document): there is no cycle to follow.
+		schemaYAML := `#@ def node(depth):
+name: ""
+#@ if depth > 0:
+children:
+- #@ node(depth - 1)
+#@ end
+#@ end
+
+#@data/values-schema
+---
+root: #@ node(1)
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        root:
+          type: object
+          additionalProperties: false
+          properties:
+            name:
+              type: string
+              default: ""
+            children:
               type: array
               items:
                 type: object
                 additionalProperties: false
                 properties:
-                  foo:
-                    type: string
-                    default: ""
-                  bar:
+                  name:
                     type: string
                     default: ""
               default: []
 `
-			filesToProcess := files.NewSortedFiles([]*files.File{
-				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
-			})
-
-			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
 		})
-	})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("including 'any' values", func(t *testing.T) {
+		t.Run("on documents", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+			schemaYAML := `#@data/values-schema
+#@schema/type any=True
+---
+foo:
+  int_key: 0
+  array_of_scalars:
+  - ""
+  array_of_maps:
+  - foo: ""
+    bar: ""
+`
+			expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      nullable: true
+      default:
+        foo:
+          int_key: 0
+          array_of_scalars:
+          - ""
+          array_of_maps:
+          - foo: ""
+            bar: ""
+`
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+		t.Run("with a null default, in the form of the OpenAPI version", func(t *testing.T) {
+			schemaYAML := `#@data/values-schema
+---
+#@schema/type any=True
+#@schema/default None
+foo:
+  bar: ""
+`
+			expectedV30 := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        foo:
+          nullable: true
+          default: null
+`
+			expectedV31 := `openapi: 3.1.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        foo:
+          default: null
+`
+			for outputType, expected := range map[string]string{"openapi-v3": expectedV30, "openapi-v3.1": expectedV31} {
+				opts := cmdtpl.NewOptions()
+				opts.DataValuesFlags.InspectSchema = true
+				opts.RegularFilesSourceOpts.OutputType.Types = []string{outputType}
+
+				filesToProcess := files.NewSortedFiles([]*files.File{
+					files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+				})
+
+				assertSucceedsDocSet(t, filesToProcess, expected, opts)
+			}
+		})
+		t.Run("on map items", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+			schemaYAML := `#@data/values-schema
+---
+#@schema/type any=True
+foo:
+  int_key: 0
+  array_of_scalars:
+  - ""
+  array_of_maps:
+  - foo: ""
+    bar: ""
+`
+			expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        foo:
+          nullable: true
+          default:
+            int_key: 0
+            array_of_scalars:
+            - ""
+            array_of_maps:
+            - foo: ""
+              bar: ""
+`
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+		t.Run("on array items", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+			schemaYAML := `#@data/values-schema
+---
+foo:
+  int_key: 0
+  array_of_scalars:
+  #@schema/type any=True
+  - ""
+  array_of_maps:
+  - foo: ""
+    bar: ""
+`
+			expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        foo:
+          type: object
+          additionalProperties: false
+          properties:
+            int_key:
+              type: integer
+              default: 0
+            array_of_scalars:
+              type: array
+              items:
+                nullable: true
+                default: ""
+              default: []
+            array_of_maps:
+              type: array
+              items:
+                type: object
+                additionalProperties: false
+                properties:
+                  foo:
+                    type: string
+                    default: ""
+                  bar:
+                    type: string
+                    default: ""
+              default: []
+`
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+		t.Run("on arrays of maps nested several levels deep", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+			schemaYAML := `#@data/values-schema
+---
+clusters:
+- name: ""
+  #@schema/type any=True
+  pools:
+  - name: default
+    nodes:
+    - labels:
+        tier: web
+      taints:
+      - key: dedicated
+        effect: NoSchedule
+    - labels: {}
+      taints: []
+  - name: spot
+    nodes: []
+  zones:
+  - #@schema/type any=True
+    - subnets:
+      - cidr: 10.0.0.0/24
+        tags:
+        - a
+        - b
+`
+			expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        clusters:
+          type: array
+          items:
+            type: object
+            additionalProperties: false
+            properties:
+              name:
+                type: string
+                default: ""
+              pools:
+                nullable: true
+                default:
+                - name: default
+                  nodes:
+                  - labels:
+                      tier: web
+                    taints:
+                    - key: dedicated
+                      effect: NoSchedule
+                  - labels: {}
+                    taints: []
+                - name: spot
+                  nodes: []
+              zones:
+                type: array
+                items:
+                  nullable: true
+                  default:
+                  - subnets:
+                    - cidr: 10.0.0.0/24
+                      tags:
+                      - a
+                      - b
+                default: []
+          default: []
+`
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+
+			reparsed, err := yamlmeta.NewDocumentSetFromBytes([]byte(expected), yamlmeta.DocSetOpts{})
+			require.NoError(t, err)
+			reparsedBytes, err := reparsed.AsBytes()
+			require.NoError(t, err)
+			require.Equal(t, expected, string(reparsedBytes))
+		})
+		t.Run("with multi-line strings in their default, in block style", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+			schemaYAML := `#@data/values-schema
+#@schema/type any=True
+---
+hooks:
+  pre_start: "#!/bin/sh\nset -e\necho starting\n"
+  motd: "Welcome,\noperator."
+  trailing_space: "not  \nas a block"
+`
+			// a line ending in whitespace would not survive as a block scalar: such a string remains quoted.
+			expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      nullable: true
+      default:
+        hooks:
+          pre_start: |
+            #!/bin/sh
+            set -e
+            echo starting
+          motd: |-
+            Welcome,
+            operator.
+          trailing_space: "not  \nas a block"
+`
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+
+			reparsed, err := yamlmeta.NewDocumentSetFromBytes([]byte(expected), yamlmeta.DocSetOpts{})
+			require.NoError(t, err)
+			reparsedBytes, err := reparsed.AsBytes()
+			require.NoError(t, err)
+			require.Equal(t, expected, string(reparsedBytes))
+		})
+	})
+	t.Run("including float values in their shortest round-trip form", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+tenth: 0.1
+#@schema/default 0.1 + 0.2
+sum: 0.0
+whole: 1.0
+#@schema/examples ("tiny", 1e-7)
+tiny: 0.0000001
+large: 12345678901234567890.0
+huge: 1.5e+300
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        tenth:
+          type: number
+          format: float
+          default: 0.1
+        sum:
+          type: number
+          format: float
+          default: 0.30000000000000004
+        whole:
+          type: number
+          format: float
+          default: 1
+        tiny:
+          type: number
+          format: float
+          x-example-description: tiny
+          example: 1e-07
+          default: 1e-07
+        large:
+          type: number
+          format: float
+          default: 1.2345678901234567e+19
+        huge:
+          type: number
+          format: float
+          default: 1.5e+300
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
 	t.Run("including nullable values with defaults", func(t *testing.T) {
 		opts := cmdtpl.NewOptions()
 		opts.DataValuesFlags.InspectSchema = true
@@ -430,40 +1027,2971 @@ components:
 
 		schemaYAML := `#@data/values-schema
 ---
-foo:
-  #@schema/default 10
+foo:
+  #@schema/default 10
+  #@schema/nullable
+  int_key: 0
+
+  #@schema/default True
+  #@schema/nullable
+  bool_key: false
+
+  #@schema/nullable
+  #@schema/default False
+  false_key: true
+
+  #@schema/nullable
+  #@schema/default "some text"
+  string_key: ""
+
+  #@schema/nullable
+  #@schema/default 9.1
+  float_key: 0.0
+
+  #@schema/nullable
+  #@schema/default [1,2,3]
+  array_of_scalars:
+  - 0
+
+  #@schema/default [{"bar": "thing 1"},{"bar": "thing 2"}, {"bar": "thing 3"}]
+  #@schema/nullable
+  array_of_maps:
+  -
+    #@schema/nullable
+    bar: ""
+    #@schema/nullable
+    ree: ""
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        foo:
+          type: object
+          additionalProperties: false
+          properties:
+            int_key:
+              type: integer
+              nullable: true
+              default: 10
+            bool_key:
+              type: boolean
+              nullable: true
+              default: true
+            false_key:
+              type: boolean
+              nullable: true
+              default: false
+            string_key:
+              type: string
+              nullable: true
+              default: some text
+            float_key:
+              type: number
+              format: float
+              nullable: true
+              default: 9.1
+            array_of_scalars:
+              type: array
+              nullable: true
+              items:
+                type: integer
+                default: 0
+              default:
+              - 1
+              - 2
+              - 3
+            array_of_maps:
+              type: array
+              nullable: true
+              items:
+                type: object
+                additionalProperties: false
+                properties:
+                  bar:
+                    type: string
+                    nullable: true
+                    default: null
+                  ree:
+                    type: string
+                    nullable: true
+                    default: null
+              default:
+              - bar: thing 1
+                ree: null
+              - bar: thing 2
+                ree: null
+              - bar: thing 3
+                ree: null
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("including values that can only be null", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/nullable
+#@schema/type any=False
+placeholder: ~
+`
+		t.Run("as a nullable value whose only allowed value is null, in OpenAPI v3.0", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+			expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        placeholder:
+          nullable: true
+          default: null
+          enum:
+          - null
+`
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+		t.Run("as the null type, in OpenAPI v3.1", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3.1"}
+
+			expected := `openapi: 3.1.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        placeholder:
+          type: "null"
+          default: null
+`
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+	})
+	t.Run("conforming to OpenAPI v3.1, when --output is 'openapi-v3.1'", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3.1"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/nullable
+name: ""
+#@schema/nullable
+tls:
+  cert: ""
+#@schema/type any=True
+extra: {}
+`
+		expected := `openapi: 3.1.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        name:
+          type:
+          - string
+          - "null"
+          default: null
+        tls:
+          type:
+          - object
+          - "null"
+          additionalProperties: false
+          properties:
+            cert:
+              type: string
+              default: ""
+          default: null
+        extra:
+          default: {}
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("with maxLength inferred from string defaults, when --openapi-infer-string-maxlen", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.DataValuesFlags.InspectSchemaInferMaxLen = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+hostname: localhost
+#@schema/validation max_len=253
+fqdn: example.com
+namespace: ""
+names:
+- héllo
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        hostname:
+          type: string
+          default: localhost
+          maxLength: 9
+        fqdn:
+          type: string
+          default: example.com
+          maxLength: 253
+        namespace:
+          type: string
+          default: ""
+        names:
+          type: array
+          items:
+            type: string
+            default: héllo
+            maxLength: 5
+          default: []
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("with validations that have no OpenAPI keyword as extensions, when --data-values-schema-inspect-validations", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.DataValuesFlags.InspectSchemaValidations = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation max_decimals=2
+price: 1.5
+#@schema/validation sorted="desc", not_one_of=[["none"]]
+priorities:
+- ""
+#@schema/nullable
+#@schema/validation one_not_null=["cert", "secret"]
+tls:
+  cert: ""
+  secret: ""
+#@schema/validation min=1
+replicas: 1
+#@schema/validation valid_json=True
+settings: "{}"
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        price:
+          type: number
+          format: float
+          default: 1.5
+          x-ytt-validations:
+          - rule: max_decimals
+            value: 2
+        priorities:
+          type: array
+          items:
+            type: string
+            default: ""
+          default: []
+          not:
+            enum:
+            - - none
+          x-ytt-validations:
+          - rule: sorted
+            value: desc
+        tls:
+          type: object
+          additionalProperties: false
+          nullable: true
+          properties:
+            cert:
+              type: string
+              default: ""
+            secret:
+              type: string
+              default: ""
+          default: null
+          x-ytt-validations:
+          - rule: one_not_null
+            value:
+            - cert
+            - secret
+        replicas:
+          type: integer
+          default: 1
+          minimum: 1
+        settings:
+          type: string
+          default: '{}'
+          x-ytt-validations:
+          - rule: valid_json
+            value: true
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("with all objects allowing additional properties, when --openapi-additional-properties", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.DataValuesFlags.InspectSchemaAddlProps = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+db:
+  host: localhost
+users:
+- name: ""
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: true
+      properties:
+        db:
+          type: object
+          additionalProperties: true
+          properties:
+            host:
+              type: string
+              default: localhost
+        users:
+          type: array
+          items:
+            type: object
+            additionalProperties: true
+            properties:
+              name:
+                type: string
+                default: ""
+          default: []
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("with properties in alphabetical order, when --openapi-sort-keys", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.DataValuesFlags.InspectSchemaSortKeys = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+replicas: 1
+db:
+  port: 5432
+  host: localhost
+app: ""
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        app:
+          type: string
+          default: ""
+        db:
+          type: object
+          additionalProperties: false
+          properties:
+            host:
+              type: string
+              default: localhost
+            port:
+              type: integer
+              default: 5432
+        replicas:
+          type: integer
+          default: 1
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("with the position of each property in schema, when --openapi-x-order", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+replicas: 1
+db:
+  port: 5432
+  host: localhost
+app: ""
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		t.Run("even as properties are listed in alphabetical order", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.DataValuesFlags.InspectSchemaXOrder = true
+			opts.DataValuesFlags.InspectSchemaSortKeys = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+			expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        app:
+          type: string
+          default: ""
+          x-order: 2
+        db:
+          type: object
+          additionalProperties: false
+          properties:
+            host:
+              type: string
+              default: localhost
+              x-order: 1
+            port:
+              type: integer
+              default: 5432
+              x-order: 0
+          x-order: 1
+        replicas:
+          type: integer
+          default: 1
+          x-order: 0
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+		t.Run("unless stripping extensions, when --openapi-strip-x-extensions", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.DataValuesFlags.InspectSchemaXOrder = true
+			opts.DataValuesFlags.InspectSchemaNoExtensions = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+			expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        replicas:
+          type: integer
+          default: 1
+        db:
+          type: object
+          additionalProperties: false
+          properties:
+            port:
+              type: integer
+              default: 5432
+            host:
+              type: string
+              default: localhost
+        app:
+          type: string
+          default: ""
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+	})
+	t.Run("with the schema of data values under the given name, when --openapi-schema-name", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.DataValuesFlags.InspectSchemaName = "MyConfig"
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+replicas: 1
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    MyConfig:
+      type: object
+      additionalProperties: false
+      properties:
+        replicas:
+          type: integer
+          default: 1
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("with defaults as examples, when --openapi-defaults-as-examples", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.DataValuesFlags.InspectSchemaDefaultsAsEx = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/examples ("a typical host", "db.example.com")
+host: localhost
+default: 1
+#@schema/nullable
+tls:
+  cert: ""
+users:
+- name: admin
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        host:
+          type: string
+          x-example-description: a typical host
+          example: db.example.com
+        default:
+          type: integer
+          example: 1
+        tls:
+          type: object
+          additionalProperties: false
+          nullable: true
+          example: null
+          properties:
+            cert:
+              type: string
+              example: ""
+        users:
+          type: array
+          example: []
+          items:
+            type: object
+            additionalProperties: false
+            properties:
+              name:
+                type: string
+                example: admin
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("without extensions, when --openapi-strip-x-extensions", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.DataValuesFlags.InspectSchemaValidations = true
+		opts.DataValuesFlags.InspectSchemaNoExtensions = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/examples ("a typical host", "db.example.com")
+host: localhost
+#@schema/validation sorted=True
+ports:
+- #@schema/examples ("https", 443)
+  0
+#! the names of values (and the values themselves) are kept, whatever they are.
+x-headers:
+  x-request-id: ""
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        host:
+          type: string
+          example: db.example.com
+          default: localhost
+        ports:
+          type: array
+          items:
+            type: integer
+            example: 443
+            default: 0
+          default: []
+        x-headers:
+          type: object
+          additionalProperties: false
+          properties:
+            x-request-id:
+              type: string
+              default: ""
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("with titles derived from keys, when --openapi-title-from-key", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.DataValuesFlags.InspectSchemaKeyTitles = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+db_conn:
+  #@schema/title "Server"
+  host-name: localhost
+tags:
+- ""
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        db_conn:
+          title: Db Conn
+          type: object
+          additionalProperties: false
+          properties:
+            host-name:
+              title: Server
+              type: string
+              default: localhost
+        tags:
+          title: Tags
+          type: array
+          items:
+            type: string
+            default: ""
+          default: []
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("with top-level scalars as query parameters, when --openapi-parameters", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.DataValuesFlags.InspectSchemaParameters = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/desc "Number of items per page"
+#@schema/validation min=1
+limit: 20
+#@schema/deprecated "use limit"
+size: 0
+db:
+  host: localhost
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  parameters:
+    limit:
+      name: limit
+      in: query
+      description: Number of items per page
+      schema:
+        type: integer
+        default: 20
+        minimum: 1
+    size:
+      name: size
+      in: query
+      deprecated: true
+      schema:
+        type: integer
+        default: 0
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        db:
+          type: object
+          additionalProperties: false
+          properties:
+            host:
+              type: string
+              default: localhost
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("without paths, when --openapi-emit-empty-paths=false", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.DataValuesFlags.InspectSchemaEmptyPaths = false
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+port: 8080
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        port:
+          type: integer
+          default: 8080
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("split into a file per top-level value, when --openapi-split-components", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.DataValuesFlags.InspectSchemaSplit = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+db:
+  host: localhost
+replicas: 1
+`
+		expectedFiles := map[string]string{
+			"openapi.yaml": `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        db:
+          $ref: schemas/db.yaml
+        replicas:
+          $ref: schemas/replicas.yaml
+`,
+			"schemas/db.yaml": `type: object
+additionalProperties: false
+properties:
+  host:
+    type: string
+    default: localhost
+`,
+			"schemas/replicas.yaml": `type: integer
+default: 1
+`,
+		}
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		out := opts.RunWithFiles(cmdtpl.Input{Files: filesToProcess}, ui.NewTTY(false))
+		require.NoError(t, out.Err)
+		require.Len(t, out.Files, len(expectedFiles))
+		for _, file := range out.Files {
+			require.Equal(t, expectedFiles[file.RelativePath()], string(file.Bytes()), file.RelativePath())
+		}
+		require.Len(t, out.DocSet.Items, len(expectedFiles))
+	})
+	t.Run("split into a file per top-level value, keeping deprecated values so where referenced", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.DataValuesFlags.InspectSchemaSplit = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/deprecated "use db"
+legacy_db:
+  host: localhost
+db:
+  host: localhost
+`
+		expectedFiles := map[string]string{
+			"openapi.yaml": `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        legacy_db:
+          allOf:
+          - $ref: schemas/legacy_db.yaml
+          deprecated: true
+        db:
+          $ref: schemas/db.yaml
+`,
+			"schemas/legacy_db.yaml": `type: object
+additionalProperties: false
+deprecated: true
+properties:
+  host:
+    type: string
+    default: localhost
+`,
+			"schemas/db.yaml": `type: object
+additionalProperties: false
+properties:
+  host:
+    type: string
+    default: localhost
+`,
+		}
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		out := opts.RunWithFiles(cmdtpl.Input{Files: filesToProcess}, ui.NewTTY(false))
+		require.NoError(t, out.Err)
+		require.Len(t, out.Files, len(expectedFiles))
+		for _, file := range out.Files {
+			require.Equal(t, expectedFiles[file.RelativePath()], string(file.Bytes()), file.RelativePath())
+		}
+	})
+	t.Run("without values marked deprecated, when --openapi-exclude-deprecated", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.DataValuesFlags.InspectSchemaNoDeprecated = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+db:
+  #@schema/deprecated "use db.url"
+  host: localhost
+  url: ""
+#@schema/deprecated ""
+#@schema/nullable
+legacy:
+  enabled: false
+replicas: 1
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        db:
+          type: object
+          additionalProperties: false
+          properties:
+            url:
+              type: string
+              default: ""
+        replicas:
+          type: integer
+          default: 1
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("with the case of strings required via lowercase= or uppercase=, as a pattern", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation lowercase=True
+name: app
+#@schema/validation uppercase=True
+region: US
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        name:
+          type: string
+          default: app
+          pattern: ^[^A-Z]*$
+        region:
+          type: string
+          default: US
+          pattern: ^[^a-z]*$
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("with the prefix and suffix given via starts_with= and ends_with=, as a pattern", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation starts_with="https://"
+url: https://example.com
+#@schema/validation ends_with=".example.com"
+host: api.example.com
+#@schema/validation starts_with="v", ends_with="-rc"
+version: v1.0.0-rc
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        url:
+          type: string
+          default: https://example.com
+          pattern: ^https://
+        host:
+          type: string
+          default: api.example.com
+          pattern: \.example\.com$
+        version:
+          type: string
+          default: v1.0.0-rc
+          pattern: ^(?=v)[\s\S]*-rc$
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("with the pattern given via pattern=, even when loaded from a library", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		patternsStar := `DNS_LABEL = "^[a-z0-9]([-a-z0-9]*[a-z0-9])?$"
+`
+		schemaYAML := `#@ load("patterns.star", "DNS_LABEL")
+#@data/values-schema
+---
+#@schema/validation pattern=DNS_LABEL
+host: my-app
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        host:
+          type: string
+          default: my-app
+          pattern: ^[a-z0-9]([-a-z0-9]*[a-z0-9])?$
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("patterns.star", []byte(patternsStar))),
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("with the format of durations and timestamps given via duration= and timestamp=", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation duration=True
+timeout: 30s
+#@schema/validation timestamp=True
+not_after: "2030-01-01T00:00:00Z"
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        timeout:
+          type: string
+          format: duration
+          default: 30s
+        not_after:
+          type: string
+          format: date-time
+          default: "2030-01-01T00:00:00Z"
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("with the allowed values of a string alongside its length rules", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation one_of=["small", "medium", "large"], min_len=5, max_len=6
+size: small
+#@schema/nullable
+#@schema/validation min_len=1
+zone: ""
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        size:
+          type: string
+          default: small
+          enum:
+          - small
+          - medium
+          - large
+          minLength: 5
+          maxLength: 6
+        zone:
+          type: string
+          nullable: true
+          default: null
+          minLength: 1
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("with the format of URLs given via url=", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation url=True, url_schemes=["https"]
+webhook: https://hooks.example.com/notify
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        webhook:
+          type: string
+          format: uri
+          default: https://hooks.example.com/notify
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("with the format of semantic versions given via semver=", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation semver=True, semver_range=">= 1.2.0"
+version: 1.2.0
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        version:
+          type: string
+          format: semver
+          default: 1.2.0
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("with the format of IP addresses and CIDRs given via ip= and cidr=", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation ip="v4"
+router: 192.168.0.1
+#@schema/validation ip="v6"
+gateway: fd00::1
+#@schema/validation ip=True
+dns: 10.0.0.53
+#@schema/validation cidr=True
+pods: 10.244.0.0/16
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        router:
+          type: string
+          format: ipv4
+          default: 192.168.0.1
+        gateway:
+          type: string
+          format: ipv6
+          default: fd00::1
+        dns:
+          type: string
+          default: 10.0.0.53
+        pods:
+          type: string
+          format: cidr
+          default: 10.244.0.0/16
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("with exclusive bounds, in the form of the OpenAPI version", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation exclusive_min=0, exclusive_max=1
+ratio: 0.5
+`
+		expectedV30 := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        ratio:
+          type: number
+          format: float
+          default: 0.5
+          minimum: 0
+          exclusiveMinimum: true
+          maximum: 1
+          exclusiveMaximum: true
+`
+		expectedV31 := `openapi: 3.1.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        ratio:
+          type: number
+          format: float
+          default: 0.5
+          exclusiveMinimum: 0
+          exclusiveMaximum: 1
+`
+		for outputType, expected := range map[string]string{"openapi-v3": expectedV30, "openapi-v3.1": expectedV31} {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{outputType}
+
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		}
+	})
+	t.Run("of just the part at a JSON Pointer, when --data-values-schema-inspect-pointer", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+db_conn:
+  host: ""
+  #@schema/desc "Port of the database"
+  #@schema/validation min=1, max=65535
+  port: 5432
+  replicas:
+  - ""
+`
+		for pointer, expected := range map[string]string{
+			"/properties/db_conn/properties/port": `type: integer
+description: Port of the database
+default: 5432
+minimum: 1
+maximum: 65535
+`,
+			"/properties/db_conn/properties/replicas/items": `type: string
+default: ""
+`,
+		} {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.DataValuesFlags.InspectSchemaPointer = pointer
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		}
+	})
+	t.Run("with numeric bounds (given via min=, max= or in_range=) on the value bounded: each item of a list, or the value itself", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation min=1, max=5
+replicas: 1
+#@schema/validation in_range=(1, 10)
+workers: 2
+#@schema/validation min=1, exclusive_max=65536
+ports:
+- 80
+#@schema/nullable
+#@schema/validation max=100
+weights:
+- 1
+#@schema/validation min=[1, 0]
+version:
+- 1
+#@schema/validation min="a"
+name: app
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        replicas:
+          type: integer
+          default: 1
+          minimum: 1
+          maximum: 5
+        workers:
+          type: integer
+          default: 2
+          minimum: 1
+          maximum: 10
+        ports:
+          type: array
+          items:
+            type: integer
+            default: 80
+            minimum: 1
+            maximum: 65536
+            exclusiveMaximum: true
+          default: []
+        weights:
+          type: array
+          nullable: true
+          items:
+            type: integer
+            default: 1
+            maximum: 100
+          default: null
+        version:
+          type: array
+          items:
+            type: integer
+            default: 1
+          default: []
+        name:
+          type: string
+          default: app
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("with the format of strings given via @schema/format", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/format "byte"
+cert: ""
+#@schema/nullable
+#@schema/format "binary"
+blob: ""
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        cert:
+          type: string
+          format: byte
+          default: ""
+        blob:
+          type: string
+          format: binary
+          nullable: true
+          default: null
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("with the unit of values given via @schema/unit, as the extension x-unit", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/unit "seconds"
+timeout: 30
+#@schema/nullable
+#@schema/unit "MiB"
+memory: 512
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        timeout:
+          type: integer
+          x-unit: seconds
+          default: 30
+        memory:
+          type: integer
+          x-unit: MiB
+          nullable: true
+          default: null
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+
+		opts.DataValuesFlags.InspectSchemaNoExtensions = true
+		expected = `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        timeout:
+          type: integer
+          default: 30
+        memory:
+          type: integer
+          nullable: true
+          default: null
+`
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("inferred from plain data values, when --data-values-schema-inspect-infer", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags = cmdtpl.DataValuesFlags{
+			InspectSchema:           true,
+			InspectSchemaInfer:      true,
+			InspectSchemaEmptyPaths: true,
+			FromFiles:               []string{"values.yml"},
+			ReadFilesFunc: func(path string) ([]*files.File, error) {
+				valuesYAML := `
+replicas: 3
+db:
+  host: db.example.com
+ports:
+- 80
+- 443
+`
+				return []*files.File{files.MustNewFileFromSource(files.NewBytesSource(path, []byte(valuesYAML)))}, nil
+			},
+		}
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        replicas:
+          type: integer
+          default: 3
+        db:
+          type: object
+          additionalProperties: false
+          properties:
+            host:
+              type: string
+              default: db.example.com
+        ports:
+          type: array
+          items:
+            type: integer
+            default: 80
+          default:
+          - 80
+          - 443
+`
+		assertSucceedsDocSet(t, []*files.File{}, expected, opts)
+	})
+	t.Run("inferred from plain data values, failing on an empty array", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags = cmdtpl.DataValuesFlags{
+			InspectSchema:      true,
+			InspectSchemaInfer: true,
+			FromFiles:          []string{"values.yml"},
+			ReadFilesFunc: func(path string) ([]*files.File, error) {
+				return []*files.File{files.MustNewFileFromSource(files.NewBytesSource(path, []byte("ports: []\n")))}, nil
+			},
+		}
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		expectedErr := `Unable to infer schema from example
+===================================
+
+values.yml:
+    |
+  1 | ports: []
+    |
+
+    = found: empty array
+    = expected: at least 1 array item, from which to infer the type of items
+    = hint: include an item of the desired type in the example.
+`
+		assertFails(t, []*files.File{}, expectedErr, opts)
+	})
+	t.Run("inferred from plain data values, failing on an array of items of different types", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags = cmdtpl.DataValuesFlags{
+			InspectSchema:      true,
+			InspectSchemaInfer: true,
+			FromFiles:          []string{"values.yml"},
+			ReadFilesFunc: func(path string) ([]*files.File, error) {
+				return []*files.File{files.MustNewFileFromSource(files.NewBytesSource(path, []byte("sidecars:\n- envoy\n- name: istio\n")))}, nil
+			},
+		}
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		expectedErr := `Unable to infer schema from example
+===================================
+
+values.yml:
+    |
+  3 | - name: istio
+    |
+
+    = found: map
+    = expected: string (like the first item), from which to infer the type of items
+    = hint: give all items of the example the same type.
+    = hint: to allow items of several types, declare schema (rather than inferring it), annotating the array item with @schema/one-of.
+`
+		assertFails(t, []*files.File{}, expectedErr, opts)
+	})
+	t.Run("with descriptions from comments, when --openapi-desc-from-comments", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.DataValuesFlags.InspectSchemaDescComments = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+#! Number of pods to run.
+replicas: 1
+#! Database connection
+#! settings.
+db:
+  host: localhost
+  #@schema/desc "Port to connect to."
+  #! Not the description.
+  port: 5432
+#! Users allowed in.
+users:
+#! A user.
+- name: ""
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        replicas:
+          type: integer
+          description: Number of pods to run.
+          default: 1
+        db:
+          type: object
+          additionalProperties: false
+          description: Database connection settings.
+          properties:
+            host:
+              type: string
+              default: localhost
+            port:
+              type: integer
+              description: Port to connect to.
+              default: 5432
+        users:
+          type: array
+          description: Users allowed in.
+          items:
+            type: object
+            additionalProperties: false
+            description: A user.
+            properties:
+              name:
+                type: string
+                default: ""
+          default: []
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("with defaults that include data values given via --data-values-file", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags = cmdtpl.DataValuesFlags{
+			InspectSchema:           true,
+			InspectSchemaWithValues: true,
+			InspectSchemaEmptyPaths: true,
+			FromFiles:               []string{"prod.yml"},
+			ReadFilesFunc: func(path string) ([]*files.File, error) {
+				valuesYAML := `
+replicas: 3
+db:
+  host: db.prod.example.com
+ports:
+- 443
+nullable_map:
+  name: primary
+`
+				return []*files.File{files.MustNewFileFromSource(files.NewBytesSource(path, []byte(valuesYAML)))}, nil
+			},
+		}
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+replicas: 1
+db:
+  host: localhost
+  port: 5432
+ports:
+- 80
+#@schema/nullable
+nullable_map:
+  name: ""
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        replicas:
+          type: integer
+          default: 3
+        db:
+          type: object
+          additionalProperties: false
+          properties:
+            host:
+              type: string
+              default: db.prod.example.com
+            port:
+              type: integer
+              default: 5432
+        ports:
+          type: array
+          items:
+            type: integer
+            default: 80
+          default:
+          - 443
+        nullable_map:
+          type: object
+          additionalProperties: false
+          nullable: true
+          properties:
+            name:
+              type: string
+              default: primary
+          default:
+            name: primary
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("where only nullable values default to null", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+name: ""
+#@schema/nullable
+alias: ""
+db:
+  #@schema/nullable
+  replica:
+    port: 5432
+#@schema/nullable
+ports:
+- 80
+`
+		for _, outputType := range []string{"openapi-v3", "openapi-v3.1"} {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{outputType}
+
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+			out := opts.RunWithFiles(cmdtpl.Input{Files: filesToProcess}, ui.NewTTY(false))
+			require.NoError(t, out.Err)
+
+			// i.e. components.schemas.dataValues
+			components := out.DocSet.Items[0].Value.(*yamlmeta.Map).Items[3].Value.(*yamlmeta.Map)
+			dataValues := components.Items[0].Value.(*yamlmeta.Map).Items[0].Value.(*yamlmeta.Map)
+			var nullDefaults []string
+			var visit func(path string, schema *yamlmeta.Map)
+			visit = func(path string, schema *yamlmeta.Map) {
+				for _, prop := range schema.Items {
+					switch prop.Key {
+					case "default":
+						if prop.Value == nil {
+							nullDefaults = append(nullDefaults, path)
+						}
+					case "properties":
+						for _, property := range prop.Value.(*yamlmeta.Map).Items {
+							visit(path+"."+property.Key.(string), property.Value.(*yamlmeta.Map))
+						}
+					case "items":
+						visit(path+"[]", prop.Value.(*yamlmeta.Map))
+					}
+				}
+			}
+			visit("", dataValues)
+			require.Equal(t, []string{".alias", ".db.replica", ".ports"}, nullDefaults, outputType)
+		}
+	})
+}
+
+func TestSchemaInspect_exports_Markdown_documentation(t *testing.T) {
+	t.Run("as a table with a row for each value", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"markdown"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/desc "Name of the app | service"
+name: app
+#@schema/nullable
+port: 8080
+db:
+  #@schema/deprecated ""
+  host: localhost
+#@schema/desc "Tags applied,\nin order."
+#@schema/validation exclusive_min=0
+tags:
+- 1
+ratio: 0.5
+`
+		expected := "| Path | Type | Default | Description | Constraints |\n" +
+			"|------|------|---------|-------------|-------------|\n" +
+			"| `name` | string | `\"app\"` | Name of the app \\| service |  |\n" +
+			"| `port` | integer, nullable | `null` |  |  |\n" +
+			"| `db` | object |  |  |  |\n" +
+			"| `db.host` | string | `\"localhost\"` | **Deprecated.** |  |\n" +
+			"| `tags` | array | `[]` | Tags applied,<br>in order. |  |\n" +
+			"| `tags[]` | integer | `1` |  | minimum: `0`, exclusiveMinimum: `true` |\n" +
+			"| `ratio` | number (float) | `0.5` |  |  |\n"
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		out := opts.RunWithFiles(cmdtpl.Input{Files: filesToProcess}, ui.NewTTY(false))
+		require.NoError(t, out.Err)
+		require.Len(t, out.Files, 1)
+		require.Equal(t, expected, string(out.Files[0].Bytes()))
+	})
+}
+
+func TestSchemaInspect_exports_TypeScript_definitions(t *testing.T) {
+	t.Run("as an interface with a member for each value", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"typescript"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/desc "Name of the app"
+name: app
+#@schema/nullable
+port: 8080
+db:
+  #@schema/deprecated ""
+  host: localhost
+#@schema/desc "Tags applied,\nin order."
+tags:
+- 1
+#@schema/validation one_of=["dev", "prod"]
+env: dev
+content-type: json
+`
+		expected := `// Types of data values, generated by ytt
+
+export interface DataValues {
+  /** Name of the app */
+  name: string;
+  port?: number | null;
+  db: {
+    /** @deprecated */
+    host: string;
+  };
+  /**
+   * Tags applied,
+   * in order.
+   */
+  tags: number[];
+  env: "dev" | "prod";
+  "content-type": string;
+}
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		out := opts.RunWithFiles(cmdtpl.Input{Files: filesToProcess}, ui.NewTTY(false))
+		require.NoError(t, out.Err)
+		require.Len(t, out.Files, 1)
+		require.Equal(t, "data-values-schema.ts", out.Files[0].RelativePath())
+		require.Equal(t, expected, string(out.Files[0].Bytes()))
+	})
+}
+
+func TestSchemaInspect_transforms_the_OpenAPI_doc(t *testing.T) {
+	schemaYAML := `#@data/values-schema
+---
+port: 8080
+`
+	filesToProcess := files.NewSortedFiles([]*files.File{
+		files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+	})
+
+	t.Run("via the given OpenAPITransform, before it is output", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+		opts.OpenAPITransform = func(doc *yamlmeta.Document) (*yamlmeta.Document, error) {
+			root := doc.Value.(*yamlmeta.Map)
+			root.Items = append(root.Items, &yamlmeta.MapItem{Key: "servers", Value: &yamlmeta.Array{Items: []*yamlmeta.ArrayItem{
+				{Value: &yamlmeta.Map{Items: []*yamlmeta.MapItem{{Key: "url", Value: "https://example.com"}}}},
+			}}})
+			return doc, nil
+		}
+
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        port:
+          type: integer
+          default: 8080
+servers:
+- url: https://example.com
+`
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("failing, when the OpenAPITransform does", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+		opts.OpenAPITransform = func(doc *yamlmeta.Document) (*yamlmeta.Document, error) {
+			return nil, fmt.Errorf("no servers configured")
+		}
+
+		out := opts.RunWithFiles(cmdtpl.Input{Files: filesToProcess}, ui.NewTTY(false))
+		require.EqualError(t, out.Err, "Transforming OpenAPI document: no servers configured")
+	})
+}
+
+func TestSchemaInspect_diffs_against_a_baseline(t *testing.T) {
+	baselineYAML := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        log_level:
+          type: string
+          default: info
+          enum:
+          - debug
+          - info
+          - warn
+        db:
+          type: object
+          additionalProperties: false
+          properties:
+            host:
+              type: string
+              default: localhost
+            user:
+              type: string
+              default: admin
+        ports:
+          type: array
+          items:
+            type: integer
+            default: 80
+          default: []
+`
+	optsWithBaseline := func() *cmdtpl.Options {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.DataValuesFlags.InspectSchemaDiffFile = "baseline.yml"
+		opts.DataValuesFlags.ReadFilesFunc = func(path string) ([]*files.File, error) {
+			return []*files.File{files.MustNewFileFromSource(files.NewBytesSource(path, []byte(baselineYAML)))}, nil
+		}
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+		return opts
+	}
+
+	t.Run("reporting the changes that are not breaking", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/nullable
+#@schema/validation one_of=["debug", "info", "warn", "error"]
+log_level: info
+db:
+  host: db.example.com
+  user: admin
+  port: 5432
+ports:
+- 80
+`
+		expected := `Other changes:
+- log_level: type changed from string to string | null
+- log_level: now also allows ["error", null]
+- log_level: default changed from "info" to null
+- db.host: default changed from "localhost" to "db.example.com"
+- db.port: added
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		out := optsWithBaseline().RunWithFiles(cmdtpl.Input{Files: filesToProcess}, ui.NewTTY(false))
+		require.NoError(t, out.Err)
+		require.Len(t, out.Files, 1)
+		require.Equal(t, "data-values-schema-diff.txt", out.Files[0].RelativePath())
+		require.Equal(t, expected, string(out.Files[0].Bytes()))
+	})
+	t.Run("failing when there are breaking changes", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation one_of=["debug", "info"]
+log_level: info
+db:
+  host: localhost
+ports:
+- "80"
+`
+		expectedErr := `Schema of data values has breaking changes (compared with 'baseline.yml'):
+
+Breaking changes:
+- log_level: no longer allows ["warn"]
+- db.user: removed
+- ports[]: type changed from integer to string
+
+Other changes:
+- ports[]: default changed from 80 to "80"
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		out := optsWithBaseline().RunWithFiles(cmdtpl.Input{Files: filesToProcess}, ui.NewTTY(false))
+		require.EqualError(t, out.Err, expectedErr)
+	})
+}
+
+func TestSchemaInspect_reports_the_schema_of_a_library(t *testing.T) {
+	libSchemaYAML := `#@data/values-schema
+---
+db:
+  host: localhost
+  port: 5432
+`
+	rootSchemaYAML := `#@data/values-schema
+---
+env: dev
+
+#@library/ref "@lib"
+#@data/values-schema
+---
+db:
+  #@overlay/match missing_ok=True
+  #@schema/desc "Name of the database to connect to"
+  name: app
+`
+	rootValuesYAML := `#@library/ref "@lib"
+#@data/values
+---
+db:
+  host: db.example.com
+`
+	filesToProcess := files.NewSortedFiles([]*files.File{
+		files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(rootSchemaYAML))),
+		files.MustNewFileFromSource(files.NewBytesSource("values.yml", []byte(rootValuesYAML))),
+		files.MustNewFileFromSource(files.NewBytesSource("_ytt_lib/lib/schema.yml", []byte(libSchemaYAML))),
+	})
+
+	t.Run("including schema the root library addresses to it", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.DataValuesFlags.InspectSchemaLibrary = "@lib"
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        db:
+          type: object
+          additionalProperties: false
+          properties:
+            host:
+              type: string
+              default: localhost
+            port:
+              type: integer
+              default: 5432
+            name:
+              type: string
+              description: Name of the database to connect to
+              default: app
+`
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("with the data values the root library addresses to it, given --data-values-schema-inspect-with-values", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.DataValuesFlags.InspectSchemaLibrary = "@lib"
+		opts.DataValuesFlags.InspectSchemaWithValues = true
+		opts.DataValuesFlags.KVsFromStrings = []string{"@lib:db.name=orders"}
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        db:
+          type: object
+          additionalProperties: false
+          properties:
+            host:
+              type: string
+              default: db.example.com
+            port:
+              type: integer
+              default: 5432
+            name:
+              type: string
+              description: Name of the database to connect to
+              default: orders
+`
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("fails when the library does not exist", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.DataValuesFlags.InspectSchemaLibrary = "@missing"
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		out := opts.RunWithFiles(cmdtpl.Input{Files: filesToProcess}, ui.NewTTY(false))
+		require.Error(t, out.Err)
+		require.Contains(t, out.Err.Error(), "Inspecting schema of library '@missing'")
+	})
+}
+
+func TestSchemaInspect_embeds_examples_from_files(t *testing.T) {
+	schemaYAML := `#@data/values-schema
+---
+#@schema/examples file=("a large cluster", "examples/large.yml")
+cluster:
+  nodes: 3
+  zones:
+  - ""
+#@schema/examples file=("typical TLS settings", "examples/tls.json")
+tls:
+  enabled: false
+  min_version: ""
+`
+	largeClusterYAML := `nodes: 12
+zones:
+- us-east-1a
+- us-east-1b
+`
+	tlsJSON := `{"enabled": true, "min_version": "1.2"}`
+
+	opts := cmdtpl.NewOptions()
+	opts.DataValuesFlags.InspectSchema = true
+	opts.FileMarksOpts.FileMarks = []string{"examples/*.yml:type=data"}
+	opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+	expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        cluster:
+          type: object
+          additionalProperties: false
+          x-example-description: a large cluster
+          example:
+            nodes: 12
+            zones:
+            - us-east-1a
+            - us-east-1b
+          properties:
+            nodes:
+              type: integer
+              default: 3
+            zones:
+              type: array
+              items:
+                type: string
+                default: ""
+              default: []
+        tls:
+          type: object
+          additionalProperties: false
+          x-example-description: typical TLS settings
+          example:
+            enabled: true
+            min_version: "1.2"
+          properties:
+            enabled:
+              type: boolean
+              default: false
+            min_version:
+              type: string
+              default: ""
+`
+	filesToProcess := files.NewSortedFiles([]*files.File{
+		files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		files.MustNewFileFromSource(files.NewBytesSource("examples/large.yml", []byte(largeClusterYAML))),
+		files.MustNewFileFromSource(files.NewBytesSource("examples/tls.json", []byte(tlsJSON))),
+	})
+
+	assertSucceedsDocSet(t, filesToProcess, expected, opts)
+}
+
+func TestSchemaInspect_annotation_adds_key(t *testing.T) {
+	t.Run("in the correct relative order", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+db_conn:
+  #@schema/title "Host Title"
+  #@schema/desc "The hostname"
+  #@schema/type any=True
+  #@schema/examples ("hostname example", 1.5)
+  #@schema/default "host"
+  #@schema/deprecated ""
+  hostname: ""
+  #@schema/title "Port Title" 
+  #@schema/desc "Port should be float between 0.152 through 16.35"  
+  #@schema/nullable
+  #@schema/examples ("", 1.5)
+  #@schema/default 9.9
+  #@schema/deprecated ""
+  port: 0.2
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        db_conn:
+          type: object
+          additionalProperties: false
+          properties:
+            hostname:
+              title: Host Title
+              nullable: true
+              deprecated: true
+              description: The hostname
+              x-example-description: hostname example
+              example: 1.5
+              default: host
+            port:
+              title: Port Title
+              type: number
+              format: float
+              nullable: true
+              deprecated: true
+              description: Port should be float between 0.152 through 16.35
+              x-example-description: ""
+              example: 1.5
+              default: 9.9
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("when description provided by @schema/desc", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+#@schema/desc "Network configuration values"
+---
+#@schema/desc "List of database connections"
+db_conn:
+#@schema/desc "A network entry"
+- 
+  #@schema/desc "The hostname"
+  hostname: ""
+  #@schema/desc "Port should be between 49152 through 65535"
+  port: 0
+  #@schema/desc "Timeout in minutes"
+  timeout: 1.0
+  #@schema/desc "Any type is allowed"
+  #@schema/type any=True
+  any_key: thing
+  #@schema/desc "When not provided, the default is null"
+  #@schema/nullable
+  null_key: ""
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      description: Network configuration values
+      properties:
+        db_conn:
+          type: array
+          description: List of database connections
+          items:
+            type: object
+            additionalProperties: false
+            description: A network entry
+            properties:
+              hostname:
+                type: string
+                description: The hostname
+                default: ""
+              port:
+                type: integer
+                description: Port should be between 49152 through 65535
+                default: 0
+              timeout:
+                type: number
+                format: float
+                description: Timeout in minutes
+                default: 1
+              any_key:
+                nullable: true
+                description: Any type is allowed
+                default: thing
+              null_key:
+                type: string
+                nullable: true
+                description: When not provided, the default is null
+                default: null
+          default: []
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("when title provided by @schema/title", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+#@schema/title "Network configuration values"
+---
+#@schema/title "List of database connections"
+db_conn:
+#@schema/title "A network entry"
+-
+  #@schema/title "The host"
+  hostname: ""
+  #@schema/title "The Port"
+  port: 0
+  #@schema/title "The Timeout"
+  timeout: 1.0
+  #@schema/title "Any type"
+  #@schema/type any=True
+  any_key: thing
+  #@schema/title "When not provided, the default is null"
+  #@schema/nullable
+  null_key: ""
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      title: Network configuration values
+      type: object
+      additionalProperties: false
+      properties:
+        db_conn:
+          title: List of database connections
+          type: array
+          items:
+            title: A network entry
+            type: object
+            additionalProperties: false
+            properties:
+              hostname:
+                title: The host
+                type: string
+                default: ""
+              port:
+                title: The Port
+                type: integer
+                default: 0
+              timeout:
+                title: The Timeout
+                type: number
+                format: float
+                default: 1
+              any_key:
+                title: Any type
+                nullable: true
+                default: thing
+              null_key:
+                title: When not provided, the default is null
+                type: string
+                nullable: true
+                default: null
+          default: []
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("when title and description are provided on both an array and its item", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/title "Allowed hosts"
+#@schema/desc "Hosts permitted to connect"
+hosts:
+#@schema/title "Host"
+#@schema/desc "A fully-qualified domain name"
+- ""
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        hosts:
+          title: Allowed hosts
+          type: array
+          description: Hosts permitted to connect
+          items:
+            title: Host
+            type: string
+            description: A fully-qualified domain name
+            default: ""
+          default: []
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("when title and description are provided on items that are nullable, of any type, or arrays themselves", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+aliases:
+#@schema/title "Alias"
+#@schema/desc "Another name, if any"
+#@schema/nullable
+- ""
+labels:
+#@schema/title "Label"
+#@schema/type any=True
+- ""
+matrix:
+#@schema/title "Row"
+-
+  #@schema/title "Cell"
+  #@schema/desc "A single value"
+  - 0
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        aliases:
+          type: array
+          items:
+            title: Alias
+            type: string
+            nullable: true
+            description: Another name, if any
+            default: null
+          default: []
+        labels:
+          type: array
+          items:
+            title: Label
+            nullable: true
+            default: ""
+          default: []
+        matrix:
+          type: array
+          items:
+            title: Row
+            type: array
+            items:
+              title: Cell
+              type: integer
+              description: A single value
+              default: 0
+            default: []
+          default: []
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("when examples are provided by @schema/examples", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+#@schema/examples ("schema example description", {"db_conn": [{"hostname": "localhost", "port": 8080, "timeout": 4.2, "any_key": "anything", "null_key": None}]})
+---
+#@schema/examples ("db_conn example description", [{"hostname": "localhost", "port": 8080, "timeout": 4.2, "any_key": "anything", "null_key": None}])
+db_conn:
+#@schema/examples ("db_conn array example description", {"hostname": "localhost", "port": 8080, "timeout": 4.2, "any_key": "anything", "null_key": "not null"})
+- 
+  #@schema/examples ("hostname example description", "localhost")
+  #@schema/desc "The hostname"
+  hostname: ""
+  #@schema/examples ("",8080)
+  port: 0
+  #@schema/examples ("timeout example description", 4.2), ("another timeout ex desc", 5)
+  timeout: 1.0
+  #@schema/examples ("any_key example description", "anything")
+  #@schema/type any=True
+  any_key: thing
+  #@schema/examples ("null_key example description", None)
   #@schema/nullable
-  int_key: 0
+  null_key: ""
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      x-example-description: schema example description
+      example:
+        db_conn:
+        - hostname: localhost
+          port: 8080
+          timeout: 4.2
+          any_key: anything
+          null_key: null
+      properties:
+        db_conn:
+          type: array
+          x-example-description: db_conn example description
+          example:
+          - hostname: localhost
+            port: 8080
+            timeout: 4.2
+            any_key: anything
+            null_key: null
+          items:
+            type: object
+            additionalProperties: false
+            x-example-description: db_conn array example description
+            example:
+              hostname: localhost
+              port: 8080
+              timeout: 4.2
+              any_key: anything
+              null_key: not null
+            properties:
+              hostname:
+                type: string
+                description: The hostname
+                x-example-description: hostname example description
+                example: localhost
+                default: ""
+              port:
+                type: integer
+                x-example-description: ""
+                example: 8080
+                default: 0
+              timeout:
+                type: number
+                format: float
+                x-example-description: timeout example description
+                example: 4.2
+                default: 1
+              any_key:
+                nullable: true
+                x-example-description: any_key example description
+                example: anything
+                default: thing
+              null_key:
+                type: string
+                nullable: true
+                x-example-description: null_key example description
+                example: null
+                default: null
+          default: []
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
 
-  #@schema/default True
-  #@schema/nullable
-  bool_key: false
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("when examples of arrays are provided by @schema/examples, as lists (not wrapped)", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
 
-  #@schema/nullable
-  #@schema/default False
-  false_key: true
+		schemaYAML := `#@data/values-schema
+---
+#@schema/examples ("two hosts", ["a.example.com", "b.example.com"])
+hosts:
+- ""
+#@schema/examples ("a matrix", [[1, 2], [3]])
+matrix:
+- - 0
+#@schema/nullable
+#@schema/examples ("one port", [8080])
+ports:
+- 0
+#@schema/examples ("none", [])
+tags:
+- ""
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        hosts:
+          type: array
+          x-example-description: two hosts
+          example:
+          - a.example.com
+          - b.example.com
+          items:
+            type: string
+            default: ""
+          default: []
+        matrix:
+          type: array
+          x-example-description: a matrix
+          example:
+          - - 1
+            - 2
+          - - 3
+          items:
+            type: array
+            items:
+              type: integer
+              default: 0
+            default: []
+          default: []
+        ports:
+          type: array
+          nullable: true
+          x-example-description: one port
+          example:
+          - 8080
+          items:
+            type: integer
+            default: 0
+          default: null
+        tags:
+          type: array
+          x-example-description: none
+          example: []
+          items:
+            type: string
+            default: ""
+          default: []
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("when deprecated property is provided by @schema/deprecated", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
 
+		schemaYAML := `#@data/values-schema
+---
+#@schema/deprecated ""
+db_conn:
+#@schema/deprecated ""
+-
+  #@schema/deprecated ""
+  hostname: ""
+  #@schema/deprecated ""
+  port: 0
+  #@schema/deprecated ""
+  timeout: 1.0
+  #@schema/deprecated ""
+  #@schema/type any=True
+  any_key: thing
+  #@schema/deprecated ""
   #@schema/nullable
-  #@schema/default "some text"
-  string_key: ""
+  null_key: ""
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        db_conn:
+          type: array
+          deprecated: true
+          items:
+            type: object
+            additionalProperties: false
+            deprecated: true
+            properties:
+              hostname:
+                type: string
+                deprecated: true
+                default: ""
+              port:
+                type: integer
+                deprecated: true
+                default: 0
+              timeout:
+                type: number
+                format: float
+                deprecated: true
+                default: 1
+              any_key:
+                nullable: true
+                deprecated: true
+                default: thing
+              null_key:
+                type: string
+                nullable: true
+                deprecated: true
+                default: null
+          default: []
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("when the entire schema document is deprecated by @schema/deprecated", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+#@schema/deprecated "superseded by the v2 schema"
+---
+foo:
+- bar
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      deprecated: true
+      properties:
+        foo:
+          type: array
+          items:
+            type: string
+            default: bar
+          default: []
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("when conditional requirement is provided by @schema/required-if", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
 
+		schemaYAML := `#@data/values-schema
+---
+#@schema/required-if "enabled", then=["cert", "key"], otherwise=["issuer"]
+tls:
+  enabled: false
   #@schema/nullable
-  #@schema/default 9.1
-  float_key: 0.0
-
+  cert: ""
   #@schema/nullable
-  #@schema/default [1,2,3]
-  array_of_scalars:
-  - 0
-
-  #@schema/default [{"bar": "thing 1"},{"bar": "thing 2"}, {"bar": "thing 3"}]
+  key: ""
   #@schema/nullable
-  array_of_maps:
-  -
-    #@schema/nullable
-    bar: ""
-    #@schema/nullable
-    ree: ""
+  issuer: ""
 `
 		expected := `openapi: 3.0.0
 info:
@@ -476,96 +4004,70 @@ components:
       type: object
       additionalProperties: false
       properties:
-        foo:
+        tls:
           type: object
           additionalProperties: false
           properties:
-            int_key:
-              type: integer
-              nullable: true
-              default: 10
-            bool_key:
-              type: boolean
-              nullable: true
-              default: true
-            false_key:
+            enabled:
               type: boolean
-              nullable: true
               default: false
-            string_key:
+            cert:
               type: string
               nullable: true
-              default: some text
-            float_key:
-              type: number
-              format: float
-              nullable: true
-              default: 9.1
-            array_of_scalars:
-              type: array
+              default: null
+            key:
+              type: string
               nullable: true
-              items:
-                type: integer
-                default: 0
-              default:
-              - 1
-              - 2
-              - 3
-            array_of_maps:
-              type: array
+              default: null
+            issuer:
+              type: string
               nullable: true
-              items:
-                type: object
-                additionalProperties: false
-                properties:
-                  bar:
-                    type: string
-                    nullable: true
-                    default: null
-                  ree:
-                    type: string
-                    nullable: true
-                    default: null
-              default:
-              - bar: thing 1
-                ree: null
-              - bar: thing 2
-                ree: null
-              - bar: thing 3
-                ree: null
+              default: null
+          if:
+            properties:
+              enabled:
+                enum:
+                - true
+          then:
+            properties:
+              cert:
+                not:
+                  enum:
+                  - null
+              key:
+                not:
+                  enum:
+                  - null
+            required:
+            - cert
+            - key
+          else:
+            properties:
+              issuer:
+                not:
+                  enum:
+                  - null
+            required:
+            - issuer
 `
-
 		filesToProcess := files.NewSortedFiles([]*files.File{
 			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
 		})
 
 		assertSucceedsDocSet(t, filesToProcess, expected, opts)
 	})
-
-}
-func TestSchemaInspect_annotation_adds_key(t *testing.T) {
-	t.Run("in the correct relative order", func(t *testing.T) {
+	t.Run("when the number of items in a map is limited by @schema/min-properties and @schema/max-properties", func(t *testing.T) {
 		opts := cmdtpl.NewOptions()
 		opts.DataValuesFlags.InspectSchema = true
 		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
 
 		schemaYAML := `#@data/values-schema
 ---
-db_conn:
-  #@schema/title "Host Title"
-  #@schema/desc "The hostname"
-  #@schema/type any=True
-  #@schema/examples ("hostname example", 1.5)
-  #@schema/default "host"
-  #@schema/deprecated ""
-  hostname: ""
-  #@schema/title "Port Title" 
-  #@schema/desc "Port should be float between 0.152 through 16.35"  
-  #@schema/nullable
-  #@schema/examples ("", 1.5)
-  #@schema/default 9.9
-  #@schema/deprecated ""
-  port: 0.2
+#@schema/min-properties 1
+#@schema/max-properties 2
+labels:
+  app: ""
+  tier: ""
 `
 		expected := `openapi: 3.0.0
 info:
@@ -578,60 +4080,38 @@ components:
       type: object
       additionalProperties: false
       properties:
-        db_conn:
+        labels:
           type: object
           additionalProperties: false
           properties:
-            hostname:
-              title: Host Title
-              nullable: true
-              deprecated: true
-              description: The hostname
-              x-example-description: hostname example
-              example: 1.5
-              default: host
-            port:
-              title: Port Title
-              type: number
-              format: float
-              nullable: true
-              deprecated: true
-              description: Port should be float between 0.152 through 16.35
-              x-example-description: ""
-              example: 1.5
-              default: 9.9
+            app:
+              type: string
+              default: ""
+            tier:
+              type: string
+              default: ""
+          minProperties: 1
+          maxProperties: 2
 `
-
 		filesToProcess := files.NewSortedFiles([]*files.File{
 			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
 		})
 
 		assertSucceedsDocSet(t, filesToProcess, expected, opts)
 	})
-	t.Run("when description provided by @schema/desc", func(t *testing.T) {
+	t.Run("when allowed values are provided by @schema/validation one_of=, including numbers", func(t *testing.T) {
 		opts := cmdtpl.NewOptions()
 		opts.DataValuesFlags.InspectSchema = true
 		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
 
 		schemaYAML := `#@data/values-schema
-#@schema/desc "Network configuration values"
 ---
-#@schema/desc "List of database connections"
-db_conn:
-#@schema/desc "A network entry"
-- 
-  #@schema/desc "The hostname"
-  hostname: ""
-  #@schema/desc "Port should be between 49152 through 65535"
-  port: 0
-  #@schema/desc "Timeout in minutes"
-  timeout: 1.0
-  #@schema/desc "Any type is allowed"
-  #@schema/type any=True
-  any_key: thing
-  #@schema/desc "When not provided, the default is null"
-  #@schema/nullable
-  null_key: ""
+#@schema/validation one_of=["debug", "info"]
+level: info
+#@schema/validation one_of=[1, 3, 5]
+replicas: 1
+#@schema/validation one_of=[0.5, 1, 1.5]
+ratio: 1.0
 `
 		expected := `openapi: 3.0.0
 info:
@@ -643,71 +4123,48 @@ components:
     dataValues:
       type: object
       additionalProperties: false
-      description: Network configuration values
       properties:
-        db_conn:
-          type: array
-          description: List of database connections
-          items:
-            type: object
-            additionalProperties: false
-            description: A network entry
-            properties:
-              hostname:
-                type: string
-                description: The hostname
-                default: ""
-              port:
-                type: integer
-                description: Port should be between 49152 through 65535
-                default: 0
-              timeout:
-                type: number
-                format: float
-                description: Timeout in minutes
-                default: 1
-              any_key:
-                nullable: true
-                description: Any type is allowed
-                default: thing
-              null_key:
-                type: string
-                nullable: true
-                description: When not provided, the default is null
-                default: null
-          default: []
+        level:
+          type: string
+          default: info
+          enum:
+          - debug
+          - info
+        replicas:
+          type: integer
+          default: 1
+          enum:
+          - 1
+          - 3
+          - 5
+        ratio:
+          type: number
+          format: float
+          default: 1
+          enum:
+          - 0.5
+          - 1
+          - 1.5
 `
-
 		filesToProcess := files.NewSortedFiles([]*files.File{
 			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
 		})
 
 		assertSucceedsDocSet(t, filesToProcess, expected, opts)
 	})
-	t.Run("when title provided by @schema/title", func(t *testing.T) {
+	t.Run("when disallowed values are provided by @schema/validation not_one_of=", func(t *testing.T) {
 		opts := cmdtpl.NewOptions()
 		opts.DataValuesFlags.InspectSchema = true
 		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
 
 		schemaYAML := `#@data/values-schema
-#@schema/title "Network configuration values"
 ---
-#@schema/title "List of database connections"
-db_conn:
-#@schema/title "A network entry"
--
-  #@schema/title "The host"
-  hostname: ""
-  #@schema/title "The Port"
-  port: 0
-  #@schema/title "The Timeout"
-  timeout: 1.0
-  #@schema/title "Any type"
-  #@schema/type any=True
-  any_key: thing
-  #@schema/title "When not provided, the default is null"
-  #@schema/nullable
-  null_key: ""
+#@schema/validation not_one_of=["admin", "root"]
+username: alice
+#@schema/validation not_one_of=[0, 22]
+ports:
+- #@schema/validation not_one_of=[0]
+  8080
 `
 		expected := `openapi: 3.0.0
 info:
@@ -717,41 +4174,29 @@ paths: {}
 components:
   schemas:
     dataValues:
-      title: Network configuration values
       type: object
       additionalProperties: false
       properties:
-        db_conn:
-          title: List of database connections
+        username:
+          type: string
+          default: alice
+          not:
+            enum:
+            - admin
+            - root
+        ports:
           type: array
           items:
-            title: A network entry
-            type: object
-            additionalProperties: false
-            properties:
-              hostname:
-                title: The host
-                type: string
-                default: ""
-              port:
-                title: The Port
-                type: integer
-                default: 0
-              timeout:
-                title: The Timeout
-                type: number
-                format: float
-                default: 1
-              any_key:
-                title: Any type
-                nullable: true
-                default: thing
-              null_key:
-                title: When not provided, the default is null
-                type: string
-                nullable: true
-                default: null
+            type: integer
+            default: 8080
+            not:
+              enum:
+              - 0
           default: []
+          not:
+            enum:
+            - 0
+            - 22
 `
 		filesToProcess := files.NewSortedFiles([]*files.File{
 			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
@@ -759,31 +4204,76 @@ components:
 
 		assertSucceedsDocSet(t, filesToProcess, expected, opts)
 	})
-	t.Run("when examples are provided by @schema/examples", func(t *testing.T) {
+	t.Run("when keys are required by @schema/validation required_keys=", func(t *testing.T) {
 		opts := cmdtpl.NewOptions()
 		opts.DataValuesFlags.InspectSchema = true
 		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
 
 		schemaYAML := `#@data/values-schema
-#@schema/examples ("schema example description", {"db_conn": [{"hostname": "localhost", "port": 8080, "timeout": 4.2, "any_key": "anything", "null_key": None}]})
 ---
-#@schema/examples ("db_conn example description", [{"hostname": "localhost", "port": 8080, "timeout": 4.2, "any_key": "anything", "null_key": None}])
-db_conn:
-#@schema/examples ("db_conn array example description", {"hostname": "localhost", "port": 8080, "timeout": 4.2, "any_key": "anything", "null_key": "not null"})
-- 
-  #@schema/examples ("hostname example description", "localhost")
-  #@schema/desc "The hostname"
-  hostname: ""
-  #@schema/examples ("",8080)
-  port: 0
-  #@schema/examples ("timeout example description", 4.2), ("another timeout ex desc", 5)
-  timeout: 1.0
-  #@schema/examples ("any_key example description", "anything")
-  #@schema/type any=True
-  any_key: thing
-  #@schema/examples ("null_key example description", None)
+#@schema/validation required_keys=["user", "password"]
+db:
+  host: localhost
+  user: admin
   #@schema/nullable
-  null_key: ""
+  password: ""
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        db:
+          type: object
+          additionalProperties: false
+          properties:
+            host:
+              type: string
+              default: localhost
+            user:
+              type: string
+              default: admin
+            password:
+              type: string
+              nullable: true
+              default: null
+          required:
+          - user
+          - password
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("when alternatives are composed by @schema/one-of, @schema/any-of, or @schema/all-of", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@ def circle():
+radius: 0
+#@ end
+
+#@ def square():
+#@schema/desc "length of each side"
+side: 0
+#@ end
+
+#@data/values-schema
+---
+#@schema/one-of circle=circle(), square=square()
+shape:
+  radius: 1
+#@schema/any-of name="", id=0
+owner: ""
 `
 		expected := `openapi: 3.0.0
 info:
@@ -795,64 +4285,35 @@ components:
     dataValues:
       type: object
       additionalProperties: false
-      x-example-description: schema example description
-      example:
-        db_conn:
-        - hostname: localhost
-          port: 8080
-          timeout: 4.2
-          any_key: anything
-          null_key: null
       properties:
-        db_conn:
-          type: array
-          x-example-description: db_conn example description
-          example:
-          - hostname: localhost
-            port: 8080
-            timeout: 4.2
-            any_key: anything
-            null_key: null
-          items:
+        shape:
+          default:
+            radius: 1
+          oneOf:
+          - title: circle
             type: object
             additionalProperties: false
-            x-example-description: db_conn array example description
-            example:
-              hostname: localhost
-              port: 8080
-              timeout: 4.2
-              any_key: anything
-              null_key: not null
             properties:
-              hostname:
-                type: string
-                description: The hostname
-                x-example-description: hostname example description
-                example: localhost
-                default: ""
-              port:
+              radius:
                 type: integer
-                x-example-description: ""
-                example: 8080
                 default: 0
-              timeout:
-                type: number
-                format: float
-                x-example-description: timeout example description
-                example: 4.2
-                default: 1
-              any_key:
-                nullable: true
-                x-example-description: any_key example description
-                example: anything
-                default: thing
-              null_key:
-                type: string
-                nullable: true
-                x-example-description: null_key example description
-                example: null
-                default: null
-          default: []
+          - title: square
+            type: object
+            additionalProperties: false
+            properties:
+              side:
+                type: integer
+                description: length of each side
+                default: 0
+        owner:
+          default: ""
+          anyOf:
+          - title: name
+            type: string
+            default: ""
+          - title: id
+            type: integer
+            default: 0
 `
 		filesToProcess := files.NewSortedFiles([]*files.File{
 			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
@@ -860,29 +4321,23 @@ components:
 
 		assertSucceedsDocSet(t, filesToProcess, expected, opts)
 	})
-	t.Run("when deprecated property is provided by @schema/deprecated", func(t *testing.T) {
+	t.Run("when the alternatives of a composition are discriminated by @schema/discriminator", func(t *testing.T) {
 		opts := cmdtpl.NewOptions()
 		opts.DataValuesFlags.InspectSchema = true
 		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
 
 		schemaYAML := `#@data/values-schema
 ---
-#@schema/deprecated ""
-db_conn:
-#@schema/deprecated ""
--
-  #@schema/deprecated ""
-  hostname: ""
-  #@schema/deprecated ""
-  port: 0
-  #@schema/deprecated ""
-  timeout: 1.0
-  #@schema/deprecated ""
-  #@schema/type any=True
-  any_key: thing
-  #@schema/deprecated ""
-  #@schema/nullable
-  null_key: ""
+#@schema/one-of circle={"kind": "circle", "radius": 0}, square={"kind": "square", "side": 0}
+#@schema/discriminator "kind", mapping={"round": "circle", "square": "square"}
+shape:
+  kind: circle
+  radius: 1
+#@schema/any-of name={"by": "name", "name": ""}, id={"by": "id", "id": 0}
+#@schema/discriminator "by"
+owner:
+  by: name
+  name: ""
 `
 		expected := `openapi: 3.0.0
 info:
@@ -895,37 +4350,63 @@ components:
       type: object
       additionalProperties: false
       properties:
-        db_conn:
-          type: array
-          deprecated: true
-          items:
+        shape:
+          default:
+            kind: circle
+            radius: 1
+          oneOf:
+          - title: circle
             type: object
             additionalProperties: false
-            deprecated: true
             properties:
-              hostname:
+              kind:
                 type: string
-                deprecated: true
-                default: ""
-              port:
+                default: circle
+              radius:
                 type: integer
-                deprecated: true
                 default: 0
-              timeout:
-                type: number
-                format: float
-                deprecated: true
-                default: 1
-              any_key:
-                nullable: true
-                deprecated: true
-                default: thing
-              null_key:
+          - title: square
+            type: object
+            additionalProperties: false
+            properties:
+              kind:
                 type: string
-                nullable: true
-                deprecated: true
-                default: null
-          default: []
+                default: square
+              side:
+                type: integer
+                default: 0
+          discriminator:
+            propertyName: kind
+            mapping:
+              round: circle
+              square: square
+        owner:
+          default:
+            by: name
+            name: ""
+          anyOf:
+          - title: name
+            type: object
+            additionalProperties: false
+            properties:
+              by:
+                type: string
+                default: name
+              name:
+                type: string
+                default: ""
+          - title: id
+            type: object
+            additionalProperties: false
+            properties:
+              by:
+                type: string
+                default: id
+              id:
+                type: integer
+                default: 0
+          discriminator:
+            propertyName: by
 `
 		filesToProcess := files.NewSortedFiles([]*files.File{
 			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
@@ -944,7 +4425,7 @@ func TestSchemaInspect_errors(t *testing.T) {
 ---
 foo: doesn't matter
 `
-		expectedErr := "Data values schema export only supported in OpenAPI v3 format; specify format with --output=openapi-v3 flag"
+		expectedErr := "Data values schema export only supported in OpenAPI v3, Markdown or TypeScript format; specify format with --output=openapi-v3 (or --output=markdown, or --output=typescript) flag"
 
 		filesToProcess := files.NewSortedFiles([]*files.File{
 			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
@@ -970,6 +4451,200 @@ foo: doesn't matter
 
 		assertFails(t, filesToProcess, expectedErr, opts)
 	})
+
+	t.Run("when a validation gives both an inclusive and an exclusive bound on the same side", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation min=1, exclusive_min=0
+replicas: 3
+`
+		expectedErr := `keyword argument "exclusive_min" gives a lower bound, as does min=; give only one of them (at schema.yml:3)`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+
+	t.Run("when an example file is not valid YAML (or JSON)", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/examples file=("typical TLS settings", "tls.json")
+tls:
+  enabled: false
+`
+		expectedErr := `Templating file 'schema.yml': @schema/examples (at schema.yml:3): expected example file 'tls.json' to be YAML (or JSON):`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("tls.json", []byte(`{"enabled": true`))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when an example file does not exist", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/examples file=("typical TLS settings", "tls.json")
+tls:
+  enabled: false
+`
+		expectedErr := `@schema/examples (at schema.yml:3): Expected to find file 'tls.json' (hint: only files included via -f flag are available)`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when an example file does not match the type of the value", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/examples file=("typical TLS settings", "tls.json")
+tls:
+  enabled: false
+`
+		expectedErr := `Invalid schema - @schema/examples has wrong type`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("tls.json", []byte(`{"enabled": "yes"}`))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when --openapi-schema-name is not a valid component name", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.DataValuesFlags.InspectSchemaName = "my config"
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+foo: doesn't matter
+`
+		expectedErr := `Expected schema name to consist of letters, digits, '.', '-' and '_' (as OpenAPI requires of component names), but was "my config"`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when one_of= allows a value that the length rules reject", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation one_of=["s", "medium", "extra-large"], min_len=2, max_len=6
+size: medium
+`
+		expectedErr := `Invalid schema - one_of= allows values that the length rules reject
+===================================================================
+
+one_of= value "s"
+schema.yml:
+    |
+  3 | #@schema/validation one_of=["s", "medium", "extra-large"], min_len=2, max_len=6
+  4 | size: medium
+    |
+
+    = found: length 1
+    = expected: length between 2 and 6 (by schema.yml:3)
+
+one_of= value "extra-large"
+schema.yml:
+    |
+  3 | #@schema/validation one_of=["s", "medium", "extra-large"], min_len=2, max_len=6
+  4 | size: medium
+    |
+
+    = found: length 11
+    = expected: length between 2 and 6 (by schema.yml:3)
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when a JSON Pointer refers to nothing, reporting its closest part that does exist", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.DataValuesFlags.InspectSchemaPointer = "/properties/db_conn/properties/prot"
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+db_conn:
+  host: ""
+  port: 5432
+`
+		expectedErr := "Expected JSON Pointer '/properties/db_conn/properties/prot' to refer to a part of the schema, but '/properties/db_conn/properties' has nothing at 'prot' (it has: host, port)"
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+
+	t.Run("when validating examples and an example is missing a value required to be not null", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.DataValuesFlags.InspectSchemaCheckExamples = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+#@schema/examples ("minimal", {"db": {"host": "db.example.com"}})
+---
+db:
+  host: ""
+  #@schema/nullable
+  #@schema/validation not_null=True
+  user: ""
+`
+		expectedErr := `Invalid schema - example is missing required value(s)
+=====================================================
+
+example "minimal"
+schema.yml:
+    |
+  2 | #@schema/examples ("minimal", {"db": {"host": "db.example.com"}})
+  3 | ---
+    |
+
+    = found: null value
+    = expected: a non-null value for "db.user"
+    = hint: the value is validated with not_null=True in schema.
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
 }
 
 func assertSucceedsDocSet(t *testing.T, filesToProcess []*files.File, expectedOut string, opts *cmdtpl.Options) {
@@ -0,0 +1,101 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package template
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/vmware-tanzu/carvel-ytt/pkg/validations"
+)
+
+// validationReport collects the outcomes of validating data values, to be written as a JUnit XML report (so that
+// CI systems can display them as test results): each validation checked is a test case.
+type validationReport struct {
+	outcomes []validations.Outcome
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Details string `xml:",cdata"`
+}
+
+// add records the outcomes in "chk".
+func (r *validationReport) add(chk validations.Check) {
+	r.outcomes = append(r.outcomes, chk.Outcomes...)
+}
+
+// AsJUnitXML renders this report as a JUnit XML document.
+func (r *validationReport) AsJUnitXML() ([]byte, error) {
+	suite := junitTestSuite{Name: "data values validations"}
+	for _, outcome := range r.outcomes {
+		testCase := junitTestCase{
+			Name:      fmt.Sprintf("%s (by %s)", outcome.Path, outcome.ValidationSource.AsCompactString()),
+			ClassName: "data values",
+		}
+		switch {
+		case outcome.Skipped:
+			testCase.Skipped = &struct{}{}
+			suite.Skipped++
+		case len(outcome.Violations) > 0:
+			var messages []string
+			for _, violation := range outcome.Violations {
+				messages = append(messages, fmt.Sprintf("must be: %s (by: %s)", violation.Description, violation.RuleSource.AsCompactString()))
+			}
+			invalidation := validations.Invalidation{Path: outcome.Path, ValueSource: outcome.ValueSource, Violations: outcome.Violations}
+			testCase.Failure = &junitFailure{
+				Message: strings.Join(messages, "; "),
+				Details: validations.Check{Invalidations: []validations.Invalidation{invalidation}}.ResultsAsString(),
+			}
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	suite.Tests = len(suite.Cases)
+
+	report := junitTestSuites{Tests: suite.Tests, Failures: suite.Failures, Skipped: suite.Skipped, Suites: []junitTestSuite{suite}}
+	reportXML, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(reportXML, '\n')...), nil
+}
+
+// WriteToFile writes this report (as JUnit XML) to the file at "path".
+func (r *validationReport) WriteToFile(path string) error {
+	reportXML, err := r.AsJUnitXML()
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(path, reportXML, 0600)
+	if err != nil {
+		return fmt.Errorf("Writing validation report: %s", err)
+	}
+	return nil
+}
@@ -4,12 +4,34 @@
 package cmd
 
 import (
+	"errors"
+
 	"github.com/cppforlife/cobrautil"
 	"github.com/spf13/cobra"
 	cmdtpl "github.com/vmware-tanzu/carvel-ytt/pkg/cmd/template"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/validations"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/version"
 )
 
+// Exit codes of ytt, when it fails.
+const (
+	// ExitCodeError is the exit code of any failure that has no more specific one (e.g. a template error).
+	ExitCodeError = 1
+	// ExitCodeInvalidDataValues is the exit code when data values (including those of a private library) fail their
+	// validations (i.e. those declared via @schema/validation or @assert/validate), their formats (given
+	// --validate-formats), or do not conform to the JSON Schema given via --data-values-json-schema.
+	ExitCodeInvalidDataValues = 3
+)
+
+// ExitCode provides the exit code that reports "err".
+func ExitCode(err error) int {
+	var chkErr validations.CheckError
+	if errors.As(err, &chkErr) {
+		return ExitCodeInvalidDataValues
+	}
+	return ExitCodeError
+}
+
 type YttOptions struct{}
 
 func NewDefaultYttOptions() *YttOptions {
@@ -30,7 +52,9 @@ func NewYttCmd(o *YttOptions) *cobra.Command {
 	cmd.Long = `ytt performs YAML templating.
 
 Docs: https://carvel.dev/ytt/docs/latest/
-Docs for data values: https://carvel.dev/ytt/docs/latest/ytt-data-values/`
+Docs for data values: https://carvel.dev/ytt/docs/latest/ytt-data-values/
+
+Exit codes: 1 on error; 3 when data values fail their validations.`
 
 	// Affects children as well
 	cmd.SilenceErrors = true
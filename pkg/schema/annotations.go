@@ -13,19 +13,35 @@ import (
 	"github.com/vmware-tanzu/carvel-ytt/pkg/template/core"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/validations"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/yamlmeta"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/yttlibrary"
 )
 
 // Declare @schema/... annotation names
 const (
-	AnnotationNullable     template.AnnotationName = "schema/nullable"
-	AnnotationType         template.AnnotationName = "schema/type"
-	AnnotationDefault      template.AnnotationName = "schema/default"
-	AnnotationDescription  template.AnnotationName = "schema/desc"
-	AnnotationTitle        template.AnnotationName = "schema/title"
-	AnnotationExamples     template.AnnotationName = "schema/examples"
-	AnnotationDeprecated   template.AnnotationName = "schema/deprecated"
-	TypeAnnotationKwargAny string                  = "any"
-	AnnotationValidation   template.AnnotationName = "schema/validation"
+	AnnotationNullable      template.AnnotationName = "schema/nullable"
+	AnnotationType          template.AnnotationName = "schema/type"
+	AnnotationDefault       template.AnnotationName = "schema/default"
+	AnnotationDescription   template.AnnotationName = "schema/desc"
+	AnnotationTitle         template.AnnotationName = "schema/title"
+	AnnotationExamples      template.AnnotationName = "schema/examples"
+	AnnotationDeprecated    template.AnnotationName = "schema/deprecated"
+	TypeAnnotationKwargAny  string                  = "any"
+	TypeAnnotationString    string                  = "string"
+	AnnotationValidation    template.AnnotationName = "schema/validation"
+	AnnotationRequiredIf    template.AnnotationName = "schema/required-if"
+	AnnotationMinProperties template.AnnotationName = "schema/min-properties"
+	AnnotationMaxProperties template.AnnotationName = "schema/max-properties"
+	AnnotationOneOf         template.AnnotationName = "schema/one-of"
+	AnnotationAnyOf         template.AnnotationName = "schema/any-of"
+	AnnotationAllOf         template.AnnotationName = "schema/all-of"
+	AnnotationFormat        template.AnnotationName = "schema/format"
+	AnnotationDiscriminator template.AnnotationName = "schema/discriminator"
+	AnnotationUnit          template.AnnotationName = "schema/unit"
+
+	ExamplesAnnotationKwargFile        string = "file"
+	RequiredIfAnnotationKwargEquals    string = "equals"
+	RequiredIfAnnotationKwargThen      string = "then"
+	RequiredIfAnnotationKwargOtherwise string = "otherwise"
 )
 
 type Annotation interface {
@@ -34,9 +50,11 @@ type Annotation interface {
 }
 
 type TypeAnnotation struct {
-	any  bool
-	node yamlmeta.Node
-	pos  *filepos.Position
+	any    bool
+	notAny bool   // whether any=False was given explicitly
+	name   string // the type given (positionally) by name, if any (i.e. TypeAnnotationString)
+	node   yamlmeta.Node
+	pos    *filepos.Position
 }
 
 type NullableAnnotation struct {
@@ -80,10 +98,35 @@ type ValidationAnnotation struct {
 	pos        *filepos.Position
 }
 
+// RequiredIfAnnotation is a wrapper for a conditional requirement provided via @schema/required-if annotation
+type RequiredIfAnnotation struct {
+	key       string
+	equals    starlark.Value
+	then      []string
+	otherwise []string
+	pos       *filepos.Position
+}
+
+// CompositionAnnotation is a wrapper for the named alternatives provided via @schema/one-of, @schema/any-of, or
+// @schema/all-of annotation
+type CompositionAnnotation struct {
+	composition  Composition
+	alternatives []*NamedType
+	node         yamlmeta.Node
+	pos          *filepos.Position
+}
+
+var compositionsByAnnotation = map[template.AnnotationName]Composition{
+	AnnotationOneOf: CompositionOneOf,
+	AnnotationAnyOf: CompositionAnyOf,
+	AnnotationAllOf: CompositionAllOf,
+}
+
 // Example contains a yaml example and its description
 type Example struct {
 	description string
 	example     interface{}
+	position    *filepos.Position // of the annotation that declared this example
 }
 
 // documentation holds metadata about a Type, provided via documentation annotations
@@ -97,17 +140,34 @@ type documentation struct {
 
 // NewTypeAnnotation checks the keyword argument provided via @schema/type annotation, and returns wrapper for the annotated node.
 func NewTypeAnnotation(ann template.NodeAnnotation, node yamlmeta.Node) (*TypeAnnotation, error) {
-	if len(ann.Kwargs) == 0 {
+	namesType := len(ann.Args) > 0 && ann.Args[0].Type() == "string"
+	if len(ann.Kwargs) == 0 && !namesType {
 		return nil, schemaAssertionError{
 			annPositions: []*filepos.Position{ann.Position},
 			position:     node.GetPosition(),
 			description:  fmt.Sprintf("expected @%v annotation to have keyword argument and value", AnnotationType),
 			expected:     "valid keyword argument and value",
 			found:        fmt.Sprintf("missing keyword argument and value (by %s)", ann.Position.AsCompactString()),
-			hints:        []string{fmt.Sprintf("Supported key-value pairs are '%v=True', '%v=False'", TypeAnnotationKwargAny, TypeAnnotationKwargAny)},
+			hints: []string{fmt.Sprintf("Supported key-value pairs are '%v=True', '%v=False'", TypeAnnotationKwargAny, TypeAnnotationKwargAny),
+				fmt.Sprintf("Supported type names are '%v'", TypeAnnotationString)},
 		}
 	}
 	typeAnn := &TypeAnnotation{node: node, pos: ann.Position}
+	if namesType {
+		name := string(ann.Args[0].(starlark.String))
+		if len(ann.Args) > 1 || len(ann.Kwargs) > 0 || name != TypeAnnotationString {
+			return nil, schemaAssertionError{
+				annPositions: []*filepos.Position{ann.Position},
+				position:     node.GetPosition(),
+				description:  fmt.Sprintf("unknown type given to @%v annotation", AnnotationType),
+				expected:     "the name of a type, alone",
+				found:        fmt.Sprintf("%s (by %s)", ann.Args[0].String(), ann.Position.AsCompactString()),
+				hints:        []string{fmt.Sprintf("Supported type names are '%v'", TypeAnnotationString)},
+			}
+		}
+		typeAnn.name = name
+		return typeAnn, nil
+	}
 	for _, kwarg := range ann.Kwargs {
 		argName, err := core.NewStarlarkValue(kwarg[0]).AsString()
 		if err != nil {
@@ -128,6 +188,7 @@ func NewTypeAnnotation(ann template.NodeAnnotation, node yamlmeta.Node) (*TypeAn
 				}
 			}
 			typeAnn.any = isAnyType
+			typeAnn.notAny = !isAnyType
 
 		default:
 			return nil, schemaAssertionError{
@@ -405,7 +466,7 @@ func NewExampleAnnotation(ann template.NodeAnnotation, pos *filepos.Position) (*
 			if err != nil {
 				panic(err)
 			}
-			examples = append(examples, Example{description, yamlmeta.NewASTFromInterfaceWithPosition(exampleVal, pos)})
+			examples = append(examples, Example{description, yamlmeta.NewASTFromInterfaceWithPosition(exampleVal, pos), ann.Position})
 		}
 	}
 	return &ExampleAnnotation{examples, ann.Position}, nil
@@ -421,11 +482,326 @@ func NewValidationAnnotation(ann template.NodeAnnotation) (*ValidationAnnotation
 	return &ValidationAnnotation{validation, ann.Position}, nil
 }
 
+// PropertiesCountAnnotation is a wrapper for a limit on the number of items in a map provided via either
+// @schema/min-properties or @schema/max-properties annotation
+type PropertiesCountAnnotation struct {
+	name  template.AnnotationName
+	limit int64
+	pos   *filepos.Position
+}
+
+// FormatAnnotation is a wrapper for the format of a string provided via @schema/format annotation
+type FormatAnnotation struct {
+	format string
+	pos    *filepos.Position
+}
+
+// UnitAnnotation is a wrapper for the unit in which a scalar is expressed (e.g. "seconds") provided via @schema/unit
+// annotation
+type UnitAnnotation struct {
+	unit string
+	pos  *filepos.Position
+}
+
+// DiscriminatorAnnotation is a wrapper for the name of the property that identifies which of the alternatives of a
+// composition a value is (and, optionally, which value of that property identifies which alternative) provided via
+// @schema/discriminator annotation
+type DiscriminatorAnnotation struct {
+	propertyName string
+	mapping      []*yamlmeta.MapItem // of a value of the property to the name of an alternative
+	pos          *filepos.Position
+}
+
+// Formats of strings that can be given via @schema/format
+const (
+	FormatByte   = "byte"   // base64-encoded data
+	FormatBinary = "binary" // any sequence of octets
+	FormatDate   = "date"   // a full-date (RFC 3339), e.g. "2006-01-02"
+)
+
+// NewCompositionAnnotation checks the named alternatives provided via @schema/one-of, @schema/any-of, or
+// @schema/all-of annotation, and returns wrapper for the annotated node.
+//
+// Each alternative is given as a keyword argument: its name and a value (typically, a YAML fragment) from which the
+// type of that alternative is inferred (including any @schema/... annotations within that fragment).
+func NewCompositionAnnotation(name template.AnnotationName, ann template.NodeAnnotation, node yamlmeta.Node) (*CompositionAnnotation, error) {
+	if len(ann.Args) != 0 || len(ann.Kwargs) == 0 {
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     node.GetPosition(),
+			description:  fmt.Sprintf("syntax error in @%v annotation", name),
+			expected:     "one or more named alternatives",
+			found:        fmt.Sprintf("%v positional and %v keyword argument(s) in @%v (by %v)", len(ann.Args), len(ann.Kwargs), name, ann.Position.AsCompactString()),
+			hints:        []string{fmt.Sprintf("name each alternative, e.g. @%v circle=circle(), square=square()", name)},
+		}
+	}
+
+	compositionAnn := &CompositionAnnotation{composition: compositionsByAnnotation[name], node: node, pos: ann.Position}
+	for _, kwarg := range ann.Kwargs {
+		altName, err := core.NewStarlarkValue(kwarg[0]).AsString()
+		if err != nil {
+			return nil, err
+		}
+		altVal, err := core.NewStarlarkValue(kwarg[1]).AsGoValue()
+		if err != nil {
+			return nil, err
+		}
+		altType, err := InferTypeFromValue(yamlmeta.NewASTFromInterfaceWithPosition(altVal, ann.Position), ann.Position)
+		if err != nil {
+			return nil, err
+		}
+		if altType == nil {
+			return nil, schemaAssertionError{
+				annPositions: []*filepos.Position{ann.Position},
+				position:     node.GetPosition(),
+				description:  fmt.Sprintf("syntax error in @%v annotation", name),
+				expected:     "a non-null value describing the alternative",
+				found:        fmt.Sprintf("null value for %v (by %v)", altName, ann.Position.AsCompactString()),
+			}
+		}
+		compositionAnn.alternatives = append(compositionAnn.alternatives, &NamedType{Name: altName, Type: altType})
+	}
+	return compositionAnn, nil
+}
+
+// NewDiscriminatorAnnotation checks the arguments provided via @schema/discriminator annotation, and returns wrapper
+// for the discriminator: the name of a property (given positionally) and, optionally, a mapping= (a dictionary of each
+// value of that property to the name of the alternative it identifies).
+func NewDiscriminatorAnnotation(ann template.NodeAnnotation, pos *filepos.Position) (*DiscriminatorAnnotation, error) {
+	syntaxErr := func(expected, found string) error {
+		return schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationDiscriminator),
+			expected:     expected,
+			found:        fmt.Sprintf("%s (by %v)", found, ann.Position.AsCompactString()),
+			hints:        []string{fmt.Sprintf(`e.g. @%v "kind", mapping={"round": "circle"}`, AnnotationDiscriminator)},
+		}
+	}
+	if len(ann.Args) != 1 {
+		return nil, syntaxErr("the name of a property", fmt.Sprintf("%v positional argument(s) in @%v", len(ann.Args), AnnotationDiscriminator))
+	}
+	propertyName, ok := ann.Args[0].(starlark.String)
+	if !ok {
+		return nil, syntaxErr("the name of a property", fmt.Sprintf("%v in @%v", ann.Args[0].Type(), AnnotationDiscriminator))
+	}
+	discriminatorAnn := &DiscriminatorAnnotation{propertyName: propertyName.GoString(), pos: ann.Position}
+	for _, kwarg := range ann.Kwargs {
+		kwargName := string(kwarg[0].(starlark.String))
+		if kwargName != "mapping" {
+			return nil, syntaxErr("only the keyword argument mapping=", fmt.Sprintf("keyword argument %s= in @%v", kwargName, AnnotationDiscriminator))
+		}
+		mapping, ok := kwarg[1].(*starlark.Dict)
+		if !ok {
+			return nil, syntaxErr("mapping= to be a dictionary", fmt.Sprintf("%v in @%v", kwarg[1].Type(), AnnotationDiscriminator))
+		}
+		for _, item := range mapping.Items() {
+			value, isStr := item[0].(starlark.String)
+			altName, isAltStr := item[1].(starlark.String)
+			if !isStr || !isAltStr {
+				return nil, syntaxErr("mapping= of strings to (the names of alternatives as) strings",
+					fmt.Sprintf("%v: %v in @%v", item[0].Type(), item[1].Type(), AnnotationDiscriminator))
+			}
+			discriminatorAnn.mapping = append(discriminatorAnn.mapping, &yamlmeta.MapItem{Key: value.GoString(), Value: altName.GoString()})
+		}
+	}
+	return discriminatorAnn, nil
+}
+
+// NewPropertiesCountAnnotation checks the argument provided via @schema/min-properties or @schema/max-properties
+// annotation, and returns wrapper for the limit.
+func NewPropertiesCountAnnotation(name template.AnnotationName, ann template.NodeAnnotation, pos *filepos.Position) (*PropertiesCountAnnotation, error) {
+	if len(ann.Kwargs) != 0 || len(ann.Args) != 1 {
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", name),
+			expected:     "exactly one non-negative integer",
+			found:        fmt.Sprintf("%v values in @%v (by %v)", len(ann.Args)+len(ann.Kwargs), name, ann.Position.AsCompactString()),
+		}
+	}
+	limit, ok := ann.Args[0].(starlark.Int)
+	if !ok {
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", name),
+			expected:     "a non-negative integer",
+			found:        fmt.Sprintf("%v value in @%v (by %v)", ann.Args[0].Type(), name, ann.Position.AsCompactString()),
+		}
+	}
+	limitVal, ok := limit.Int64()
+	if !ok || limitVal < 0 {
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", name),
+			expected:     "a non-negative integer",
+			found:        fmt.Sprintf("%v in @%v (by %v)", limit.String(), name, ann.Position.AsCompactString()),
+		}
+	}
+	return &PropertiesCountAnnotation{name, limitVal, ann.Position}, nil
+}
+
+// NewFormatAnnotation checks the argument provided via @schema/format annotation, and returns wrapper for the format.
+func NewFormatAnnotation(ann template.NodeAnnotation, pos *filepos.Position) (*FormatAnnotation, error) {
+	if len(ann.Kwargs) != 0 || len(ann.Args) != 1 {
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationFormat),
+			expected:     fmt.Sprintf("exactly one of: %q, %q, or %q", FormatByte, FormatBinary, FormatDate),
+			found:        fmt.Sprintf("%v values in @%v (by %v)", len(ann.Args)+len(ann.Kwargs), AnnotationFormat, ann.Position.AsCompactString()),
+		}
+	}
+	format, ok := ann.Args[0].(starlark.String)
+	if !ok || (format.GoString() != FormatByte && format.GoString() != FormatBinary && format.GoString() != FormatDate) {
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("unknown format in @%v annotation", AnnotationFormat),
+			expected:     fmt.Sprintf("one of: %q, %q, or %q", FormatByte, FormatBinary, FormatDate),
+			found:        fmt.Sprintf("%v in @%v (by %v)", ann.Args[0].String(), AnnotationFormat, ann.Position.AsCompactString()),
+		}
+	}
+	return &FormatAnnotation{format.GoString(), ann.Position}, nil
+}
+
+// NewUnitAnnotation checks the argument provided via @schema/unit annotation, and returns wrapper for the unit.
+func NewUnitAnnotation(ann template.NodeAnnotation, pos *filepos.Position) (*UnitAnnotation, error) {
+	if len(ann.Kwargs) != 0 || len(ann.Args) != 1 {
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationUnit),
+			expected:     "the name of a unit (e.g. \"seconds\")",
+			found:        fmt.Sprintf("%v values in @%v (by %v)", len(ann.Args)+len(ann.Kwargs), AnnotationUnit, ann.Position.AsCompactString()),
+		}
+	}
+	unit, ok := ann.Args[0].(starlark.String)
+	if !ok || unit.GoString() == "" {
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationUnit),
+			expected:     "the name of a unit (e.g. \"seconds\")",
+			found:        fmt.Sprintf("%v in @%v (by %v)", ann.Args[0].String(), AnnotationUnit, ann.Position.AsCompactString()),
+		}
+	}
+	return &UnitAnnotation{unit.GoString(), ann.Position}, nil
+}
+
+// NewRequiredIfAnnotation checks the arguments provided via @schema/required-if annotation, and returns wrapper for the
+// conditional requirement.
+func NewRequiredIfAnnotation(ann template.NodeAnnotation, pos *filepos.Position) (*RequiredIfAnnotation, error) {
+	if len(ann.Args) != 1 {
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationRequiredIf),
+			expected:     "the name of the key to condition on (string)",
+			found:        fmt.Sprintf("%v values in @%v (by %v)", len(ann.Args), AnnotationRequiredIf, ann.Position.AsCompactString()),
+			hints:        []string{fmt.Sprintf("e.g. @%v \"enabled\", %v=[\"cert\"]", AnnotationRequiredIf, RequiredIfAnnotationKwargThen)},
+		}
+	}
+	key, err := core.NewStarlarkValue(ann.Args[0]).AsString()
+	if err != nil {
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationRequiredIf),
+			expected:     "the name of the key to condition on (string)",
+			found:        fmt.Sprintf("%v value in @%v (by %v)", ann.Args[0].Type(), AnnotationRequiredIf, ann.Position.AsCompactString()),
+		}
+	}
+
+	requiredIfAnn := &RequiredIfAnnotation{key: key, equals: starlark.True, pos: ann.Position}
+	for _, kwarg := range ann.Kwargs {
+		argName, err := core.NewStarlarkValue(kwarg[0]).AsString()
+		if err != nil {
+			return nil, err
+		}
+
+		switch argName {
+		case RequiredIfAnnotationKwargEquals:
+			switch kwarg[1].(type) {
+			case starlark.String, starlark.Int, starlark.Float, starlark.Bool, starlark.NoneType:
+				requiredIfAnn.equals = kwarg[1]
+			default:
+				return nil, schemaAssertionError{
+					annPositions: []*filepos.Position{ann.Position},
+					position:     pos,
+					description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationRequiredIf),
+					expected:     fmt.Sprintf("%v= to be a string, number, boolean, or None", argName),
+					found:        fmt.Sprintf("%v value (by %v)", kwarg[1].Type(), ann.Position.AsCompactString()),
+				}
+			}
+		case RequiredIfAnnotationKwargThen, RequiredIfAnnotationKwargOtherwise:
+			keys, err := requiredKeysFrom(kwarg[1])
+			if err != nil {
+				return nil, schemaAssertionError{
+					annPositions: []*filepos.Position{ann.Position},
+					position:     pos,
+					description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationRequiredIf),
+					expected:     fmt.Sprintf("%v= to be a list of key names (strings)", argName),
+					found:        fmt.Sprintf("%v (by %v)", err, ann.Position.AsCompactString()),
+				}
+			}
+			if argName == RequiredIfAnnotationKwargThen {
+				requiredIfAnn.then = keys
+			} else {
+				requiredIfAnn.otherwise = keys
+			}
+		default:
+			return nil, schemaAssertionError{
+				annPositions: []*filepos.Position{ann.Position},
+				position:     pos,
+				description:  fmt.Sprintf("unknown @%v annotation keyword argument", AnnotationRequiredIf),
+				expected:     "A valid kwarg",
+				found:        fmt.Sprintf("%s (by %s)", argName, ann.Position.AsCompactString()),
+				hints:        []string{fmt.Sprintf("Supported kwargs are '%v', '%v', '%v'", RequiredIfAnnotationKwargEquals, RequiredIfAnnotationKwargThen, RequiredIfAnnotationKwargOtherwise)},
+			}
+		}
+	}
+	if len(requiredIfAnn.then) == 0 && len(requiredIfAnn.otherwise) == 0 {
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationRequiredIf),
+			expected:     fmt.Sprintf("at least one of '%v' or '%v' keyword arguments", RequiredIfAnnotationKwargThen, RequiredIfAnnotationKwargOtherwise),
+			found:        fmt.Sprintf("no required keys in @%v (by %v)", AnnotationRequiredIf, ann.Position.AsCompactString()),
+		}
+	}
+	return requiredIfAnn, nil
+}
+
+func requiredKeysFrom(value starlark.Value) ([]string, error) {
+	seq, ok := value.(starlark.Sequence)
+	if !ok {
+		return nil, fmt.Errorf("%v value", value.Type())
+	}
+	var keys []string
+	var key starlark.Value
+	iter := seq.Iterate()
+	defer iter.Done()
+	for iter.Next(&key) {
+		keyStr, err := core.NewStarlarkValue(key).AsString()
+		if err != nil {
+			return nil, fmt.Errorf("%v in list", key.Type())
+		}
+		keys = append(keys, keyStr)
+	}
+	return keys, nil
+}
+
 // NewTypeFromAnn returns type information given by annotation.
 func (t *TypeAnnotation) NewTypeFromAnn() (Type, error) {
 	if t.any {
 		return &AnyType{defaultValue: t.node.GetValues()[0], Position: t.node.GetPosition()}, nil
 	}
+	if t.name == TypeAnnotationString {
+		return &ScalarType{ValueType: StringType, defaultValue: t.node.GetValues()[0], Position: t.node.GetPosition()}, nil
+	}
 	return nil, nil
 }
 
@@ -438,6 +814,11 @@ func (n *NullableAnnotation) NewTypeFromAnn() (Type, error) {
 	return &NullType{ValueType: inferredType, Position: n.node.GetPosition()}, nil
 }
 
+// NewTypeFromAnn returns type information given by annotation.
+func (c *CompositionAnnotation) NewTypeFromAnn() (Type, error) {
+	return &CompositeType{Composition: c.composition, Alternatives: c.alternatives, defaultValue: c.node.GetValues()[0], Position: c.node.GetPosition()}, nil
+}
+
 // NewTypeFromAnn returns type information given by annotation.
 func (d *DefaultAnnotation) NewTypeFromAnn() (Type, error) {
 	return nil, nil
@@ -468,6 +849,16 @@ func (v *ValidationAnnotation) NewTypeFromAnn() (Type, error) {
 	return nil, nil
 }
 
+// NewTypeFromAnn returns type information given by annotation. RequiredIfAnnotation has no type information.
+func (r *RequiredIfAnnotation) NewTypeFromAnn() (Type, error) {
+	return nil, nil
+}
+
+// NewTypeFromAnn returns type information given by annotation. PropertiesCountAnnotation has no type information.
+func (p *PropertiesCountAnnotation) NewTypeFromAnn() (Type, error) {
+	return nil, nil
+}
+
 // GetPosition returns position of the source comment used to create this annotation.
 func (n *NullableAnnotation) GetPosition() *filepos.Position {
 	return n.pos
@@ -478,6 +869,11 @@ func (t *TypeAnnotation) GetPosition() *filepos.Position {
 	return t.pos
 }
 
+// GetPosition returns position of the source comment used to create this annotation.
+func (c *CompositionAnnotation) GetPosition() *filepos.Position {
+	return c.pos
+}
+
 // GetPosition returns position of the source comment used to create this annotation.
 func (d *DefaultAnnotation) GetPosition() *filepos.Position {
 	return d.pos
@@ -508,6 +904,16 @@ func (v *ValidationAnnotation) GetPosition() *filepos.Position {
 	return nil
 }
 
+// GetPosition returns position of the source comment used to create this annotation.
+func (r *RequiredIfAnnotation) GetPosition() *filepos.Position {
+	return r.pos
+}
+
+// GetPosition returns position of the source comment used to create this annotation.
+func (p *PropertiesCountAnnotation) GetPosition() *filepos.Position {
+	return p.pos
+}
+
 // GetValidation gets the NodeValidation created from @schema/validation annotation
 func (v *ValidationAnnotation) GetValidation() *validations.NodeValidation {
 	return v.validation
@@ -517,6 +923,16 @@ func (t *TypeAnnotation) IsAny() bool {
 	return t.any
 }
 
+// IsNotAny reports whether the annotation explicitly excludes values of any type (i.e. any=False was given).
+func (t *TypeAnnotation) IsNotAny() bool {
+	return t.notAny
+}
+
+// IsNamed reports whether the annotation gives the type by name (e.g. @schema/type "string").
+func (t *TypeAnnotation) IsNamed() bool {
+	return t.name != ""
+}
+
 // Val returns default value specified in annotation.
 func (d *DefaultAnnotation) Val() interface{} {
 	return d.val
@@ -525,7 +941,7 @@ func (d *DefaultAnnotation) Val() interface{} {
 func collectTypeAnnotations(node yamlmeta.Node) ([]Annotation, error) {
 	var anns []Annotation
 
-	for _, annotation := range []template.AnnotationName{AnnotationType, AnnotationNullable} {
+	for _, annotation := range []template.AnnotationName{AnnotationType, AnnotationNullable, AnnotationOneOf, AnnotationAnyOf, AnnotationAllOf} {
 		ann, err := processOptionalAnnotation(node, annotation, nil)
 		if err != nil {
 			return nil, err
@@ -587,6 +1003,12 @@ func processOptionalAnnotation(node yamlmeta.Node, optionalAnnotation template.A
 				return nil, err
 			}
 			return typeAnn, nil
+		case AnnotationOneOf, AnnotationAnyOf, AnnotationAllOf:
+			compositionAnn, err := NewCompositionAnnotation(optionalAnnotation, ann, node)
+			if err != nil {
+				return nil, err
+			}
+			return compositionAnn, nil
 		case AnnotationDefault:
 			switch node.(type) {
 			case *yamlmeta.DocumentSet, *yamlmeta.Array, *yamlmeta.Map:
@@ -609,14 +1031,7 @@ func processOptionalAnnotation(node yamlmeta.Node, optionalAnnotation template.A
 			}
 			return defaultAnn, nil
 		case AnnotationDeprecated:
-			if _, ok := node.(*yamlmeta.Document); ok {
-				return nil, schemaAssertionError{
-					description:  fmt.Sprintf("@%v not supported on a %s", AnnotationDeprecated, yamlmeta.TypeName(node)),
-					annPositions: []*filepos.Position{ann.Position},
-					position:     node.GetPosition(),
-					hints:        []string{"do you mean to deprecate the entire schema document?", "use schema/deprecated on individual keys."},
-				}
-			}
+			// on a document, deprecates the entire schema (i.e. the document's value)
 			deprAnn, err := NewDeprecatedAnnotation(ann, node.GetPosition())
 			if err != nil {
 				return nil, err
@@ -658,14 +1073,229 @@ func processValidationAnnotation(node yamlmeta.Node) (*ValidationAnnotation, err
 	return nil, nil
 }
 
+func processRequiredIfAnnotation(node yamlmeta.Node) (*RequiredIfAnnotation, error) {
+	nodeAnnotations := template.NewAnnotations(node)
+	if nodeAnnotations.Has(AnnotationRequiredIf) {
+		return NewRequiredIfAnnotation(nodeAnnotations[AnnotationRequiredIf], node.GetPosition())
+	}
+	return nil, nil
+}
+
+func processFormatAnnotation(node yamlmeta.Node) (*FormatAnnotation, error) {
+	nodeAnnotations := template.NewAnnotations(node)
+	if nodeAnnotations.Has(AnnotationFormat) {
+		return NewFormatAnnotation(nodeAnnotations[AnnotationFormat], node.GetPosition())
+	}
+	return nil, nil
+}
+
+// setFormatFromAnn sets the format of the string described by "typeOfValue".
+func setFormatFromAnn(ann *FormatAnnotation, typeOfValue Type) error {
+	if nullType, ok := typeOfValue.(*NullType); ok {
+		typeOfValue = nullType.GetValueType()
+	}
+	scalarType, ok := typeOfValue.(*ScalarType)
+	if !ok || scalarType.ValueType != StringType {
+		return NewSchemaError(fmt.Sprintf("Invalid schema - @%v not supported on %s", AnnotationFormat, typeOfValue.String()),
+			schemaAssertionError{
+				annPositions: []*filepos.Position{ann.pos},
+				position:     typeOfValue.GetDefinitionPosition(),
+				hints:        []string{"only strings have a format."},
+			})
+	}
+	scalarType.format = ann.format
+	return nil
+}
+
+func processUnitAnnotation(node yamlmeta.Node) (*UnitAnnotation, error) {
+	nodeAnnotations := template.NewAnnotations(node)
+	if nodeAnnotations.Has(AnnotationUnit) {
+		return NewUnitAnnotation(nodeAnnotations[AnnotationUnit], node.GetPosition())
+	}
+	return nil, nil
+}
+
+// setUnitFromAnn sets the unit of the scalar described by "typeOfValue".
+func setUnitFromAnn(ann *UnitAnnotation, typeOfValue Type) error {
+	if nullType, ok := typeOfValue.(*NullType); ok {
+		typeOfValue = nullType.GetValueType()
+	}
+	scalarType, ok := typeOfValue.(*ScalarType)
+	if !ok {
+		return NewSchemaError(fmt.Sprintf("Invalid schema - @%v not supported on %s", AnnotationUnit, typeOfValue.String()),
+			schemaAssertionError{
+				annPositions: []*filepos.Position{ann.pos},
+				position:     typeOfValue.GetDefinitionPosition(),
+				hints:        []string{"only scalars (e.g. numbers) have a unit."},
+			})
+	}
+	scalarType.unit = ann.unit
+	return nil
+}
+
+func processDiscriminatorAnnotation(node yamlmeta.Node) (*DiscriminatorAnnotation, error) {
+	nodeAnnotations := template.NewAnnotations(node)
+	if nodeAnnotations.Has(AnnotationDiscriminator) {
+		return NewDiscriminatorAnnotation(nodeAnnotations[AnnotationDiscriminator], node.GetPosition())
+	}
+	return nil, nil
+}
+
+// setDiscriminatorFromAnn sets the discriminator of the composition described by "typeOfValue": each of its
+// alternatives must be a map containing the discriminating property.
+func setDiscriminatorFromAnn(ann *DiscriminatorAnnotation, typeOfValue Type) error {
+	compositeType, ok := typeOfValue.(*CompositeType)
+	if !ok {
+		return NewSchemaError(fmt.Sprintf("Invalid schema - @%v not supported on %s", AnnotationDiscriminator, typeOfValue.String()),
+			schemaAssertionError{
+				annPositions: []*filepos.Position{ann.pos},
+				position:     typeOfValue.GetDefinitionPosition(),
+				hints:        []string{"only a composition (i.e. @schema/one-of, @schema/any-of, or @schema/all-of) has a discriminator."},
+			})
+	}
+	for _, alt := range compositeType.Alternatives {
+		mapType, ok := alt.Type.(*MapType)
+		if !ok || !mapType.hasKey(ann.propertyName) {
+			return NewSchemaError(fmt.Sprintf("Invalid schema - @%v refers to a property missing from an alternative", AnnotationDiscriminator),
+				schemaAssertionError{
+					annPositions: []*filepos.Position{ann.pos},
+					position:     compositeType.GetDefinitionPosition(),
+					expected:     fmt.Sprintf("every alternative to be a map containing %q", ann.propertyName),
+					found:        fmt.Sprintf("%s (by %s)", alt.Name, ann.pos.AsCompactString()),
+				})
+		}
+	}
+	for _, mapping := range ann.mapping {
+		if !compositeType.hasAlternative(mapping.Value.(string)) {
+			return NewSchemaError(fmt.Sprintf("Invalid schema - @%v maps to an unknown alternative", AnnotationDiscriminator),
+				schemaAssertionError{
+					annPositions: []*filepos.Position{ann.pos},
+					position:     compositeType.GetDefinitionPosition(),
+					expected:     "the name of an alternative",
+					found:        fmt.Sprintf("%s (by %s)", mapping.Value, ann.pos.AsCompactString()),
+				})
+		}
+	}
+	compositeType.discriminator = ann
+	return nil
+}
+
+func processPropertiesCountAnnotations(node yamlmeta.Node) ([]*PropertiesCountAnnotation, error) {
+	var anns []*PropertiesCountAnnotation
+	nodeAnnotations := template.NewAnnotations(node)
+	for _, annName := range []template.AnnotationName{AnnotationMinProperties, AnnotationMaxProperties} {
+		if nodeAnnotations.Has(annName) {
+			ann, err := NewPropertiesCountAnnotation(annName, nodeAnnotations[annName], node.GetPosition())
+			if err != nil {
+				return nil, err
+			}
+			anns = append(anns, ann)
+		}
+	}
+	return anns, nil
+}
+
+// setPropertiesCountFromAnn limits the number of items in the map described by "typeOfValue".
+func setPropertiesCountFromAnn(ann *PropertiesCountAnnotation, typeOfValue Type) error {
+	if nullType, ok := typeOfValue.(*NullType); ok {
+		typeOfValue = nullType.GetValueType()
+	}
+	mapType, ok := typeOfValue.(*MapType)
+	if !ok {
+		return NewSchemaError(fmt.Sprintf("Invalid schema - @%v not supported on %s", ann.name, typeOfValue.String()),
+			schemaAssertionError{
+				annPositions: []*filepos.Position{ann.GetPosition()},
+				position:     typeOfValue.GetDefinitionPosition(),
+				hints:        []string{"to limit the length of an array or string, use @schema/validation min_len=... or max_len=..."},
+			})
+	}
+	limit := ann.limit
+	if ann.name == AnnotationMinProperties {
+		mapType.minProperties = &limit
+	} else {
+		mapType.maxProperties = &limit
+	}
+	mapType.validations = validations.Merge(mapType.validations, ann.asValidation())
+	return nil
+}
+
+// asValidation produces the rule that enforces this limit on the number of a map's items.
+func (p *PropertiesCountAnnotation) asValidation() *validations.NodeValidation {
+	limit := starlark.MakeInt64(p.limit)
+	if p.name == AnnotationMinProperties {
+		return validations.NewValidationFromAssertions(
+			[]string{fmt.Sprintf("number of properties >= %v", p.limit)},
+			[]starlark.Callable{yttlibrary.NewAssertMinLen(limit).CheckFunc()}, p.pos)
+	}
+	return validations.NewValidationFromAssertions(
+		[]string{fmt.Sprintf("number of properties <= %v", p.limit)},
+		[]starlark.Callable{yttlibrary.NewAssertMaxLen(limit).CheckFunc()}, p.pos)
+}
+
+// setRequiredIfFromAnn attaches the conditional requirement to the map described by "typeOfValue".
+func setRequiredIfFromAnn(ann *RequiredIfAnnotation, typeOfValue Type) error {
+	if nullType, ok := typeOfValue.(*NullType); ok {
+		typeOfValue = nullType.GetValueType()
+	}
+	mapType, ok := typeOfValue.(*MapType)
+	if !ok {
+		return NewSchemaError(fmt.Sprintf("Invalid schema - @%v not supported on %s", AnnotationRequiredIf, typeOfValue.String()),
+			schemaAssertionError{
+				annPositions: []*filepos.Position{ann.GetPosition()},
+				position:     typeOfValue.GetDefinitionPosition(),
+				hints:        []string{"annotate a node whose value is a map, naming keys within that map."},
+			})
+	}
+	for _, key := range append(append([]string{ann.key}, ann.then...), ann.otherwise...) {
+		if !mapType.hasKey(key) {
+			return NewSchemaError(fmt.Sprintf("Invalid schema - @%v refers to an unknown key", AnnotationRequiredIf),
+				schemaAssertionError{
+					annPositions: []*filepos.Position{ann.GetPosition()},
+					position:     mapType.GetDefinitionPosition(),
+					expected:     "a key within the annotated map",
+					found:        fmt.Sprintf("%s (by %s)", key, ann.GetPosition().AsCompactString()),
+				})
+		}
+	}
+	mapType.requiredIf = ann
+	mapType.validations = validations.Merge(mapType.validations, ann.asValidation())
+	return nil
+}
+
+// asValidation produces the rules that enforce this conditional requirement on a map's values.
+func (r *RequiredIfAnnotation) asValidation() *validations.NodeValidation {
+	var msgs []string
+	var assertions []starlark.Callable
+	key := starlark.String(r.key)
+	if len(r.then) > 0 {
+		msgs = append(msgs, fmt.Sprintf("all of %s to be present and not null, when %s is %s", asStarlarkList(r.then), key.String(), r.equals.String()))
+		assertions = append(assertions, yttlibrary.NewAssertRequiredIf(key, r.equals, asStarlarkList(r.then), false).CheckFunc())
+	}
+	if len(r.otherwise) > 0 {
+		msgs = append(msgs, fmt.Sprintf("all of %s to be present and not null, when %s is not %s", asStarlarkList(r.otherwise), key.String(), r.equals.String()))
+		assertions = append(assertions, yttlibrary.NewAssertRequiredIf(key, r.equals, asStarlarkList(r.otherwise), true).CheckFunc())
+	}
+	return validations.NewValidationFromAssertions(msgs, assertions, r.pos)
+}
+
+func asStarlarkList(strs []string) *starlark.List {
+	var vals []starlark.Value
+	for _, str := range strs {
+		vals = append(vals, starlark.String(str))
+	}
+	return starlark.NewList(vals)
+}
+
 func getTypeFromAnnotations(anns []Annotation) (Type, error) {
 	annsCopy := append([]Annotation{}, anns...)
 
 	var typeFromAnn Type
+	var notAny bool
 	for _, ann := range annsCopy {
 		switch typedAnn := ann.(type) {
 		case *TypeAnnotation:
-			if typedAnn.IsAny() {
+			notAny = typedAnn.IsNotAny()
+			if typedAnn.IsAny() || typedAnn.IsNamed() {
 				var err error
 				typeFromAnn, err = typedAnn.NewTypeFromAnn()
 				if err != nil {
@@ -679,8 +1309,29 @@ func getTypeFromAnnotations(anns []Annotation) (Type, error) {
 				if err != nil {
 					return nil, err
 				}
-			} else {
+				// a null value, explicitly of no other type (via @schema/type any=False), can only ever be null.
+				if nullType := typeFromAnn.(*NullType); nullType.ValueType == nil && notAny {
+					nullType.ValueType = &ScalarType{ValueType: NullValueType, Position: typedAnn.node.GetPosition()}
+				}
+			} else if _, isAny := typeFromAnn.(*AnyType); isAny {
 				typeFromAnn.SetDefaultValue(nil)
+			} else {
+				typeFromAnn = &NullType{ValueType: typeFromAnn, Position: typedAnn.node.GetPosition()}
+			}
+		case *CompositionAnnotation:
+			if typeFromAnn != nil {
+				return nil, schemaAssertionError{
+					annPositions: []*filepos.Position{typedAnn.GetPosition()},
+					position:     typedAnn.node.GetPosition(),
+					description:  "composition cannot be combined with other type annotations",
+					expected:     "only one of @schema/type, @schema/nullable, @schema/one-of, @schema/any-of, or @schema/all-of",
+					found:        fmt.Sprintf("more than one type annotation (by %v)", typedAnn.GetPosition().AsCompactString()),
+				}
+			}
+			var err error
+			typeFromAnn, err = typedAnn.NewTypeFromAnn()
+			if err != nil {
+				return nil, err
 			}
 		default:
 			continue
@@ -161,6 +161,14 @@ func (n NullType) AssignTypeTo(node yamlmeta.Node) TypeCheck {
 	return chk
 }
 
+// AssignTypeTo assigns this schema metadata to `node`.
+//
+// The contents of `node` are not assigned a type: which alternative(s) they match is determined when checked.
+func (c *CompositeType) AssignTypeTo(node yamlmeta.Node) TypeCheck {
+	SetType(node, c)
+	return TypeCheck{}
+}
+
 // AssignSchemaValidations implements the visitor interface to set validations from the schema type
 type AssignSchemaValidations struct{}
 
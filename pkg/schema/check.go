@@ -4,9 +4,16 @@
 package schema
 
 import (
+	"encoding/base64"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/filepos"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/validations"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/yamlmeta"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/yttlibrary"
 )
 
 // CheckNode attempts type check of root node and its children.
@@ -24,6 +31,66 @@ func CheckNode(n yamlmeta.Node) TypeCheck {
 	return *checker.chk
 }
 
+// CheckFormats checks that each string within `n` whose type declares a format (via @schema/format) is, in fact,
+// encoded in that format, reporting each that is not (just as data values validations do).
+func CheckFormats(n yamlmeta.Node) validations.Check {
+	checker := &formatChecker{chk: &validations.Check{}}
+
+	err := yamlmeta.WalkWithParent(n, nil, "", checker)
+	if err != nil {
+		panic(err)
+	}
+
+	return *checker.chk
+}
+
+type formatChecker struct {
+	chk *validations.Check
+}
+
+func (f *formatChecker) VisitWithParent(node yamlmeta.Node, _ yamlmeta.Node, path string) error {
+	var valueType Type
+	switch nodeType := GetType(node).(type) {
+	case *DocumentType, *MapItemType, *ArrayItemType:
+		valueType = nodeType.GetValueType()
+	default:
+		return nil
+	}
+	if nullType, ok := valueType.(*NullType); ok {
+		valueType = nullType.GetValueType()
+	}
+	scalarType, ok := valueType.(*ScalarType)
+	if !ok {
+		return nil
+	}
+	value, ok := node.GetValues()[0].(string)
+	if !ok {
+		return nil
+	}
+	switch scalarType.format {
+	case FormatByte:
+		if _, err := base64.StdEncoding.DecodeString(value); err != nil {
+			f.invalid(node, path, scalarType, "a base64-encoded string", fmt.Sprintf("string that does not decode: %s", err))
+		}
+	case FormatDate:
+		if _, err := time.Parse(yttlibrary.DateLayout, value); err != nil {
+			f.invalid(node, path, scalarType, "a date (e.g. 2006-01-02)", fmt.Sprintf("%q", value))
+		}
+	}
+	return nil
+}
+
+func (f *formatChecker) invalid(node yamlmeta.Node, path string, scalarType *ScalarType, expected, found string) {
+	if path == "" {
+		path = fmt.Sprintf("(%s)", yamlmeta.TypeName(node))
+	}
+	f.chk.Invalidations = append(f.chk.Invalidations, validations.Invalidation{
+		Path:        path,
+		ValueSource: node.GetPosition(),
+		Violations:  []validations.Violation{{RuleSource: scalarType.GetDefinitionPosition(), Description: expected, Results: found}},
+	})
+}
+
 // TypeCheck is the result of checking a yamlmeta.Node structure against a given Type, recursively.
 type TypeCheck struct {
 	Violations []error
@@ -249,3 +316,225 @@ func (n NullType) CheckType(node yamlmeta.Node) TypeCheck {
 
 	return chk
 }
+
+// CheckType checks `node`'s value against each of this CompositeType's alternatives.
+//
+// If the alternatives that admit the value do not satisfy this CompositeType's Composition, `chk` contains a
+// violation naming each alternative that did not match (and why).
+func (c *CompositeType) CheckType(node yamlmeta.Node) TypeCheck {
+	var matched []string
+	var hints []string
+	for _, alt := range c.Alternatives {
+		altChk := checkAlternative(alt.Type, node)
+		if altChk.HasViolations() {
+			hints = append(hints, fmt.Sprintf("not %s: %s", alt.Name, describeViolation(altChk.Violations[0])))
+		} else {
+			matched = append(matched, alt.Name)
+		}
+	}
+
+	var satisfied bool
+	switch c.Composition {
+	case CompositionOneOf:
+		satisfied = len(matched) == 1
+	case CompositionAnyOf:
+		satisfied = len(matched) > 0
+	case CompositionAllOf:
+		satisfied = len(matched) == len(c.Alternatives)
+	}
+	if satisfied {
+		return TypeCheck{}
+	}
+
+	found := "a value matching none of them"
+	if len(matched) > 0 {
+		found = fmt.Sprintf("a value matching %s", strings.Join(matched, ", "))
+	}
+	return TypeCheck{[]error{schemaAssertionError{
+		position: node.GetPosition(),
+		expected: fmt.Sprintf("a value matching %s (by %s)", c.String(), c.GetDefinitionPosition().AsCompactString()),
+		found:    found,
+		hints:    hints,
+	}}}
+}
+
+// checkAlternative checks `node`'s value against `t` without altering `node`.
+func checkAlternative(t Type, node yamlmeta.Node) TypeCheck {
+	switch node.(type) {
+	case *yamlmeta.Map, *yamlmeta.Array:
+		value := node.DeepCopyAsNode()
+		chk := t.AssignTypeTo(value)
+		if !chk.HasViolations() {
+			chk = CheckNode(value)
+		}
+		return chk
+	default:
+		return t.CheckType(node) // scalar values are checked on their containing node
+	}
+}
+
+func describeViolation(err error) string {
+	if assertionErr, ok := err.(schemaAssertionError); ok {
+		return fmt.Sprintf("found %s, expected %s", assertionErr.found, assertionErr.expected)
+	}
+	return err.Error()
+}
+
+// CheckExamples verifies that each example given via @schema/examples includes a (non-null) value wherever the
+// schema requires one (i.e. where a value is validated with not_null=True).
+//
+// Returns an error describing every example that does not.
+func CheckExamples(docType *DocumentType) error {
+	var errs []error
+	collectExampleViolations(docType.GetValueType(), &errs)
+	if len(errs) > 0 {
+		return NewSchemaError("Invalid schema - example is missing required value(s)", errs...)
+	}
+	return nil
+}
+
+func collectExampleViolations(t Type, errs *[]error) {
+	for _, example := range t.GetExamples() {
+		for _, path := range missingRequiredValues(t, example.example, "") {
+			*errs = append(*errs, schemaAssertionError{
+				description:  fmt.Sprintf("example %q", example.description),
+				annPositions: []*filepos.Position{example.position},
+				position:     t.GetDefinitionPosition(),
+				expected:     fmt.Sprintf("a non-null value for %q", path),
+				found:        "null value",
+				hints:        []string{"the value is validated with not_null=True in schema."},
+			})
+		}
+	}
+
+	switch typedType := t.(type) {
+	case *MapType:
+		for _, item := range typedType.Items {
+			collectExampleViolations(item.GetValueType(), errs)
+		}
+	case *ArrayType:
+		collectExampleViolations(typedType.GetValueType().GetValueType(), errs)
+	case *NullType:
+		collectExampleViolations(typedType.GetValueType(), errs)
+	}
+}
+
+// missingRequiredValues walks `value` alongside `t`, returning the path to each value that is null (or missing) but
+// required to be not null.
+func missingRequiredValues(t Type, value interface{}, path string) []string {
+	var missing []string
+	switch typedType := t.(type) {
+	case *MapType:
+		valueMap, ok := value.(*yamlmeta.Map)
+		if !ok {
+			return nil
+		}
+		for _, itemType := range typedType.Items {
+			var itemValue interface{}
+			for _, item := range valueMap.Items {
+				if item.Key == itemType.Key {
+					itemValue = item.Value
+				}
+			}
+			itemPath := fmt.Sprintf("%s.%v", path, itemType.Key)
+			if itemValue == nil {
+				if isNotNull(itemType) {
+					missing = append(missing, strings.TrimPrefix(itemPath, "."))
+				}
+				continue
+			}
+			missing = append(missing, missingRequiredValues(itemType.GetValueType(), itemValue, itemPath)...)
+		}
+	case *ArrayType:
+		valueArray, ok := value.(*yamlmeta.Array)
+		if !ok {
+			return nil
+		}
+		itemType := typedType.GetValueType()
+		for idx, item := range valueArray.Items {
+			itemPath := fmt.Sprintf("%s[%d]", path, idx)
+			if item.Value == nil {
+				if isNotNull(itemType) {
+					missing = append(missing, strings.TrimPrefix(itemPath, "."))
+				}
+				continue
+			}
+			missing = append(missing, missingRequiredValues(itemType.GetValueType(), item.Value, itemPath)...)
+		}
+	case *NullType:
+		missing = append(missing, missingRequiredValues(typedType.GetValueType(), value, path)...)
+	}
+	return missing
+}
+
+func isNotNull(t Type) bool {
+	v := t.GetValidation()
+	return v != nil && v.GetValidationKwargs().GetNotNull()
+}
+
+// CheckOneOfLengths verifies that each of the values allowed via one_of= is also allowed by the length rules
+// (min_len= and max_len=) of the same validation: otherwise, the schema is inconsistent (that value could never be
+// given).
+//
+// Returns an error describing every such value.
+func CheckOneOfLengths(docType *DocumentType) error {
+	var errs []error
+	collectOneOfLengthViolations(docType, &errs)
+	if len(errs) > 0 {
+		return NewSchemaError("Invalid schema - one_of= allows values that the length rules reject", errs...)
+	}
+	return nil
+}
+
+func collectOneOfLengthViolations(t Type, errs *[]error) {
+	if validation := t.GetValidation(); validation != nil {
+		kwargs := validation.GetValidationKwargs()
+		allowed, hasOneOf := kwargs.GetOneOf()
+		minLen, hasMinLen := kwargs.GetMinLength()
+		maxLen, hasMaxLen := kwargs.GetMaxLength()
+		if hasOneOf && (hasMinLen || hasMaxLen) {
+			iter := allowed.Iterate()
+			var member starlark.Value
+			for iter.Next(&member) {
+				length, ok := lengthOf(member)
+				if !ok || (!hasMinLen || length >= minLen) && (!hasMaxLen || length <= maxLen) {
+					continue
+				}
+				var expected string
+				switch {
+				case hasMinLen && hasMaxLen:
+					expected = fmt.Sprintf("length between %d and %d", minLen, maxLen)
+				case hasMinLen:
+					expected = fmt.Sprintf("length >= %d", minLen)
+				default:
+					expected = fmt.Sprintf("length <= %d", maxLen)
+				}
+				*errs = append(*errs, schemaAssertionError{
+					description:  fmt.Sprintf("one_of= value %s", member.String()),
+					annPositions: []*filepos.Position{validation.GetPosition()},
+					position:     t.GetDefinitionPosition(),
+					expected:     fmt.Sprintf("%s (by %s)", expected, validation.GetPosition().AsCompactString()),
+					found:        fmt.Sprintf("length %d", length),
+				})
+			}
+			iter.Done()
+		}
+	}
+
+	switch typedType := t.(type) {
+	case *DocumentType, *MapItemType, *ArrayItemType, *NullType:
+		collectOneOfLengthViolations(typedType.GetValueType(), errs)
+	case *MapType:
+		for _, item := range typedType.Items {
+			collectOneOfLengthViolations(item, errs)
+		}
+	case *ArrayType:
+		collectOneOfLengthViolations(typedType.GetValueType(), errs)
+	}
+}
+
+// lengthOf is the length of "value" — as measured by min_len= and max_len= — if it has one.
+func lengthOf(value starlark.Value) (int64, bool) {
+	length := starlark.Len(value)
+	return int64(length), length >= 0
+}
@@ -0,0 +1,78 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"strings"
+
+	"github.com/vmware-tanzu/carvel-ytt/pkg/template"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/yamlmeta"
+)
+
+// LeadingComments holds the text of the comments (e.g. `#! ...`) on the lines preceding nodes. This is the text that
+// documents a node in a well-commented schema.
+type LeadingComments map[leadingCommentKey]string
+
+// leadingCommentKey identifies a node by its kind and position (an array item and the first item of the map it
+// holds start on the same line).
+type leadingCommentKey struct {
+	kind string
+	pos  string
+}
+
+// NewLeadingComments collects the comments preceding each node within "docSet" (which must have been parsed with
+// its comments).
+func NewLeadingComments(docSet *yamlmeta.DocumentSet) LeadingComments {
+	comments := LeadingComments{}
+	_ = yamlmeta.Walk(docSet, comments)
+	return comments
+}
+
+// Visit records the comments preceding "node", if any.
+func (c LeadingComments) Visit(node yamlmeta.Node) error {
+	var lines []string
+	for _, comment := range node.GetComments() {
+		if comment.Position.LineNum() >= node.GetPosition().LineNum() {
+			continue
+		}
+		ann, err := template.NewAnnotationFromComment(comment.Data, comment.Position, template.MetaOpts{IgnoreUnknown: true})
+		if err != nil || ann.Name != template.AnnotationComment {
+			continue
+		}
+		if text := strings.TrimSpace(ann.Content); text != "" {
+			lines = append(lines, text)
+		}
+	}
+	if len(lines) > 0 {
+		c[leadingCommentKey{yamlmeta.TypeName(node), node.GetPosition().AsCompactString()}] = strings.Join(lines, " ")
+	}
+	return nil
+}
+
+// Merge adds all the comments in "other" to these.
+func (c LeadingComments) Merge(other LeadingComments) {
+	for key, text := range other {
+		c[key] = text
+	}
+}
+
+// Of provides the text of the comments preceding the node described by "t", if any.
+func (c LeadingComments) Of(t Type) string {
+	var kind string
+	switch t.(type) {
+	case *DocumentType:
+		kind = yamlmeta.TypeName(&yamlmeta.Document{})
+	case *MapItemType:
+		kind = yamlmeta.TypeName(&yamlmeta.MapItem{})
+	case *ArrayItemType:
+		kind = yamlmeta.TypeName(&yamlmeta.ArrayItem{})
+	default:
+		return ""
+	}
+	pos := t.GetDefinitionPosition()
+	if pos == nil || !pos.IsKnown() {
+		return ""
+	}
+	return c[leadingCommentKey{kind, pos.AsCompactString()}]
+}
@@ -0,0 +1,413 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/vmware-tanzu/carvel-ytt/pkg/filepos"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/validations"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/yamlmeta"
+)
+
+// JSONSchema is an (external) JSON Schema against which data values can be checked, in addition to (or in place of)
+// a data values schema.
+//
+// The commonly used subset of JSON Schema (and of its OpenAPI dialect) is supported: the keywords "type", "enum",
+// "const", "properties", "required", "additionalProperties", "items", numeric and length bounds, "pattern", "nullable",
+// "allOf", "anyOf", "oneOf", "not" and references within the same document (via "$ref", e.g. to "#/definitions/...").
+// Any other keyword is ignored.
+type JSONSchema struct {
+	root *yamlmeta.Map
+}
+
+// NewJSONSchema creates a JSONSchema from "doc" (as parsed from a JSON or YAML file).
+func NewJSONSchema(doc interface{}) (*JSONSchema, error) {
+	root, ok := doc.(*yamlmeta.Map)
+	if !ok {
+		return nil, fmt.Errorf("Expected JSON Schema to be an object, but was %s", yamlmeta.TypeName(doc))
+	}
+	return &JSONSchema{root: root}, nil
+}
+
+// Check verifies that the value of "doc" conforms to this schema, reporting each place it does not (just as data
+// values validations do).
+func (s *JSONSchema) Check(doc *yamlmeta.Document) validations.Check {
+	chk := &validations.Check{}
+	s.check(doc.Value, doc.GetPosition(), "", s.root, map[string]bool{}, chk)
+	return *chk
+}
+
+// check verifies that "value" (found at "pos", addressed by "path") conforms to "schema", recording any violations
+// in "chk". "followedRefs" are the references followed since last descending into "value" (guarding against cycles).
+func (s *JSONSchema) check(value interface{}, pos *filepos.Position, path string, schema *yamlmeta.Map, followedRefs map[string]bool, chk *validations.Check) {
+	if value == nil {
+		if nullable, found := jsonSchemaKeyword(schema, "nullable"); found && nullable.Value == true {
+			return
+		}
+	}
+
+	// a reference already followed (for this value) would only lead back here: the rest of this schema still applies.
+	if ref, found := jsonSchemaKeyword(schema, "$ref"); found && !followedRefs[fmt.Sprintf("%v", ref.Value)] {
+		refStr := fmt.Sprintf("%v", ref.Value)
+		target, err := s.resolve(refStr)
+		if err != nil {
+			chk.Invalidations = append(chk.Invalidations, s.violation(pos, path, err.Error(), ref.Position, "a resolvable $ref"))
+			return
+		}
+		followedRefs[refStr] = true
+		s.check(value, pos, path, target, followedRefs, chk)
+		delete(followedRefs, refStr)
+	}
+
+	for _, keyword := range schema.Items {
+		switch keyword.Key {
+		case "type":
+			s.checkType(value, pos, path, keyword, chk)
+		case "enum":
+			s.checkEnum(value, pos, path, keyword, chk)
+		case "const":
+			if inlineJSON(value) != inlineJSON(keyword.Value) {
+				chk.Invalidations = append(chk.Invalidations, s.violation(pos, path, inlineJSON(value), keyword.Position, inlineJSON(keyword.Value)))
+			}
+		case "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum":
+			s.checkBound(value, pos, path, schema, keyword, chk)
+		case "minLength", "maxLength":
+			if str, ok := value.(string); ok {
+				s.checkLen(utf8.RuneCountInString(str), "length", pos, path, keyword, chk)
+			}
+		case "minItems", "maxItems":
+			if array, ok := value.(*yamlmeta.Array); ok {
+				s.checkLen(len(array.Items), "number of items", pos, path, keyword, chk)
+			}
+		case "minProperties", "maxProperties":
+			if m, ok := value.(*yamlmeta.Map); ok {
+				s.checkLen(len(m.Items), "number of properties", pos, path, keyword, chk)
+			}
+		case "pattern":
+			s.checkPattern(value, pos, path, keyword, chk)
+		case "required":
+			s.checkRequired(value, pos, path, keyword, chk)
+		case "properties":
+			s.checkProperties(value, path, schema, keyword, chk)
+		case "items":
+			s.checkItems(value, path, keyword, chk)
+		case "allOf", "anyOf", "oneOf":
+			s.checkAlternatives(value, pos, path, keyword, followedRefs, chk)
+		case "not":
+			if sub, ok := keyword.Value.(*yamlmeta.Map); ok {
+				subChk := &validations.Check{}
+				s.check(value, pos, path, sub, followedRefs, subChk)
+				if !subChk.HasInvalidations() {
+					chk.Invalidations = append(chk.Invalidations, s.violation(pos, path, inlineJSON(value), keyword.Position, "a value that does not conform to the schema given to \"not\""))
+				}
+			}
+		}
+	}
+}
+
+func (s *JSONSchema) checkType(value interface{}, pos *filepos.Position, path string, keyword *yamlmeta.MapItem, chk *validations.Check) {
+	var types []string
+	switch typed := keyword.Value.(type) {
+	case *yamlmeta.Array:
+		for _, item := range typed.Items {
+			types = append(types, fmt.Sprintf("%v", item.Value))
+		}
+	default:
+		types = append(types, fmt.Sprintf("%v", typed))
+	}
+	for _, t := range types {
+		if jsonSchemaTypeMatches(t, value) {
+			return
+		}
+	}
+	chk.Invalidations = append(chk.Invalidations, s.violation(pos, path, jsonSchemaTypeName(value), keyword.Position, strings.Join(types, " or ")))
+}
+
+func (s *JSONSchema) checkEnum(value interface{}, pos *filepos.Position, path string, keyword *yamlmeta.MapItem, chk *validations.Check) {
+	enum, ok := keyword.Value.(*yamlmeta.Array)
+	if !ok {
+		return
+	}
+	var allowed []string
+	for _, item := range enum.Items {
+		if inlineJSON(value) == inlineJSON(item.Value) {
+			return
+		}
+		allowed = append(allowed, inlineJSON(item.Value))
+	}
+	chk.Invalidations = append(chk.Invalidations, s.violation(pos, path, inlineJSON(value), keyword.Position, "one of ["+strings.Join(allowed, ", ")+"]"))
+}
+
+// checkBound verifies that "value" is within the numeric bound given by "keyword". In the OpenAPI v3.0 dialect,
+// "exclusiveMinimum" and "exclusiveMaximum" are booleans that make the sibling "minimum" and "maximum" exclusive.
+func (s *JSONSchema) checkBound(value interface{}, pos *filepos.Position, path string, schema *yamlmeta.Map, keyword *yamlmeta.MapItem, chk *validations.Check) {
+	num, isNum := jsonSchemaNumber(value)
+	bound, isBoundNum := jsonSchemaNumber(keyword.Value)
+	if !isNum || !isBoundNum {
+		return
+	}
+	var ok bool
+	var expected string
+	switch keyword.Key {
+	case "minimum":
+		if exclusive, found := jsonSchemaKeyword(schema, "exclusiveMinimum"); found && exclusive.Value == true {
+			ok, expected = num > bound, "a value > "
+		} else {
+			ok, expected = num >= bound, "a value >= "
+		}
+	case "maximum":
+		if exclusive, found := jsonSchemaKeyword(schema, "exclusiveMaximum"); found && exclusive.Value == true {
+			ok, expected = num < bound, "a value < "
+		} else {
+			ok, expected = num <= bound, "a value <= "
+		}
+	case "exclusiveMinimum":
+		ok, expected = num > bound, "a value > "
+	case "exclusiveMaximum":
+		ok, expected = num < bound, "a value < "
+	}
+	if !ok {
+		chk.Invalidations = append(chk.Invalidations, s.violation(pos, path, inlineJSON(value), keyword.Position, expected+inlineJSON(keyword.Value)))
+	}
+}
+
+func (s *JSONSchema) checkLen(length int, what string, pos *filepos.Position, path string, keyword *yamlmeta.MapItem, chk *validations.Check) {
+	bound, ok := jsonSchemaNumber(keyword.Value)
+	if !ok {
+		return
+	}
+	if strings.HasPrefix(fmt.Sprintf("%v", keyword.Key), "min") {
+		if float64(length) < bound {
+			chk.Invalidations = append(chk.Invalidations, s.violation(pos, path, fmt.Sprintf("%s %d", what, length), keyword.Position, fmt.Sprintf("%s >= %v", what, keyword.Value)))
+		}
+		return
+	}
+	if float64(length) > bound {
+		chk.Invalidations = append(chk.Invalidations, s.violation(pos, path, fmt.Sprintf("%s %d", what, length), keyword.Position, fmt.Sprintf("%s <= %v", what, keyword.Value)))
+	}
+}
+
+func (s *JSONSchema) checkPattern(value interface{}, pos *filepos.Position, path string, keyword *yamlmeta.MapItem, chk *validations.Check) {
+	str, ok := value.(string)
+	if !ok {
+		return
+	}
+	pattern := fmt.Sprintf("%v", keyword.Value)
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		chk.Invalidations = append(chk.Invalidations, s.violation(pos, path, fmt.Sprintf("invalid pattern: %s", err), keyword.Position, "a valid regular expression"))
+		return
+	}
+	if !re.MatchString(str) {
+		chk.Invalidations = append(chk.Invalidations, s.violation(pos, path, fmt.Sprintf("%q", str), keyword.Position, fmt.Sprintf("a string matching /%s/", pattern)))
+	}
+}
+
+func (s *JSONSchema) checkRequired(value interface{}, pos *filepos.Position, path string, keyword *yamlmeta.MapItem, chk *validations.Check) {
+	m, isMap := value.(*yamlmeta.Map)
+	required, isArray := keyword.Value.(*yamlmeta.Array)
+	if !isMap || !isArray {
+		return
+	}
+	for _, req := range required.Items {
+		key := fmt.Sprintf("%v", req.Value)
+		if jsonSchemaMapItem(m, key) == nil {
+			chk.Invalidations = append(chk.Invalidations, s.violation(pos, path, fmt.Sprintf("no key named %q", key), keyword.Position, fmt.Sprintf("a map with the key named %q", key)))
+		}
+	}
+}
+
+func (s *JSONSchema) checkProperties(value interface{}, path string, schema *yamlmeta.Map, keyword *yamlmeta.MapItem, chk *validations.Check) {
+	m, isMap := value.(*yamlmeta.Map)
+	properties, isPropsMap := keyword.Value.(*yamlmeta.Map)
+	if !isMap || !isPropsMap {
+		return
+	}
+	addlProps, hasAddlProps := jsonSchemaKeyword(schema, "additionalProperties")
+
+	var declared []string
+	for _, prop := range properties.Items {
+		declared = append(declared, fmt.Sprintf("%v", prop.Key))
+	}
+	sort.Strings(declared)
+
+	for _, item := range m.Items {
+		key := fmt.Sprintf("%v", item.Key)
+		itemPath := key
+		if path != "" {
+			itemPath = path + "." + key
+		}
+		if prop := jsonSchemaMapItem(properties, key); prop != nil {
+			if propSchema, ok := prop.Value.(*yamlmeta.Map); ok {
+				s.check(item.Value, item.GetPosition(), itemPath, propSchema, map[string]bool{}, chk)
+			}
+			continue
+		}
+		if !hasAddlProps {
+			continue
+		}
+		switch typed := addlProps.Value.(type) {
+		case bool:
+			if !typed {
+				chk.Invalidations = append(chk.Invalidations, s.violation(item.GetPosition(), itemPath, key, addlProps.Position, "one of { "+strings.Join(declared, ", ")+" }"))
+			}
+		case *yamlmeta.Map:
+			s.check(item.Value, item.GetPosition(), itemPath, typed, map[string]bool{}, chk)
+		}
+	}
+}
+
+func (s *JSONSchema) checkItems(value interface{}, path string, keyword *yamlmeta.MapItem, chk *validations.Check) {
+	array, isArray := value.(*yamlmeta.Array)
+	itemSchema, isMap := keyword.Value.(*yamlmeta.Map)
+	if !isArray || !isMap {
+		return
+	}
+	for i, item := range array.Items {
+		s.check(item.Value, item.GetPosition(), fmt.Sprintf("%s[%d]", path, i), itemSchema, map[string]bool{}, chk)
+	}
+}
+
+func (s *JSONSchema) checkAlternatives(value interface{}, pos *filepos.Position, path string, keyword *yamlmeta.MapItem, followedRefs map[string]bool, chk *validations.Check) {
+	alternatives, ok := keyword.Value.(*yamlmeta.Array)
+	if !ok {
+		return
+	}
+	var conforming int
+	var violations []validations.Invalidation
+	for _, alt := range alternatives.Items {
+		altSchema, ok := alt.Value.(*yamlmeta.Map)
+		if !ok {
+			continue
+		}
+		altChk := &validations.Check{}
+		s.check(value, pos, path, altSchema, followedRefs, altChk)
+		if altChk.HasInvalidations() {
+			violations = append(violations, altChk.Invalidations...)
+		} else {
+			conforming++
+		}
+	}
+
+	switch keyword.Key {
+	case "allOf":
+		chk.Invalidations = append(chk.Invalidations, violations...)
+	case "anyOf":
+		if conforming == 0 {
+			chk.Invalidations = append(chk.Invalidations, s.violation(pos, path, inlineJSON(value), keyword.Position, "a value that conforms to at least one of the schemas given to \"anyOf\""))
+		}
+	case "oneOf":
+		if conforming != 1 {
+			chk.Invalidations = append(chk.Invalidations, s.violation(pos, path, fmt.Sprintf("%s (conforming to %d of them)", inlineJSON(value), conforming), keyword.Position, "a value that conforms to exactly one of the schemas given to \"oneOf\""))
+		}
+	}
+}
+
+// resolve locates the schema referred to by "ref", which must be a JSON Pointer within this schema (e.g.
+// "#/definitions/port").
+func (s *JSONSchema) resolve(ref string) (*yamlmeta.Map, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("$ref %q refers outside of this schema (only references within it are supported)", ref)
+	}
+	current := s.root
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#"), "/")[1:] {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		item := jsonSchemaMapItem(current, token)
+		if item == nil {
+			return nil, fmt.Errorf("$ref %q refers to nothing", ref)
+		}
+		next, ok := item.Value.(*yamlmeta.Map)
+		if !ok {
+			return nil, fmt.Errorf("$ref %q refers to a %s, not a schema", ref, yamlmeta.TypeName(item.Value))
+		}
+		current = next
+	}
+	return current, nil
+}
+
+func (s *JSONSchema) violation(pos *filepos.Position, path, found string, rulePos *filepos.Position, expected string) validations.Invalidation {
+	if path == "" {
+		path = "(document)"
+	}
+	return validations.Invalidation{
+		Path:        path,
+		ValueSource: pos,
+		Violations:  []validations.Violation{{RuleSource: rulePos, Description: expected, Results: found}},
+	}
+}
+
+func jsonSchemaKeyword(schema *yamlmeta.Map, keyword string) (*yamlmeta.MapItem, bool) {
+	item := jsonSchemaMapItem(schema, keyword)
+	return item, item != nil
+}
+
+func jsonSchemaMapItem(m *yamlmeta.Map, key string) *yamlmeta.MapItem {
+	for _, item := range m.Items {
+		if fmt.Sprintf("%v", item.Key) == key {
+			return item
+		}
+	}
+	return nil
+}
+
+// jsonSchemaTypeMatches reports whether "value" is of the JSON Schema type named "typeName".
+func jsonSchemaTypeMatches(typeName string, value interface{}) bool {
+	switch typeName {
+	case "object":
+		_, ok := value.(*yamlmeta.Map)
+		return ok
+	case "array":
+		_, ok := value.(*yamlmeta.Array)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	case "number":
+		_, ok := jsonSchemaNumber(value)
+		return ok
+	case "integer":
+		num, ok := jsonSchemaNumber(value)
+		return ok && num == math.Trunc(num)
+	}
+	return false
+}
+
+// jsonSchemaTypeName names the JSON Schema type of "value".
+func jsonSchemaTypeName(value interface{}) string {
+	switch value.(type) {
+	case *yamlmeta.Map:
+		return "object"
+	case *yamlmeta.Array:
+		return "array"
+	case float32, float64:
+		return "number"
+	default:
+		return yamlmeta.TypeName(value)
+	}
+}
+
+func jsonSchemaNumber(value interface{}) (float64, bool) {
+	switch typed := value.(type) {
+	case int:
+		return float64(typed), true
+	case int64:
+		return float64(typed), true
+	case uint64:
+		return float64(typed), true
+	case float64:
+		return typed, true
+	}
+	return 0, false
+}
@@ -0,0 +1,167 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/schema"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/validations"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/yamlmeta"
+)
+
+func TestJSONSchema_Check(t *testing.T) {
+	tests := []struct {
+		name       string
+		jsonSchema string
+		value      string
+		// expected are the "must be" of each violation, in order (none when the value conforms).
+		expected []string
+	}{
+		{
+			name:       "enum admits only the values listed",
+			jsonSchema: `{"enum": ["a", 1, null]}`,
+			value:      `b`,
+			expected:   []string{`one of ["a", 1, null]`},
+		},
+		{
+			name:       "enum admits a value listed",
+			jsonSchema: `{"enum": ["a", 1, null]}`,
+			value:      `1`,
+		},
+		{
+			name:       "const admits only the one value",
+			jsonSchema: `{"const": {"a": [1, 2]}}`,
+			value:      `{a: [1, 3]}`,
+			expected:   []string{`{"a":[1,2]}`},
+		},
+		{
+			name:       "const admits an equal value",
+			jsonSchema: `{"const": {"a": [1, 2]}}`,
+			value:      `{a: [1, 2]}`,
+		},
+		{
+			name:       "oneOf admits a value conforming to exactly one schema",
+			jsonSchema: `{"oneOf": [{"type": "integer"}, {"type": "string"}]}`,
+			value:      `1`,
+		},
+		{
+			name:       "oneOf rejects a value conforming to more than one schema",
+			jsonSchema: `{"oneOf": [{"type": "integer"}, {"minimum": 0}]}`,
+			value:      `1`,
+			expected:   []string{`a value that conforms to exactly one of the schemas given to "oneOf"`},
+		},
+		{
+			name:       "anyOf rejects a value conforming to none of the schemas",
+			jsonSchema: `{"anyOf": [{"type": "integer"}, {"type": "string"}]}`,
+			value:      `true`,
+			expected:   []string{`a value that conforms to at least one of the schemas given to "anyOf"`},
+		},
+		{
+			name:       "allOf reports the violations of each schema",
+			jsonSchema: `{"allOf": [{"type": "string"}, {"minimum": 10}]}`,
+			value:      `1`,
+			expected:   []string{`string`, `a value >= 10`},
+		},
+		{
+			name:       "not rejects a value conforming to the schema",
+			jsonSchema: `{"not": {"type": "string"}}`,
+			value:      `a`,
+			expected:   []string{`a value that does not conform to the schema given to "not"`},
+		},
+		{
+			name:       "additionalProperties false rejects undeclared keys",
+			jsonSchema: `{"properties": {"a": {}, "b": {}}, "additionalProperties": false}`,
+			value:      `{a: 1, c: 2}`,
+			expected:   []string{`one of { a, b }`},
+		},
+		{
+			name:       "additionalProperties as a schema checks undeclared keys",
+			jsonSchema: `{"properties": {"a": {}}, "additionalProperties": {"type": "integer"}}`,
+			value:      `{a: x, c: y}`,
+			expected:   []string{`integer`},
+		},
+		{
+			name:       "nullable admits null, regardless of the other keywords",
+			jsonSchema: `{"type": "string", "nullable": true}`,
+			value:      `null`,
+		},
+		{
+			name:       "without nullable, null must conform to the other keywords",
+			jsonSchema: `{"type": "string"}`,
+			value:      `null`,
+			expected:   []string{`string`},
+		},
+		{
+			name:       "a cyclic $ref is followed once per value",
+			jsonSchema: `{"$ref": "#/definitions/node", "definitions": {"node": {"$ref": "#/definitions/node", "type": "object", "properties": {"next": {"$ref": "#/definitions/node"}}}}}`,
+			value:      `{next: {next: 1}}`,
+			expected:   []string{`object`},
+		},
+		{
+			name:       "a $ref to nothing is reported",
+			jsonSchema: `{"$ref": "#/definitions/missing"}`,
+			value:      `1`,
+			expected:   []string{`a resolvable $ref`},
+		},
+		{
+			name:       "an invalid pattern is reported",
+			jsonSchema: `{"pattern": "a("}`,
+			value:      `a`,
+			expected:   []string{`a valid regular expression`},
+		},
+		{
+			name:       "minimum is inclusive",
+			jsonSchema: `{"minimum": 0, "maximum": 10}`,
+			value:      `10`,
+		},
+		{
+			name:       "exclusiveMinimum (as a number, since OpenAPI v3.1) excludes the bound",
+			jsonSchema: `{"exclusiveMinimum": 0}`,
+			value:      `0`,
+			expected:   []string{`a value > 0`},
+		},
+		{
+			name:       "exclusiveMinimum (as a boolean, in OpenAPI v3.0) makes minimum exclusive",
+			jsonSchema: `{"minimum": 0, "exclusiveMinimum": true}`,
+			value:      `0`,
+			expected:   []string{`a value > 0`},
+		},
+		{
+			name:       "exclusiveMaximum (as a boolean, in OpenAPI v3.0) makes maximum exclusive",
+			jsonSchema: `{"maximum": 10, "exclusiveMaximum": true}`,
+			value:      `10`,
+			expected:   []string{`a value < 10`},
+		},
+		{
+			name:       "exclusiveMaximum false leaves maximum inclusive",
+			jsonSchema: `{"maximum": 10, "exclusiveMaximum": false}`,
+			value:      `10`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jsonSchema, err := schema.NewJSONSchema(parseYAML(t, test.jsonSchema).Value)
+			require.NoError(t, err)
+
+			chk := jsonSchema.Check(parseYAML(t, test.value))
+
+			var mustBe []string
+			for _, inval := range chk.Invalidations {
+				for _, viol := range inval.Violations {
+					mustBe = append(mustBe, viol.Description)
+				}
+			}
+			assert.Equal(t, test.expected, mustBe, "\n%s", validations.CheckError{Check: chk})
+		})
+	}
+}
+
+func parseYAML(t *testing.T, yaml string) *yamlmeta.Document {
+	docSet, err := yamlmeta.NewDocumentSetFromBytes([]byte(yaml), yamlmeta.DocSetOpts{})
+	require.NoError(t, err)
+	return docSet.Items[0]
+}
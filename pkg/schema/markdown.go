@@ -0,0 +1,161 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/vmware-tanzu/carvel-ytt/pkg/yamlmeta"
+)
+
+// MarkdownDocument describes data values as a Markdown table (e.g. for inclusion in a README), one row per value.
+//
+// It is rendered from the same description of each value as is the OpenAPIDocument it wraps (and so respects how
+// that document is configured).
+type MarkdownDocument struct {
+	openAPIDoc *OpenAPIDocument
+}
+
+// notConstraints are the properties of a value (in its OpenAPI description) that are rendered in columns of their
+// own, are rendered as rows of their own, or are otherwise not worth noting as a constraint.
+var notConstraints = map[string]bool{
+	titleProp:              true,
+	typeProp:               true,
+	additionalPropsProp:    true,
+	formatProp:             true,
+	nullableProp:           true,
+	deprecatedProp:         true,
+	descriptionProp:        true,
+	exampleDescriptionProp: true,
+	exampleProp:            true,
+	itemsProp:              true,
+	propertiesProp:         true,
+	ifProp:                 true,
+	thenProp:               true,
+	elseProp:               true,
+	defaultProp:            true,
+	oneOfProp:              true,
+	anyOfProp:              true,
+	allOfProp:              true,
+}
+
+// NewMarkdownDocument creates an instance of a MarkdownDocument that renders the values described by "openAPIDoc".
+func NewMarkdownDocument(openAPIDoc *OpenAPIDocument) *MarkdownDocument {
+	return &MarkdownDocument{openAPIDoc: openAPIDoc}
+}
+
+// AsBytes renders this document as Markdown.
+func (m *MarkdownDocument) AsBytes() []byte {
+	var md bytes.Buffer
+	md.WriteString("| Path | Type | Default | Description | Constraints |\n")
+	md.WriteString("|------|------|---------|-------------|-------------|\n")
+
+	root := m.openAPIDoc.calculateProperties(m.openAPIDoc.docType)
+	withoutNullDefaults(root)
+	m.writeChildren(&md, "", root)
+	return md.Bytes()
+}
+
+// writeChildren renders a row for each of the values within the value described by "properties" (found at "path").
+func (m *MarkdownDocument) writeChildren(md *bytes.Buffer, path string, properties *yamlmeta.Map) {
+	for _, prop := range properties.Items {
+		switch prop.Key {
+		case propertiesProp:
+			for _, item := range prop.Value.(*yamlmeta.Map).Items {
+				childPath := fmt.Sprintf("%v", item.Key)
+				if path != "" {
+					childPath = path + "." + childPath
+				}
+				m.writeRow(md, childPath, item.Value.(*yamlmeta.Map))
+			}
+		case itemsProp:
+			m.writeRow(md, path+"[]", prop.Value.(*yamlmeta.Map))
+		}
+	}
+}
+
+func (m *MarkdownDocument) writeRow(md *bytes.Buffer, path string, properties *yamlmeta.Map) {
+	var description, typeName, format, defaultVal string
+	var nullable, deprecated bool
+	var alternatives, constraints []string
+
+	for _, prop := range properties.Items {
+		key := fmt.Sprintf("%v", prop.Key)
+		switch key {
+		case descriptionProp:
+			description = fmt.Sprintf("%v", prop.Value)
+		case typeProp:
+			typeName = markdownTypeName(prop.Value)
+		case formatProp:
+			format = fmt.Sprintf("%v", prop.Value)
+		case nullableProp:
+			nullable = prop.Value == true
+		case deprecatedProp:
+			deprecated = prop.Value == true
+		case defaultProp:
+			defaultVal = "`" + inlineJSON(prop.Value) + "`"
+		case oneOfProp, anyOfProp, allOfProp:
+			for _, alt := range prop.Value.(*yamlmeta.Array).Items {
+				for _, altProp := range alt.Value.(*yamlmeta.Map).Items {
+					if altProp.Key == titleProp {
+						alternatives = append(alternatives, fmt.Sprintf("%v", altProp.Value))
+					}
+				}
+			}
+			typeName = key + ": " + strings.Join(alternatives, ", ")
+		}
+		if !notConstraints[key] {
+			constraints = append(constraints, fmt.Sprintf("%s: `%s`", key, inlineJSON(prop.Value)))
+		}
+	}
+
+	if typeName == "" {
+		typeName = "any"
+	}
+	if format != "" {
+		typeName += " (" + format + ")"
+	}
+	if nullable {
+		typeName += ", nullable"
+	}
+	if deprecated {
+		description = strings.TrimSpace("**Deprecated.** " + description)
+	}
+
+	fmt.Fprintf(md, "| `%s` | %s | %s | %s | %s |\n", path, markdownCell(typeName), markdownCell(defaultVal),
+		markdownCell(description), markdownCell(strings.Join(constraints, ", ")))
+
+	m.writeChildren(md, path, properties)
+}
+
+// markdownTypeName names the OpenAPI type "value" (which, as of OpenAPI v3.1, can be a list of types).
+func markdownTypeName(value interface{}) string {
+	types, isList := value.(*yamlmeta.Array)
+	if !isList {
+		return fmt.Sprintf("%v", value)
+	}
+	var names []string
+	for _, t := range types.Items {
+		names = append(names, fmt.Sprintf("%v", t.Value))
+	}
+	return strings.Join(names, " | ")
+}
+
+// inlineJSON renders "value" as (single-line) JSON.
+func inlineJSON(value interface{}) string {
+	var buf bytes.Buffer
+	err := yamlmeta.NewJSONPrinter(&buf).Print(&yamlmeta.Document{Value: value})
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return buf.String()
+}
+
+// markdownCell escapes "text" so that it fits within a single cell of a Markdown table.
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", "\\|")
+	return strings.ReplaceAll(strings.TrimSpace(text), "\n", "<br>")
+}
@@ -5,9 +5,20 @@ package schema
 
 import (
 	"fmt"
+	"regexp"
+	"runtime"
 	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/template/core"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/validations"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/yamlmeta"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/yttlibrary"
 )
 
 // keys used when generating an OpenAPI Document
@@ -20,25 +31,73 @@ const (
 	deprecatedProp         = "deprecated"
 	descriptionProp        = "description"
 	exampleDescriptionProp = "x-example-description"
+	unitProp               = "x-unit"
 	exampleProp            = "example"
 	itemsProp              = "items"
 	propertiesProp         = "properties"
+	minPropertiesProp      = "minProperties"
+	maxPropertiesProp      = "maxProperties"
+	ifProp                 = "if"
+	thenProp               = "then"
+	elseProp               = "else"
 	defaultProp            = "default"
+	enumProp               = "enum"
+	requiredProp           = "required"
+	notProp                = "not"
+	minLengthProp          = "minLength"
+	maxLengthProp          = "maxLength"
+	minimumProp            = "minimum"
+	maximumProp            = "maximum"
+	exclusiveMinimumProp   = "exclusiveMinimum"
+	exclusiveMaximumProp   = "exclusiveMaximum"
+	oneOfProp              = string(CompositionOneOf)
+	anyOfProp              = string(CompositionAnyOf)
+	allOfProp              = string(CompositionAllOf)
+	validationsExtProp     = "x-ytt-validations"
+	orderExtProp           = "x-order"
+	patternProp            = "pattern"
+	refProp                = "$ref"
+	discriminatorProp      = "discriminator"
 )
 
+// nullTypeName is the name of the type of null in OpenAPI v3.1 (and JSON Schema).
+const nullTypeName = "null"
+
 var propOrder = map[string]int{
 	titleProp:              0,
 	typeProp:               1,
 	additionalPropsProp:    2,
 	formatProp:             3,
-	nullableProp:           4,
-	deprecatedProp:         5,
-	descriptionProp:        6,
-	exampleDescriptionProp: 7,
-	exampleProp:            8,
-	itemsProp:              9,
-	propertiesProp:         10,
-	defaultProp:            11,
+	unitProp:               4,
+	nullableProp:           5,
+	deprecatedProp:         6,
+	descriptionProp:        7,
+	exampleDescriptionProp: 8,
+	exampleProp:            9,
+	itemsProp:              10,
+	propertiesProp:         11,
+	minPropertiesProp:      12,
+	maxPropertiesProp:      13,
+	requiredProp:           14,
+	ifProp:                 15,
+	thenProp:               16,
+	elseProp:               17,
+	defaultProp:            18,
+	enumProp:               19,
+	minimumProp:            20,
+	exclusiveMinimumProp:   21,
+	maximumProp:            22,
+	exclusiveMaximumProp:   23,
+	minLengthProp:          24,
+	maxLengthProp:          25,
+	patternProp:            26,
+	notProp:                27,
+	oneOfProp:              28,
+	anyOfProp:              29,
+	allOfProp:              30,
+	discriminatorProp:      31,
+	validationsExtProp:     32,
+	orderExtProp:           33,
 }
 
 type openAPIKeys []*yamlmeta.MapItem
@@ -55,52 +114,386 @@ func (o openAPIKeys) Swap(i, j int) {
 	o[i], o[j] = o[j], o[i]
 }
 
+// DefaultOpenAPISchemaName is the name under which the schema of data values is given in `components/schemas` (unless
+// configured otherwise, see WithSchemaName()).
+const DefaultOpenAPISchemaName = "dataValues"
+
+// openAPIComponentName matches the names allowed for components (e.g. in `components/schemas`) by OpenAPI.
+var openAPIComponentName = regexp.MustCompile(`^[a-zA-Z0-9.\-_]+$`)
+
+// Versions of the OpenAPI specification to which an OpenAPIDocument can conform
+const (
+	OpenAPIVersion30 = "3.0.0"
+	OpenAPIVersion31 = "3.1.0"
+)
+
 // OpenAPIDocument holds the document type used for creating an OpenAPI document
 type OpenAPIDocument struct {
-	docType *DocumentType
+	docType              *DocumentType
+	version              string
+	schemaName           string
+	inferStringMaxLength bool
+	validationExtensions bool
+	additionalProperties bool
+	commentDescriptions  LeadingComments
+	sortProperties       bool
+	excludeDeprecated    bool
+	defaultsAsExamples   bool
+	stripExtensions      bool
+	titlesFromKeys       bool
+	asParameters         bool
+	omitEmptyPaths       bool
+	propertyOrder        bool
+	parallelism          int // how many top-level values to describe at once (when 0, as many as there are CPUs)
 }
 
-// NewOpenAPIDocument creates an instance of an OpenAPIDocument based on the given DocumentType
+// NewOpenAPIDocument creates an instance of an OpenAPIDocument (conforming to OpenAPI v3.0.x) based on the given
+// DocumentType
 func NewOpenAPIDocument(docType *DocumentType) *OpenAPIDocument {
-	return &OpenAPIDocument{docType}
+	return &OpenAPIDocument{docType: docType, version: OpenAPIVersion30, schemaName: DefaultOpenAPISchemaName}
+}
+
+// WithVersion sets the version of the OpenAPI specification to which this document conforms.
+func (o *OpenAPIDocument) WithVersion(version string) *OpenAPIDocument {
+	o.version = version
+	return o
+}
+
+// WithSchemaName gives the schema of data values under "name" in `components/schemas` (rather than
+// DefaultOpenAPISchemaName), so that schemas generated for several sets of data values can be merged into one
+// document. Reports an error if "name" is not one that OpenAPI allows for a component.
+func (o *OpenAPIDocument) WithSchemaName(name string) (*OpenAPIDocument, error) {
+	if !openAPIComponentName.MatchString(name) {
+		return nil, fmt.Errorf("Expected schema name to consist of letters, digits, '.', '-' and '_' (as OpenAPI requires of component names), but was %q", name)
+	}
+	o.schemaName = name
+	return o, nil
+}
+
+// WithInferredStringMaxLength includes, for each string that has no length constraint, its default's length as its
+// maxLength (as a starting point for authors wanting to limit such lengths).
+func (o *OpenAPIDocument) WithInferredStringMaxLength() *OpenAPIDocument {
+	o.inferStringMaxLength = true
+	return o
+}
+
+// WithValidationExtensions includes, for each value, the validation rules that have no equivalent OpenAPI keyword
+// (e.g. sorted=) as the extension "x-ytt-validations".
+func (o *OpenAPIDocument) WithValidationExtensions() *OpenAPIDocument {
+	o.validationExtensions = true
+	return o
+}
+
+// WithAdditionalProperties describes every object as allowing properties beyond those declared in schema (for
+// consumers that reject `additionalProperties: false`).
+func (o *OpenAPIDocument) WithAdditionalProperties() *OpenAPIDocument {
+	o.additionalProperties = true
+	return o
+}
+
+// WithDescriptionsFromComments describes each value that has no description (via @schema/desc) with the comments
+// preceding it in schema, if any.
+func (o *OpenAPIDocument) WithDescriptionsFromComments(comments LeadingComments) *OpenAPIDocument {
+	o.commentDescriptions = comments
+	return o
+}
+
+// WithSortedProperties lists the properties of each object in alphabetical order of their keys (rather than in the
+// order they are declared in schema).
+func (o *OpenAPIDocument) WithSortedProperties() *OpenAPIDocument {
+	o.sortProperties = true
+	return o
+}
+
+// WithoutDeprecated omits the values marked deprecated (via @schema/deprecated), rather than describing them as
+// `deprecated: true`.
+func (o *OpenAPIDocument) WithoutDeprecated() *OpenAPIDocument {
+	o.excludeDeprecated = true
+	return o
+}
+
+// WithDefaultsAsExamples describes the default of each value as its `example` (unless it has one, via
+// @schema/examples), omitting `default` altogether (for consumers that reject defaults in component schemas).
+func (o *OpenAPIDocument) WithDefaultsAsExamples() *OpenAPIDocument {
+	o.defaultsAsExamples = true
+	return o
+}
+
+// WithoutExtensions omits every extension (e.g. "x-example-description"), for consumers that reject them.
+func (o *OpenAPIDocument) WithoutExtensions() *OpenAPIDocument {
+	o.stripExtensions = true
+	return o
+}
+
+// WithTitlesFromKeys titles each property that has no title (via @schema/title) after its key, made readable (e.g.
+// "db_conn" is titled "Db Conn").
+func (o *OpenAPIDocument) WithTitlesFromKeys() *OpenAPIDocument {
+	o.titlesFromKeys = true
+	return o
+}
+
+// WithParameters describes each top-level data value that is a scalar as a (query) parameter, in
+// `components/parameters`, rather than as a property of the schema of data values (which, then, describes only the
+// remaining values, if any).
+func (o *OpenAPIDocument) WithParameters() *OpenAPIDocument {
+	o.asParameters = true
+	return o
+}
+
+// WithoutEmptyPaths omits `paths` (rather than giving it as an empty map), as some tools reject empty paths in a
+// document that only has components.
+func (o *OpenAPIDocument) WithoutEmptyPaths() *OpenAPIDocument {
+	o.omitEmptyPaths = true
+	return o
 }
 
-// AsDocument generates a new AST of this OpenAPI v3.0.x document, populating the `schemas:` section with the
+// WithPropertyOrder gives each property its position (from 0) among those of its object, in the `x-order` extension,
+// so that schema order can be recovered by consumers that list properties alphabetically.
+func (o *OpenAPIDocument) WithPropertyOrder() *OpenAPIDocument {
+	o.propertyOrder = true
+	return o
+}
+
+// WithParallelism describes (up to) "workers" top-level data values at once; 1 describes them one after another.
+// By default, as many are described at once as there are CPUs. Either way, properties are listed in schema order.
+func (o *OpenAPIDocument) WithParallelism(workers int) *OpenAPIDocument {
+	o.parallelism = workers
+	return o
+}
+
+// AsDocument generates a new AST of this OpenAPI document, populating the `schemas:` section with the
 // type information contained in `docType`.
 func (o *OpenAPIDocument) AsDocument() *yamlmeta.Document {
+	return o.asDocument(o.calculateDataValuesProperties())
+}
+
+// AsDocumentAt generates just the part of this OpenAPI document — relative to the schema of data values — found at the
+// JSON Pointer "pointer" (e.g. "/properties/db/properties/port" is the schema of the data value `db.port`).
+func (o *OpenAPIDocument) AsDocumentAt(pointer string) (*yamlmeta.Document, error) {
+	if pointer != "" && !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("Expected JSON Pointer '%s' to start with '/'", pointer)
+	}
+	var current interface{} = o.calculateDataValuesProperties()
+	resolved := ""
+	for _, token := range strings.Split(pointer, "/")[1:] {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		next, found := pointerChild(current, token)
+		if !found {
+			// report the closest part of the schema that does exist.
+			closest := "the schema of data values"
+			if resolved != "" {
+				closest = fmt.Sprintf("'%s'", resolved)
+			}
+			msg := fmt.Sprintf("Expected JSON Pointer '%s' to refer to a part of the schema, but %s has nothing at '%s'", pointer, closest, token)
+			if keys := pointerKeys(current); len(keys) > 0 {
+				msg += fmt.Sprintf(" (it has: %s)", strings.Join(keys, ", "))
+			}
+			return nil, fmt.Errorf("%s", msg)
+		}
+		current = next
+		resolved += "/" + strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+	}
+	return &yamlmeta.Document{Value: current}, nil
+}
+
+// pointerChild is the value within "node" (a map or an array) referred to by the JSON Pointer reference "token".
+func pointerChild(node interface{}, token string) (interface{}, bool) {
+	switch typedNode := node.(type) {
+	case *yamlmeta.Map:
+		for _, item := range typedNode.Items {
+			if fmt.Sprintf("%v", item.Key) == token {
+				return item.Value, true
+			}
+		}
+	case *yamlmeta.Array:
+		idx, err := strconv.Atoi(token)
+		if err == nil && idx >= 0 && idx < len(typedNode.Items) {
+			return typedNode.Items[idx].Value, true
+		}
+	}
+	return nil, false
+}
+
+func pointerKeys(node interface{}) []string {
+	var keys []string
+	if typedNode, isMap := node.(*yamlmeta.Map); isMap {
+		for _, item := range typedNode.Items {
+			keys = append(keys, fmt.Sprintf("%v", item.Key))
+		}
+	}
+	return keys
+}
+
+// OpenAPIFile is one of the files making up an OpenAPI document that is split across several (see AsSplitDocuments()).
+type OpenAPIFile struct {
+	RelativePath string
+	Document     *yamlmeta.Document
+}
+
+// AsSplitDocuments generates this OpenAPI document as several files: "openapi.yaml" — in which each of the top-level
+// data values is a reference (`$ref`) — and, for each such value, a file (in "schemas/") describing it.
+//
+// A deprecated value remains so where it is referenced, too (i.e. its reference is wrapped in an `allOf`, since
+// keywords alongside a `$ref` are ignored).
+func (o *OpenAPIDocument) AsSplitDocuments() []OpenAPIFile {
+	openAPIProperties := o.calculateDataValuesProperties()
+
+	var components []OpenAPIFile
+	for _, prop := range openAPIProperties.Items {
+		if prop.Key != propertiesProp {
+			continue
+		}
+		for _, value := range prop.Value.(*yamlmeta.Map).Items {
+			relativePath := fmt.Sprintf("schemas/%s.yaml", strings.ReplaceAll(fmt.Sprintf("%v", value.Key), "/", "_"))
+			components = append(components, OpenAPIFile{RelativePath: relativePath, Document: &yamlmeta.Document{Value: value.Value}})
+			ref := &yamlmeta.Map{Items: []*yamlmeta.MapItem{{Key: refProp, Value: relativePath}}}
+			if isDeprecated(value.Value.(*yamlmeta.Map)) {
+				ref = &yamlmeta.Map{Items: []*yamlmeta.MapItem{
+					{Key: allOfProp, Value: &yamlmeta.Array{Items: []*yamlmeta.ArrayItem{{Value: ref}}}},
+					{Key: deprecatedProp, Value: true},
+				}}
+			}
+			value.Value = ref
+		}
+	}
+	return append([]OpenAPIFile{{RelativePath: "openapi.yaml", Document: o.asDocument(openAPIProperties)}}, components...)
+}
+
+func (o *OpenAPIDocument) calculateDataValuesProperties() *yamlmeta.Map {
 	openAPIProperties := o.calculateProperties(o.docType)
+	withoutNullDefaults(openAPIProperties)
+	if o.defaultsAsExamples {
+		defaultsAsExamples(openAPIProperties)
+	}
+	if o.stripExtensions {
+		withoutExtensions(openAPIProperties)
+	}
+	return openAPIProperties
+}
 
-	return &yamlmeta.Document{Value: &yamlmeta.Map{Items: []*yamlmeta.MapItem{
-		{Key: "openapi", Value: "3.0.0"},
+func (o *OpenAPIDocument) asDocument(openAPIProperties *yamlmeta.Map) *yamlmeta.Document {
+	components := &yamlmeta.Map{Items: []*yamlmeta.MapItem{
+		{Key: "schemas", Value: &yamlmeta.Map{Items: []*yamlmeta.MapItem{
+			{Key: o.schemaName, Value: openAPIProperties},
+		}}},
+	}}
+	if o.asParameters {
+		components = asParameters(openAPIProperties, o.schemaName)
+	}
+	items := []*yamlmeta.MapItem{
+		{Key: "openapi", Value: o.version},
 		{Key: "info", Value: &yamlmeta.Map{Items: []*yamlmeta.MapItem{
 			{Key: "version", Value: "0.1.0"},
 			{Key: titleProp, Value: "Schema for data values, generated by ytt"},
 		}}},
-		{Key: "paths", Value: &yamlmeta.Map{}},
-		{Key: "components", Value: &yamlmeta.Map{Items: []*yamlmeta.MapItem{
-			{Key: "schemas", Value: &yamlmeta.Map{Items: []*yamlmeta.MapItem{
-				{Key: "dataValues", Value: openAPIProperties},
-			}}},
-		}}},
-	}}}
+	}
+	if !o.omitEmptyPaths {
+		items = append(items, &yamlmeta.MapItem{Key: "paths", Value: &yamlmeta.Map{}})
+	}
+	items = append(items, &yamlmeta.MapItem{Key: "components", Value: components})
+	return &yamlmeta.Document{Value: &yamlmeta.Map{Items: items}}
+}
+
+// asParameters produces the components describing the schema of data values "openAPIProperties": each top-level value
+// that is a scalar as a (query) parameter, in `parameters`; the others as the schema of data values (named
+// "schemaName"), in `schemas`.
+func asParameters(openAPIProperties *yamlmeta.Map, schemaName string) *yamlmeta.Map {
+	var dataValues, parameters []*yamlmeta.MapItem
+	for _, prop := range openAPIProperties.Items {
+		if prop.Key != propertiesProp {
+			dataValues = append(dataValues, prop)
+			continue
+		}
+		var remaining []*yamlmeta.MapItem
+		for _, value := range prop.Value.(*yamlmeta.Map).Items {
+			valueSchema := value.Value.(*yamlmeta.Map)
+			if hasProp(valueSchema, propertiesProp) || hasProp(valueSchema, itemsProp) {
+				remaining = append(remaining, value)
+				continue
+			}
+			parameters = append(parameters, &yamlmeta.MapItem{Key: value.Key, Value: asParameter(value.Key, valueSchema)})
+		}
+		if len(remaining) == 0 {
+			// every value is a parameter: there is nothing left to describe as schema.
+			return &yamlmeta.Map{Items: []*yamlmeta.MapItem{{Key: "parameters", Value: &yamlmeta.Map{Items: parameters}}}}
+		}
+		dataValues = append(dataValues, &yamlmeta.MapItem{Key: propertiesProp, Value: &yamlmeta.Map{Items: remaining}})
+	}
+
+	var components []*yamlmeta.MapItem
+	if len(parameters) > 0 {
+		components = append(components, &yamlmeta.MapItem{Key: "parameters", Value: &yamlmeta.Map{Items: parameters}})
+	}
+	components = append(components, &yamlmeta.MapItem{Key: "schemas", Value: &yamlmeta.Map{Items: []*yamlmeta.MapItem{
+		{Key: schemaName, Value: &yamlmeta.Map{Items: dataValues}},
+	}}})
+	return &yamlmeta.Map{Items: components}
+}
+
+// asParameter describes the value named "key" (whose schema is "valueSchema") as a query parameter: its description
+// (and deprecation) are those of the parameter; the rest, of its schema.
+func asParameter(key interface{}, valueSchema *yamlmeta.Map) *yamlmeta.Map {
+	parameter := []*yamlmeta.MapItem{{Key: "name", Value: key}, {Key: "in", Value: "query"}}
+	schema := &yamlmeta.Map{}
+	for _, prop := range valueSchema.Items {
+		switch prop.Key {
+		case descriptionProp, deprecatedProp:
+			parameter = append(parameter, prop)
+		default:
+			schema.Items = append(schema.Items, prop)
+		}
+	}
+	return &yamlmeta.Map{Items: append(parameter, &yamlmeta.MapItem{Key: "schema", Value: schema})}
 }
 
 func (o *OpenAPIDocument) calculateProperties(schemaVal interface{}) *yamlmeta.Map {
 	switch typedValue := schemaVal.(type) {
 	case *DocumentType:
-		return o.calculateProperties(typedValue.GetValueType())
+		properties := o.calculateValueProperties(typedValue.GetValueType(), typedValue.GetValidation())
+		return o.withCommentDescription(properties, typedValue)
 	case *MapType:
 		var items openAPIKeys
 		items = append(items, collectDocumentation(typedValue)...)
 		items = append(items, &yamlmeta.MapItem{Key: typeProp, Value: "object"})
-		items = append(items, &yamlmeta.MapItem{Key: additionalPropsProp, Value: false})
+		items = append(items, &yamlmeta.MapItem{Key: additionalPropsProp, Value: o.additionalProperties})
 
-		var properties []*yamlmeta.MapItem
+		var mapItems []*MapItemType
 		for _, i := range typedValue.Items {
-			mi := yamlmeta.MapItem{Key: i.Key, Value: o.calculateProperties(i.GetValueType())}
-			properties = append(properties, &mi)
+			if isDeprecated, _ := i.GetValueType().IsDeprecated(); isDeprecated && o.excludeDeprecated {
+				continue
+			}
+			mapItems = append(mapItems, i)
+		}
+		var properties []*yamlmeta.MapItem
+		if o.docType != nil && typedValue == o.docType.GetValueType() {
+			properties = o.calculateItemsProperties(mapItems)
+		} else {
+			for _, i := range mapItems {
+				properties = append(properties, &yamlmeta.MapItem{Key: i.Key, Value: o.calculateProperties(i)})
+			}
+		}
+		if o.propertyOrder {
+			for idx, prop := range properties {
+				prop.Value = withOrder(prop.Value.(*yamlmeta.Map), idx)
+			}
+		}
+		if o.sortProperties {
+			sort.SliceStable(properties, func(i, j int) bool {
+				return fmt.Sprintf("%v", properties[i].Key) < fmt.Sprintf("%v", properties[j].Key)
+			})
 		}
 		items = append(items, &yamlmeta.MapItem{Key: propertiesProp, Value: &yamlmeta.Map{Items: properties}})
+		if typedValue.minProperties != nil {
+			items = append(items, &yamlmeta.MapItem{Key: minPropertiesProp, Value: *typedValue.minProperties})
+		}
+		if typedValue.maxProperties != nil {
+			items = append(items, &yamlmeta.MapItem{Key: maxPropertiesProp, Value: *typedValue.maxProperties})
+		}
+		items = append(items, o.convertRequiredIf(typedValue.requiredIf)...)
+		if len(typedValue.Items) == 0 {
+			// with no properties to give it a default, an empty map defaults to itself (much like an array).
+			items = append(items, &yamlmeta.MapItem{Key: defaultProp, Value: &yamlmeta.Map{}})
+		}
 
 		sort.Sort(items)
 		return &yamlmeta.Map{Items: items}
@@ -111,40 +504,106 @@ func (o *OpenAPIDocument) calculateProperties(schemaVal interface{}) *yamlmeta.M
 		items = append(items, &yamlmeta.MapItem{Key: defaultProp, Value: typedValue.GetDefaultValue()})
 
 		valueType := typedValue.GetValueType().(*ArrayItemType)
-		properties := o.calculateProperties(valueType.GetValueType())
+		properties := o.calculateProperties(valueType)
 		items = append(items, &yamlmeta.MapItem{Key: itemsProp, Value: properties})
 
 		sort.Sort(items)
 		return &yamlmeta.Map{Items: items}
+	case *MapItemType:
+		properties := o.calculateValueProperties(typedValue.GetValueType(), typedValue.GetValidation())
+		if _, isNullable := typedValue.GetValueType().(*NullType); isNullable {
+			// a nullable value defaults to null, unless data values (e.g. via --data-values-file) say otherwise.
+			properties = withDefault(properties, typedValue.defaultValue)
+		}
+		return o.withKeyTitle(o.withCommentDescription(properties, typedValue), typedValue.Key)
+	case *ArrayItemType:
+		properties := o.calculateValueProperties(typedValue.GetValueType(), typedValue.GetValidation())
+		return o.withCommentDescription(properties, typedValue)
 	case *ScalarType:
 		var items openAPIKeys
 		items = append(items, collectDocumentation(typedValue)...)
 		items = append(items, &yamlmeta.MapItem{Key: defaultProp, Value: typedValue.GetDefaultValue()})
 
+		if typedValue.ValueType == NullValueType {
+			items = append(items, o.nullOnly()...)
+			sort.Sort(items)
+			return &yamlmeta.Map{Items: items}
+		}
 		typeString := o.openAPITypeFor(typedValue)
 		items = append(items, &yamlmeta.MapItem{Key: typeProp, Value: typeString})
 		if typedValue.String() == "float" {
 			items = append(items, &yamlmeta.MapItem{Key: formatProp, Value: "float"})
 		}
+		if typedValue.format != "" {
+			items = append(items, &yamlmeta.MapItem{Key: formatProp, Value: typedValue.format})
+		}
+		if typedValue.unit != "" {
+			items = append(items, &yamlmeta.MapItem{Key: unitProp, Value: typedValue.unit})
+		}
 
 		sort.Sort(items)
 		return &yamlmeta.Map{Items: items}
 	case *NullType:
 		var items openAPIKeys
 		items = append(items, collectDocumentation(typedValue)...)
-		items = append(items, &yamlmeta.MapItem{Key: nullableProp, Value: true})
 
 		properties := o.calculateProperties(typedValue.GetValueType())
+		if _, isMap := typedValue.GetValueType().(*MapType); isMap {
+			// a nullable map defaults to null, even when empty.
+			properties = withoutProp(properties, defaultProp)
+		}
+		if o.version == OpenAPIVersion31 {
+			// as of OpenAPI v3.1, null is a type in its own right (i.e. "nullable" was removed)
+			for _, prop := range properties.Items {
+				if prop.Key == typeProp && prop.Value != nullTypeName {
+					prop.Value = &yamlmeta.Array{Items: []*yamlmeta.ArrayItem{{Value: prop.Value}, {Value: "null"}}}
+				}
+			}
+		} else {
+			items = append(items, &yamlmeta.MapItem{Key: nullableProp, Value: true})
+		}
 		items = append(items, properties.Items...)
+		if !hasProp(properties, defaultProp) {
+			// objects have no default of their own; a nullable one, though, defaults to null.
+			items = append(items, &yamlmeta.MapItem{Key: defaultProp, Value: nil})
+		}
 
 		sort.Sort(items)
 		return &yamlmeta.Map{Items: items}
 	case *AnyType:
 		var items openAPIKeys
 		items = append(items, collectDocumentation(typedValue)...)
-		items = append(items, &yamlmeta.MapItem{Key: nullableProp, Value: true})
+		if o.version != OpenAPIVersion31 {
+			// in OpenAPI v3.1, a schema without a "type" already admits null
+			items = append(items, &yamlmeta.MapItem{Key: nullableProp, Value: true})
+		}
 		items = append(items, &yamlmeta.MapItem{Key: defaultProp, Value: typedValue.GetDefaultValue()})
 
+		sort.Sort(items)
+		return &yamlmeta.Map{Items: items}
+	case *CompositeType:
+		var items openAPIKeys
+		items = append(items, collectDocumentation(typedValue)...)
+		items = append(items, &yamlmeta.MapItem{Key: defaultProp, Value: typedValue.GetDefaultValue()})
+
+		alternatives := &yamlmeta.Array{}
+		for _, alt := range typedValue.Alternatives {
+			properties := o.calculateProperties(alt.Type)
+			if alt.Type.GetTitle() == "" {
+				// name the alternative so that it can be identified
+				properties.Items = append([]*yamlmeta.MapItem{{Key: titleProp, Value: alt.Name}}, properties.Items...)
+			}
+			alternatives.Items = append(alternatives.Items, &yamlmeta.ArrayItem{Value: properties})
+		}
+		items = append(items, &yamlmeta.MapItem{Key: string(typedValue.Composition), Value: alternatives})
+		if discriminator := typedValue.discriminator; discriminator != nil {
+			discriminatorItems := []*yamlmeta.MapItem{{Key: "propertyName", Value: discriminator.propertyName}}
+			if len(discriminator.mapping) > 0 {
+				discriminatorItems = append(discriminatorItems, &yamlmeta.MapItem{Key: "mapping", Value: &yamlmeta.Map{Items: discriminator.mapping}})
+			}
+			items = append(items, &yamlmeta.MapItem{Key: discriminatorProp, Value: &yamlmeta.Map{Items: discriminatorItems}})
+		}
+
 		sort.Sort(items)
 		return &yamlmeta.Map{Items: items}
 	default:
@@ -152,6 +611,540 @@ func (o *OpenAPIDocument) calculateProperties(schemaVal interface{}) *yamlmeta.M
 	}
 }
 
+// calculateItemsProperties describes each of the top-level "items" (concurrently, see WithParallelism()), listing them
+// in the order given.
+func (o *OpenAPIDocument) calculateItemsProperties(items []*MapItemType) []*yamlmeta.MapItem {
+	workers := o.parallelism
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	properties := make([]*yamlmeta.MapItem, len(items))
+
+	var wg sync.WaitGroup
+	next := make(chan int)
+	for w := 0; w < workers && w < len(items); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range next {
+				// each worker writes only to its own indices: no further synchronization is needed.
+				properties[idx] = &yamlmeta.MapItem{Key: items[idx].Key, Value: o.calculateProperties(items[idx])}
+			}
+		}()
+	}
+	for idx := range items {
+		next <- idx
+	}
+	close(next)
+	wg.Wait()
+
+	return properties
+}
+
+// defaultsAsExamples replaces the `default` of the schema "properties" — and of each schema within it — with an
+// `example` (unless it already has one).
+func defaultsAsExamples(properties *yamlmeta.Map) {
+	forEachSchema(properties, func(schema *yamlmeta.Map) {
+		var items openAPIKeys
+		for _, prop := range schema.Items {
+			if prop.Key == defaultProp {
+				if !hasProp(schema, exampleProp) {
+					items = append(items, &yamlmeta.MapItem{Key: exampleProp, Value: prop.Value})
+				}
+				continue
+			}
+			items = append(items, prop)
+		}
+		sort.Sort(items)
+		schema.Items = items
+	})
+}
+
+// withoutNullDefaults removes `default: null` from each schema (within "properties") that does not admit null: only a
+// nullable value may be described as defaulting to null.
+func withoutNullDefaults(properties *yamlmeta.Map) {
+	forEachSchema(properties, func(schema *yamlmeta.Map) {
+		if admitsNull(schema) {
+			return
+		}
+		var items []*yamlmeta.MapItem
+		for _, prop := range schema.Items {
+			if prop.Key == defaultProp && prop.Value == nil {
+				continue
+			}
+			items = append(items, prop)
+		}
+		schema.Items = items
+	})
+}
+
+// admitsNull reports whether null is a valid value for the schema "properties".
+func admitsNull(properties *yamlmeta.Map) bool {
+	constrained := false
+	for _, prop := range properties.Items {
+		switch prop.Key {
+		case nullableProp:
+			if prop.Value == true {
+				return true
+			}
+		case typeProp:
+			constrained = true
+			if prop.Value == nullTypeName {
+				return true
+			}
+			if types, isList := prop.Value.(*yamlmeta.Array); isList {
+				for _, t := range types.Items {
+					if t.Value == nullTypeName {
+						return true
+					}
+				}
+			}
+		case oneOfProp, anyOfProp:
+			constrained = true
+			for _, alternative := range prop.Value.(*yamlmeta.Array).Items {
+				if admitsNull(alternative.Value.(*yamlmeta.Map)) {
+					return true
+				}
+			}
+		case allOfProp:
+			for _, alternative := range prop.Value.(*yamlmeta.Array).Items {
+				if !admitsNull(alternative.Value.(*yamlmeta.Map)) {
+					constrained = true
+				}
+			}
+		}
+	}
+	// without a type, any value (including null) is allowed.
+	return !constrained
+}
+
+// withoutExtensions removes the extensions (i.e. keywords starting with "x-") from the schema "properties" and from
+// each schema within it. (Values — e.g. defaults and the names of properties — are kept as they are.)
+func withoutExtensions(properties *yamlmeta.Map) {
+	forEachSchema(properties, func(schema *yamlmeta.Map) {
+		var items []*yamlmeta.MapItem
+		for _, prop := range schema.Items {
+			if !strings.HasPrefix(fmt.Sprintf("%v", prop.Key), "x-") {
+				items = append(items, prop)
+			}
+		}
+		schema.Items = items
+	})
+}
+
+// forEachSchema calls "visit" with the schema "properties" and with each schema within it.
+func forEachSchema(properties *yamlmeta.Map, visit func(schema *yamlmeta.Map)) {
+	for _, prop := range properties.Items {
+		switch prop.Key {
+		case propertiesProp:
+			for _, property := range prop.Value.(*yamlmeta.Map).Items {
+				forEachSchema(property.Value.(*yamlmeta.Map), visit)
+			}
+		case itemsProp, ifProp, thenProp, elseProp, notProp:
+			forEachSchema(prop.Value.(*yamlmeta.Map), visit)
+		case oneOfProp, anyOfProp, allOfProp:
+			for _, alternative := range prop.Value.(*yamlmeta.Array).Items {
+				forEachSchema(alternative.Value.(*yamlmeta.Map), visit)
+			}
+		}
+	}
+	visit(properties)
+}
+
+// calculateValueProperties describes a value of type "valueType", constrained by "validation".
+func (o *OpenAPIDocument) calculateValueProperties(valueType Type, validation *validations.NodeValidation) *yamlmeta.Map {
+	properties := o.calculateProperties(valueType)
+	if o.inferStringMaxLength && !hasLengthConstraint(validation) {
+		properties = withInferredMaxLength(properties, valueType)
+	}
+	return o.withValidations(properties, validation)
+}
+
+// withCommentDescription adds to "properties" the comments preceding the node described by "t" as its description,
+// when configured to do so and no description was given explicitly.
+func (o *OpenAPIDocument) withCommentDescription(properties *yamlmeta.Map, t Type) *yamlmeta.Map {
+	description := o.commentDescriptions.Of(t)
+	if description == "" {
+		return properties
+	}
+	if hasProp(properties, descriptionProp) {
+		return properties
+	}
+	var items openAPIKeys
+	items = append(items, properties.Items...)
+	items = append(items, &yamlmeta.MapItem{Key: descriptionProp, Value: description})
+
+	sort.Sort(items)
+	return &yamlmeta.Map{Items: items}
+}
+
+// withKeyTitle adds to "properties" a title derived from "key", when configured to do so and no title was given
+// explicitly.
+func (o *OpenAPIDocument) withKeyTitle(properties *yamlmeta.Map, key interface{}) *yamlmeta.Map {
+	if !o.titlesFromKeys || hasProp(properties, titleProp) {
+		return properties
+	}
+	title := titleFromKey(fmt.Sprintf("%v", key))
+	if title == "" {
+		return properties
+	}
+	var items openAPIKeys
+	items = append(items, properties.Items...)
+	items = append(items, &yamlmeta.MapItem{Key: titleProp, Value: title})
+
+	sort.Sort(items)
+	return &yamlmeta.Map{Items: items}
+}
+
+// titleFromKey makes "key" readable as a title: its words (separated by "_", "-", "." or spaces) each capitalized
+// and separated by a space (e.g. "db_conn" becomes "Db Conn").
+func titleFromKey(key string) string {
+	words := strings.FieldsFunc(key, func(r rune) bool {
+		return r == '_' || r == '-' || r == '.' || unicode.IsSpace(r)
+	})
+	for idx, word := range words {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[idx] = string(runes)
+	}
+	return strings.Join(words, " ")
+}
+
+// withDefault sets the "default" of "properties" to "value".
+func withDefault(properties *yamlmeta.Map, value interface{}) *yamlmeta.Map {
+	for _, prop := range properties.Items {
+		if prop.Key == defaultProp {
+			prop.Value = value
+			return properties
+		}
+	}
+	var items openAPIKeys
+	items = append(items, properties.Items...)
+	items = append(items, &yamlmeta.MapItem{Key: defaultProp, Value: value})
+
+	sort.Sort(items)
+	return &yamlmeta.Map{Items: items}
+}
+
+// withOrder adds to "properties" its position, "order", among the properties of its object.
+func withOrder(properties *yamlmeta.Map, order int) *yamlmeta.Map {
+	var items openAPIKeys
+	items = append(items, properties.Items...)
+	items = append(items, &yamlmeta.MapItem{Key: orderExtProp, Value: order})
+
+	sort.Sort(items)
+	return &yamlmeta.Map{Items: items}
+}
+
+// isDeprecated reports whether the schema "properties" is marked `deprecated: true`.
+func isDeprecated(properties *yamlmeta.Map) bool {
+	for _, prop := range properties.Items {
+		if prop.Key == deprecatedProp {
+			return prop.Value == true
+		}
+	}
+	return false
+}
+
+func hasProp(properties *yamlmeta.Map, key string) bool {
+	for _, prop := range properties.Items {
+		if prop.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutProp produces a copy of "properties" without "key".
+func withoutProp(properties *yamlmeta.Map, key string) *yamlmeta.Map {
+	without := &yamlmeta.Map{}
+	for _, prop := range properties.Items {
+		if prop.Key != key {
+			without.Items = append(without.Items, prop)
+		}
+	}
+	return without
+}
+
+func hasLengthConstraint(validation *validations.NodeValidation) bool {
+	if validation == nil {
+		return false
+	}
+	kwargs := validation.GetValidationKwargs()
+	_, hasMinLength := kwargs.GetMinLength()
+	_, hasMaxLength := kwargs.GetMaxLength()
+	return hasMinLength || hasMaxLength
+}
+
+// withInferredMaxLength adds to "properties" the length of the default of "valueType" as the maximum, if that type is
+// a string with a non-empty default.
+func withInferredMaxLength(properties *yamlmeta.Map, valueType Type) *yamlmeta.Map {
+	scalarType, ok := valueType.(*ScalarType)
+	if !ok || scalarType.ValueType != StringType {
+		return properties
+	}
+	defaultValue, ok := scalarType.GetDefaultValue().(string)
+	if !ok || defaultValue == "" {
+		return properties
+	}
+	var items openAPIKeys
+	items = append(items, properties.Items...)
+	items = append(items, &yamlmeta.MapItem{Key: maxLengthProp, Value: utf8.RuneCountInString(defaultValue)})
+
+	sort.Sort(items)
+	return &yamlmeta.Map{Items: items}
+}
+
+// nullOnly produces the keywords that describe a value that can only ever be null.
+func (o *OpenAPIDocument) nullOnly() []*yamlmeta.MapItem {
+	if o.version == OpenAPIVersion31 {
+		return []*yamlmeta.MapItem{{Key: typeProp, Value: nullTypeName}}
+	}
+	// OpenAPI v3.0 has no "null" type: a null-only value is a nullable one (see NullType) whose only allowed value is null.
+	return []*yamlmeta.MapItem{{Key: enumProp, Value: &yamlmeta.Array{Items: []*yamlmeta.ArrayItem{{Value: nil}}}}}
+}
+
+// withValidations adds to "properties" the keywords expressing the rules in "validation".
+func (o *OpenAPIDocument) withValidations(properties *yamlmeta.Map, validation *validations.NodeValidation) *yamlmeta.Map {
+	if validation == nil {
+		return properties
+	}
+	var items, itemBounds openAPIKeys
+	items = append(items, properties.Items...)
+	for _, item := range o.convertValidations(validation, isStringSchema(properties)) {
+		// a format given explicitly (via @schema/format) supersedes one implied by a rule (e.g. timestamp=).
+		if item.Key == formatProp && hasProp(properties, formatProp) {
+			continue
+		}
+		// given a list, a (scalar) bound is of each of its items, not of the list itself.
+		if isBoundProp(item.Key) && hasProp(properties, itemsProp) {
+			itemBounds = append(itemBounds, item)
+			continue
+		}
+		// a nullable value, though enumerated, may still be null: which an "enum" must then list explicitly.
+		if item.Key == enumProp && admitsNull(properties) {
+			item = withNullEnumMember(item)
+		}
+		items = append(items, item)
+	}
+	if len(itemBounds) > 0 {
+		for _, item := range items {
+			if item.Key == itemsProp {
+				var itemProps openAPIKeys
+				itemProps = append(itemProps, item.Value.(*yamlmeta.Map).Items...)
+				itemProps = append(itemProps, itemBounds...)
+				sort.Sort(itemProps)
+				item.Value = &yamlmeta.Map{Items: itemProps}
+			}
+		}
+	}
+	if o.validationExtensions {
+		items = append(items, validationExtensions(validation)...)
+	}
+
+	sort.Sort(items)
+	return &yamlmeta.Map{Items: items}
+}
+
+// withNullEnumMember produces a copy of the "enum" property that also lists null (if it does not already).
+func withNullEnumMember(enum *yamlmeta.MapItem) *yamlmeta.MapItem {
+	members := enum.Value.(*yamlmeta.Array)
+	for _, member := range members.Items {
+		if member.Value == nil {
+			return enum
+		}
+	}
+	withNull := &yamlmeta.Array{Items: append(append([]*yamlmeta.ArrayItem{}, members.Items...), &yamlmeta.ArrayItem{Value: nil})}
+	return &yamlmeta.MapItem{Key: enum.Key, Value: withNull}
+}
+
+// convertValidations expresses the rules of "validation" (of a string value, when "isString") that have an equivalent
+// OpenAPI keyword. Others (e.g. max_decimals=) are only enforced when validating data values.
+func (o *OpenAPIDocument) convertValidations(validation *validations.NodeValidation, isString bool) []*yamlmeta.MapItem {
+	var items []*yamlmeta.MapItem
+	kwargs := validation.GetValidationKwargs()
+	if isString {
+		if minLen, found := kwargs.GetMinLength(); found {
+			items = append(items, &yamlmeta.MapItem{Key: minLengthProp, Value: minLen})
+		}
+		if maxLen, found := kwargs.GetMaxLength(); found {
+			items = append(items, &yamlmeta.MapItem{Key: maxLengthProp, Value: maxLen})
+		}
+	}
+	// only a numeric bound has an equivalent keyword (e.g. not one of a string or a date).
+	if min, found := kwargs.GetMin(); found && isNumber(min) {
+		items = append(items, &yamlmeta.MapItem{Key: minimumProp, Value: goValueOf(min)})
+	}
+	if max, found := kwargs.GetMax(); found && isNumber(max) {
+		items = append(items, &yamlmeta.MapItem{Key: maximumProp, Value: goValueOf(max)})
+	}
+	if exclusiveMin, found := kwargs.GetExclusiveMin(); found && isNumber(exclusiveMin) {
+		items = append(items, o.exclusiveBound(minimumProp, exclusiveMinimumProp, exclusiveMin)...)
+	}
+	if exclusiveMax, found := kwargs.GetExclusiveMax(); found && isNumber(exclusiveMax) {
+		items = append(items, o.exclusiveBound(maximumProp, exclusiveMaximumProp, exclusiveMax)...)
+	}
+	if oneOf, found := kwargs.GetOneOf(); found {
+		items = append(items, &yamlmeta.MapItem{Key: enumProp, Value: yamlmeta.NewASTFromInterfaceWithNoPosition(goValueOf(oneOf))})
+	}
+	if requiredKeys, found := kwargs.GetRequiredKeys(); found {
+		items = append(items, &yamlmeta.MapItem{Key: requiredProp, Value: yamlmeta.NewASTFromInterfaceWithNoPosition(goValueOf(requiredKeys))})
+	}
+	if notOneOf, found := kwargs.GetNotOneOf(); found {
+		items = append(items, &yamlmeta.MapItem{Key: notProp, Value: &yamlmeta.Map{Items: []*yamlmeta.MapItem{
+			{Key: enumProp, Value: yamlmeta.NewASTFromInterfaceWithNoPosition(goValueOf(notOneOf))},
+		}}})
+	}
+	// the letters excluded by a pattern are those in the ASCII range, only.
+	excluded := ""
+	if kwargs.GetLowercase() {
+		excluded += "A-Z"
+	}
+	if kwargs.GetUppercase() {
+		excluded += "a-z"
+	}
+	if kwargs.GetDuration() {
+		items = append(items, &yamlmeta.MapItem{Key: formatProp, Value: "duration"})
+	} else if kwargs.GetTimestamp() {
+		items = append(items, &yamlmeta.MapItem{Key: formatProp, Value: "date-time"})
+	} else if version, found := kwargs.GetIP(); found && version != yttlibrary.IPVersionAny {
+		// there is no format for an address of either version.
+		items = append(items, &yamlmeta.MapItem{Key: formatProp, Value: "ip" + version})
+	} else if _, found := kwargs.GetCIDR(); found {
+		items = append(items, &yamlmeta.MapItem{Key: formatProp, Value: "cidr"})
+	} else if _, found := kwargs.GetURL(); found {
+		items = append(items, &yamlmeta.MapItem{Key: formatProp, Value: "uri"})
+	} else if _, found := kwargs.GetSemver(); found {
+		// not a format defined by OpenAPI (nor JSON Schema), but one that tools are free to recognize.
+		items = append(items, &yamlmeta.MapItem{Key: formatProp, Value: "semver"})
+	}
+	prefix, hasPrefix := kwargs.GetStartsWith()
+	suffix, hasSuffix := kwargs.GetEndsWith()
+	if pattern, found := kwargs.GetPattern(); found {
+		// a value has (at most) one pattern: an explicit one supersedes that derived from the other rules.
+		items = append(items, &yamlmeta.MapItem{Key: patternProp, Value: pattern.String()})
+	} else if hasPrefix || hasSuffix {
+		items = append(items, &yamlmeta.MapItem{Key: patternProp, Value: affixPattern(prefix, hasPrefix, suffix, hasSuffix)})
+	} else if excluded != "" {
+		items = append(items, &yamlmeta.MapItem{Key: patternProp, Value: fmt.Sprintf("^[^%s]*$", excluded)})
+	}
+	return items
+}
+
+// isStringSchema reports whether the schema "properties" describes a string (possibly, a nullable one).
+func isStringSchema(properties *yamlmeta.Map) bool {
+	for _, prop := range properties.Items {
+		if prop.Key != typeProp {
+			continue
+		}
+		if types, isList := prop.Value.(*yamlmeta.Array); isList {
+			for _, t := range types.Items {
+				if t.Value == "string" {
+					return true
+				}
+			}
+		}
+		return prop.Value == "string"
+	}
+	return false
+}
+
+// isBoundProp reports whether "key" is a keyword bounding a (numeric) value.
+func isBoundProp(key interface{}) bool {
+	switch key {
+	case minimumProp, maximumProp, exclusiveMinimumProp, exclusiveMaximumProp:
+		return true
+	}
+	return false
+}
+
+func isNumber(value starlark.Value) bool {
+	switch value.(type) {
+	case starlark.Int, starlark.Float:
+		return true
+	}
+	return false
+}
+
+func goValueOf(value starlark.Value) interface{} {
+	goValue, err := core.NewStarlarkValue(value).AsGoValue()
+	if err != nil {
+		panic(err)
+	}
+	return goValue
+}
+
+// exclusiveBound expresses "bound" as an exclusive one: in OpenAPI v3.0, as the (inclusive) bound keyword "boundProp"
+// flagged by the boolean "exclusiveProp"; as of OpenAPI v3.1, as the numeric "exclusiveProp", alone.
+func (o *OpenAPIDocument) exclusiveBound(boundProp, exclusiveProp string, bound starlark.Value) []*yamlmeta.MapItem {
+	value, err := core.NewStarlarkValue(bound).AsGoValue()
+	if err != nil {
+		panic(err)
+	}
+	if o.version == OpenAPIVersion31 {
+		return []*yamlmeta.MapItem{{Key: exclusiveProp, Value: value}}
+	}
+	return []*yamlmeta.MapItem{{Key: boundProp, Value: value}, {Key: exclusiveProp, Value: true}}
+}
+
+// affixPattern produces the pattern of a string that starts with "prefix" and/or ends with "suffix". When it has both,
+// the prefix is a lookahead (so that, e.g., "aba" both starts with "ab" and ends with "ba").
+func affixPattern(prefix starlark.String, hasPrefix bool, suffix starlark.String, hasSuffix bool) string {
+	switch {
+	case hasPrefix && hasSuffix:
+		return fmt.Sprintf("^(?=%s)[\\s\\S]*%s$", regexp.QuoteMeta(prefix.GoString()), regexp.QuoteMeta(suffix.GoString()))
+	case hasPrefix:
+		return "^" + regexp.QuoteMeta(prefix.GoString())
+	default:
+		return regexp.QuoteMeta(suffix.GoString()) + "$"
+	}
+}
+
+// validationExtensions describes the rules of "validation" that have no equivalent OpenAPI keyword, each as a map of
+// the name of the keyword argument that declared it and the value given.
+func validationExtensions(validation *validations.NodeValidation) []*yamlmeta.MapItem {
+	var rules []*yamlmeta.ArrayItem
+	addRule := func(name string, value interface{}) {
+		rules = append(rules, &yamlmeta.ArrayItem{Value: &yamlmeta.Map{Items: []*yamlmeta.MapItem{
+			{Key: "rule", Value: name},
+			{Key: "value", Value: value},
+		}}})
+	}
+
+	kwargs := validation.GetValidationKwargs()
+	if maxDecimals, found := kwargs.GetMaxDecimals(); found {
+		addRule(validations.KwargMaxDecimals, maxDecimals)
+	}
+	if sorted, found := kwargs.GetSorted(); found {
+		addRule(validations.KwargSorted, sorted.GoString())
+	}
+	if kwargs.GetValidYAML() {
+		addRule(validations.KwargValidYAML, true)
+	}
+	if kwargs.GetValidJSON() {
+		addRule(validations.KwargValidJSON, true)
+	}
+	if oneNotNull, found := kwargs.GetOneNotNull(); found {
+		keys, err := core.NewStarlarkValue(oneNotNull).AsGoValue()
+		if err != nil {
+			panic(err)
+		}
+		addRule(validations.KwargOneNotNull, yamlmeta.NewASTFromInterfaceWithNoPosition(keys))
+	}
+	if requiredTogether, found := kwargs.GetRequiredTogether(); found {
+		keys, err := core.NewStarlarkValue(requiredTogether).AsGoValue()
+		if err != nil {
+			panic(err)
+		}
+		addRule(validations.KwargRequiredTogether, yamlmeta.NewASTFromInterfaceWithNoPosition(keys))
+	}
+
+	if len(rules) == 0 {
+		return nil
+	}
+	return []*yamlmeta.MapItem{{Key: validationsExtProp, Value: &yamlmeta.Array{Items: rules}}}
+}
+
 func collectDocumentation(typedValue Type) []*yamlmeta.MapItem {
 	var items []*yamlmeta.MapItem
 	if typedValue.GetTitle() != "" {
@@ -171,6 +1164,44 @@ func collectDocumentation(typedValue Type) []*yamlmeta.MapItem {
 	return items
 }
 
+// convertRequiredIf expresses a conditional requirement as the if/then/else schema keywords.
+func (o *OpenAPIDocument) convertRequiredIf(requiredIf *RequiredIfAnnotation) []*yamlmeta.MapItem {
+	if requiredIf == nil {
+		return nil
+	}
+	condition := &yamlmeta.Map{Items: []*yamlmeta.MapItem{
+		{Key: propertiesProp, Value: &yamlmeta.Map{Items: []*yamlmeta.MapItem{
+			{Key: requiredIf.key, Value: &yamlmeta.Map{Items: []*yamlmeta.MapItem{
+				{Key: enumProp, Value: &yamlmeta.Array{Items: []*yamlmeta.ArrayItem{
+					{Value: yamlmeta.NewASTFromInterfaceWithPosition(goValueOf(requiredIf.equals), requiredIf.pos)},
+				}}},
+			}}},
+		}}},
+	}}
+
+	items := []*yamlmeta.MapItem{{Key: ifProp, Value: condition}}
+	if len(requiredIf.then) > 0 {
+		items = append(items, &yamlmeta.MapItem{Key: thenProp, Value: o.requiredKeys(requiredIf.then)})
+	}
+	if len(requiredIf.otherwise) > 0 {
+		items = append(items, &yamlmeta.MapItem{Key: elseProp, Value: o.requiredKeys(requiredIf.otherwise)})
+	}
+	return items
+}
+
+// requiredKeys produces the schema of a map in which each of "keys" is present and not null.
+func (o *OpenAPIDocument) requiredKeys(keys []string) *yamlmeta.Map {
+	required := &yamlmeta.Array{}
+	properties := &yamlmeta.Map{}
+	for _, key := range keys {
+		required.Items = append(required.Items, &yamlmeta.ArrayItem{Value: key})
+		properties.Items = append(properties.Items, &yamlmeta.MapItem{Key: key, Value: &yamlmeta.Map{Items: []*yamlmeta.MapItem{
+			{Key: notProp, Value: &yamlmeta.Map{Items: o.nullOnly()}},
+		}}})
+	}
+	return &yamlmeta.Map{Items: []*yamlmeta.MapItem{{Key: propertiesProp, Value: properties}, {Key: requiredProp, Value: required}}}
+}
+
 func (o *OpenAPIDocument) openAPITypeFor(astType *ScalarType) string {
 	switch astType.ValueType {
 	case StringType:
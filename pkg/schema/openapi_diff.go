@@ -0,0 +1,427 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vmware-tanzu/carvel-ytt/pkg/yamlmeta"
+)
+
+// OpenAPIChange is a difference between the schemas of data values in two OpenAPI documents.
+type OpenAPIChange struct {
+	Path        string // of the data value changed (e.g. "db.port"; "ports[]" for each item of "ports"); empty for data values as a whole.
+	Description string
+	// Breaking changes are those that can make data values that were valid, invalid (e.g. removing a value, tightening
+	// its type or its bounds).
+	Breaking bool
+}
+
+// OpenAPIDiff is the set of changes from one OpenAPI document (the baseline) to another.
+type OpenAPIDiff struct {
+	Changes []OpenAPIChange
+}
+
+// DiffOpenAPI compares the schema of data values (i.e. `components/schemas/<schemaName>`, see
+// DefaultOpenAPISchemaName) in the OpenAPI document "current" with that in "baseline", describing each change.
+func DiffOpenAPI(baseline, current *yamlmeta.Document, schemaName string) (OpenAPIDiff, error) {
+	baselineSchema, err := dataValuesSchemaOf(baseline, schemaName)
+	if err != nil {
+		return OpenAPIDiff{}, fmt.Errorf("Expected baseline to be an OpenAPI document (as inspected by ytt): %s", err)
+	}
+	currentSchema, err := dataValuesSchemaOf(current, schemaName)
+	if err != nil {
+		return OpenAPIDiff{}, err
+	}
+	diff := OpenAPIDiff{}
+	diff.compare("", baselineSchema, currentSchema)
+	return diff, nil
+}
+
+// HasBreakingChanges reports whether any of the changes is breaking.
+func (d OpenAPIDiff) HasBreakingChanges() bool {
+	for _, change := range d.Changes {
+		if change.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// Report describes the changes, breaking ones first.
+func (d OpenAPIDiff) Report() string {
+	if len(d.Changes) == 0 {
+		return "No changes to the schema of data values.\n"
+	}
+	var breaking, other []string
+	for _, change := range d.Changes {
+		path := change.Path
+		if path == "" {
+			path = "(data values)"
+		}
+		line := fmt.Sprintf("- %s: %s\n", path, change.Description)
+		if change.Breaking {
+			breaking = append(breaking, line)
+		} else {
+			other = append(other, line)
+		}
+	}
+	var sections []string
+	if len(breaking) > 0 {
+		sections = append(sections, "Breaking changes:\n"+strings.Join(breaking, ""))
+	}
+	if len(other) > 0 {
+		sections = append(sections, "Other changes:\n"+strings.Join(other, ""))
+	}
+	return strings.Join(sections, "\n")
+}
+
+func dataValuesSchemaOf(doc *yamlmeta.Document, schemaName string) (*yamlmeta.Map, error) {
+	var current interface{} = doc.Value
+	for _, key := range []string{"components", "schemas", schemaName} {
+		node, isMap := current.(*yamlmeta.Map)
+		if !isMap {
+			return nil, fmt.Errorf("no 'components/schemas/%s'", schemaName)
+		}
+		item, found := jsonSchemaKeyword(node, key)
+		if !found {
+			return nil, fmt.Errorf("no 'components/schemas/%s'", schemaName)
+		}
+		current = item.Value
+	}
+	schema, isMap := current.(*yamlmeta.Map)
+	if !isMap {
+		return nil, fmt.Errorf("'components/schemas/%s' is not a schema", schemaName)
+	}
+	return schema, nil
+}
+
+func (d *OpenAPIDiff) add(path string, breaking bool, format string, args ...interface{}) {
+	d.Changes = append(d.Changes, OpenAPIChange{Path: path, Description: fmt.Sprintf(format, args...), Breaking: breaking})
+}
+
+// compare records the changes from the schema "baseline" to the schema "current" (of the value at "path"), and of
+// the schemas within them.
+func (d *OpenAPIDiff) compare(path string, baseline, current *yamlmeta.Map) {
+	d.compareTypes(path, baseline, current)
+	d.compareEnums(path, baseline, current)
+	d.compareBounds(path, baseline, current)
+	for _, keyword := range []string{formatProp, patternProp} {
+		baselineValue, currentValue := keywordString(baseline, keyword), keywordString(current, keyword)
+		if baselineValue != currentValue {
+			d.add(path, true, "%s changed from %s to %s", keyword, orNone(baselineValue), orNone(currentValue))
+		}
+	}
+	if !isDeprecated(baseline) && isDeprecated(current) {
+		d.add(path, false, "deprecated")
+	}
+	if baselineDefault, currentDefault := keywordString(baseline, defaultProp), keywordString(current, defaultProp); baselineDefault != currentDefault {
+		d.add(path, false, "default changed from %s to %s", orNone(baselineDefault), orNone(currentDefault))
+	}
+
+	baselineRequired, currentRequired := keywordStrings(baseline, requiredProp), keywordStrings(current, requiredProp)
+	for _, key := range currentRequired {
+		if !containsString(baselineRequired, key) {
+			d.add(childPath(path, key), true, "newly required")
+		}
+	}
+
+	baselineProps, currentProps := properties(baseline), properties(current)
+	for _, prop := range currentProps.Items {
+		key := fmt.Sprintf("%v", prop.Key)
+		baselineProp, found := jsonSchemaKeyword(baselineProps, key)
+		if !found {
+			d.add(childPath(path, key), false, "added")
+			continue
+		}
+		if baselineSchema, currentSchema, ok := bothSchemas(baselineProp.Value, prop.Value); ok {
+			d.compare(childPath(path, key), baselineSchema, currentSchema)
+		}
+	}
+	for _, prop := range baselineProps.Items {
+		key := fmt.Sprintf("%v", prop.Key)
+		if _, found := jsonSchemaKeyword(currentProps, key); !found {
+			d.add(childPath(path, key), true, "removed")
+		}
+	}
+
+	baselineItems, baselineHasItems := jsonSchemaKeyword(baseline, itemsProp)
+	currentItems, currentHasItems := jsonSchemaKeyword(current, itemsProp)
+	if baselineHasItems && currentHasItems {
+		if baselineSchema, currentSchema, ok := bothSchemas(baselineItems.Value, currentItems.Value); ok {
+			d.compare(path+"[]", baselineSchema, currentSchema)
+		}
+	}
+}
+
+// compareTypes records a change in the types of value allowed (including null, whether via "nullable" or as a type).
+func (d *OpenAPIDiff) compareTypes(path string, baseline, current *yamlmeta.Map) {
+	baselineTypes, currentTypes := typesOf(baseline), typesOf(current)
+	if sortedString(baselineTypes) == sortedString(currentTypes) {
+		return
+	}
+	// without a type, any value is allowed.
+	widened := len(currentTypes) == 0
+	if len(baselineTypes) > 0 && len(currentTypes) > 0 {
+		widened = true
+		for _, t := range baselineTypes {
+			// every integer is a number, too.
+			if !containsString(currentTypes, t) && !(t == "integer" && containsString(currentTypes, "number")) {
+				widened = false
+			}
+		}
+	}
+	d.add(path, !widened, "type changed from %s to %s", orAny(baselineTypes), orAny(currentTypes))
+}
+
+// compareEnums records a change in the values allowed by "enum".
+func (d *OpenAPIDiff) compareEnums(path string, baseline, current *yamlmeta.Map) {
+	baselineEnum, baselineHasEnum := enumOf(baseline)
+	currentEnum, currentHasEnum := enumOf(current)
+	switch {
+	case !baselineHasEnum && currentHasEnum:
+		d.add(path, true, "now restricted to one of [%s]", strings.Join(currentEnum, ", "))
+	case baselineHasEnum && !currentHasEnum:
+		d.add(path, false, "no longer restricted to one of [%s]", strings.Join(baselineEnum, ", "))
+	case baselineHasEnum && currentHasEnum:
+		var removed, added []string
+		for _, value := range baselineEnum {
+			if !containsString(currentEnum, value) {
+				removed = append(removed, value)
+			}
+		}
+		for _, value := range currentEnum {
+			if !containsString(baselineEnum, value) {
+				added = append(added, value)
+			}
+		}
+		if len(removed) > 0 {
+			d.add(path, true, "no longer allows [%s]", strings.Join(removed, ", "))
+		}
+		if len(added) > 0 {
+			d.add(path, false, "now also allows [%s]", strings.Join(added, ", "))
+		}
+	}
+}
+
+// compareBounds records a change in any lower or upper bound (of a number, or of a length).
+func (d *OpenAPIDiff) compareBounds(path string, baseline, current *yamlmeta.Map) {
+	for _, isLower := range []bool{true, false} {
+		name := maximumProp
+		if isLower {
+			name = minimumProp
+		}
+		baselineBound, baselineFound := numericBoundOf(baseline, isLower)
+		currentBound, currentFound := numericBoundOf(current, isLower)
+		switch {
+		case !baselineFound && !currentFound:
+			continue
+		case !baselineFound:
+			d.add(path, true, "%s added (%s)", name, currentBound)
+		case !currentFound:
+			d.add(path, false, "%s removed (was %s)", name, baselineBound)
+		case baselineBound != currentBound:
+			d.add(path, currentBound.tighterThan(baselineBound), "%s changed from %s to %s", name, baselineBound, currentBound)
+		}
+	}
+
+	bounds := []struct {
+		keyword string
+		isLower bool
+	}{
+		{minLengthProp, true}, {maxLengthProp, false},
+		{"minItems", true}, {"maxItems", false},
+		{minPropertiesProp, true}, {maxPropertiesProp, false},
+	}
+	for _, bound := range bounds {
+		baselineBound, baselineFound := keywordNumber(baseline, bound.keyword)
+		currentBound, currentFound := keywordNumber(current, bound.keyword)
+		switch {
+		case !baselineFound && !currentFound:
+			continue
+		case !baselineFound:
+			d.add(path, true, "%s added (%v)", bound.keyword, currentBound)
+		case !currentFound:
+			d.add(path, false, "%s removed (was %v)", bound.keyword, baselineBound)
+		case baselineBound != currentBound:
+			tightened := currentBound < baselineBound
+			if bound.isLower {
+				tightened = currentBound > baselineBound
+			}
+			d.add(path, tightened, "%s changed from %v to %v", bound.keyword, baselineBound, currentBound)
+		}
+	}
+}
+
+// numericBound is a lower or upper bound on a number, either inclusive or exclusive.
+type numericBound struct {
+	value     float64
+	isLower   bool
+	exclusive bool
+}
+
+// numericBoundOf is the lower (or upper) bound "schema" places on a number, if any. It is given by "minimum" (or
+// "maximum"), which the boolean "exclusiveMinimum" (or "exclusiveMaximum") makes exclusive in OpenAPI v3.0; or, as of
+// OpenAPI v3.1, by a numeric "exclusiveMinimum" (or "exclusiveMaximum"). When given both ways, the tighter applies.
+func numericBoundOf(schema *yamlmeta.Map, isLower bool) (numericBound, bool) {
+	boundProp, exclusiveProp := maximumProp, exclusiveMaximumProp
+	if isLower {
+		boundProp, exclusiveProp = minimumProp, exclusiveMinimumProp
+	}
+	var bounds []numericBound
+	if value, found := keywordNumber(schema, boundProp); found {
+		exclusive, _ := jsonSchemaKeyword(schema, exclusiveProp)
+		bounds = append(bounds, numericBound{value: value, isLower: isLower, exclusive: exclusive != nil && exclusive.Value == true})
+	}
+	if value, found := keywordNumber(schema, exclusiveProp); found {
+		bounds = append(bounds, numericBound{value: value, isLower: isLower, exclusive: true})
+	}
+	if len(bounds) == 0 {
+		return numericBound{}, false
+	}
+	tightest := bounds[0]
+	for _, bound := range bounds[1:] {
+		if bound.tighterThan(tightest) {
+			tightest = bound
+		}
+	}
+	return tightest, true
+}
+
+// tighterThan reports whether this bound admits fewer numbers than "other" (a bound on the same side).
+func (b numericBound) tighterThan(other numericBound) bool {
+	if b.value == other.value {
+		return b.exclusive && !other.exclusive
+	}
+	if b.isLower {
+		return b.value > other.value
+	}
+	return b.value < other.value
+}
+
+func (b numericBound) String() string {
+	op := "<"
+	if b.isLower {
+		op = ">"
+	}
+	if !b.exclusive {
+		op += "="
+	}
+	return fmt.Sprintf("%s %v", op, b.value)
+}
+
+// typesOf names the types of value the schema "schema" allows (null, if allowed, last); none when it allows any value.
+func typesOf(schema *yamlmeta.Map) []string {
+	var types []string
+	if typeKeyword, found := jsonSchemaKeyword(schema, typeProp); found {
+		switch typed := typeKeyword.Value.(type) {
+		case *yamlmeta.Array:
+			for _, item := range typed.Items {
+				types = append(types, fmt.Sprintf("%v", item.Value))
+			}
+		default:
+			types = append(types, fmt.Sprintf("%v", typed))
+		}
+	}
+	if len(types) > 0 && admitsNull(schema) && !containsString(types, nullTypeName) {
+		types = append(types, nullTypeName)
+	}
+	return types
+}
+
+func sortedString(values []string) string {
+	sorted := append([]string{}, values...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+func enumOf(schema *yamlmeta.Map) ([]string, bool) {
+	enum, found := jsonSchemaKeyword(schema, enumProp)
+	if !found {
+		return nil, false
+	}
+	var values []string
+	if items, isArray := enum.Value.(*yamlmeta.Array); isArray {
+		for _, item := range items.Items {
+			values = append(values, strings.TrimSpace(inlineJSON(item.Value)))
+		}
+	}
+	return values, true
+}
+
+func keywordNumber(schema *yamlmeta.Map, keyword string) (float64, bool) {
+	item, found := jsonSchemaKeyword(schema, keyword)
+	if !found {
+		return 0, false
+	}
+	return jsonSchemaNumber(item.Value)
+}
+
+// keywordString is the value of "keyword" in "schema" (as inline JSON), or empty if it has none.
+func keywordString(schema *yamlmeta.Map, keyword string) string {
+	item, found := jsonSchemaKeyword(schema, keyword)
+	if !found {
+		return ""
+	}
+	return strings.TrimSpace(inlineJSON(item.Value))
+}
+
+func keywordStrings(schema *yamlmeta.Map, keyword string) []string {
+	var values []string
+	if item, found := jsonSchemaKeyword(schema, keyword); found {
+		if items, isArray := item.Value.(*yamlmeta.Array); isArray {
+			for _, value := range items.Items {
+				values = append(values, fmt.Sprintf("%v", value.Value))
+			}
+		}
+	}
+	return values
+}
+
+func properties(schema *yamlmeta.Map) *yamlmeta.Map {
+	if props, found := jsonSchemaKeyword(schema, propertiesProp); found {
+		if propsMap, isMap := props.Value.(*yamlmeta.Map); isMap {
+			return propsMap
+		}
+	}
+	return &yamlmeta.Map{}
+}
+
+func bothSchemas(baseline, current interface{}) (*yamlmeta.Map, *yamlmeta.Map, bool) {
+	baselineSchema, baselineIsMap := baseline.(*yamlmeta.Map)
+	currentSchema, currentIsMap := current.(*yamlmeta.Map)
+	return baselineSchema, currentSchema, baselineIsMap && currentIsMap
+}
+
+func childPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
+
+func orAny(types []string) string {
+	if len(types) == 0 {
+		return "any"
+	}
+	return strings.Join(types, " | ")
+}
@@ -0,0 +1,90 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/schema"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/yamlmeta"
+)
+
+func TestDiffOpenAPI_bounds_and_types(t *testing.T) {
+	tests := []struct {
+		name     string
+		baseline string
+		current  string
+		expected []schema.OpenAPIChange
+	}{
+		{
+			name:     "making a minimum exclusive (as in OpenAPI v3.0) tightens it",
+			baseline: `{type: number, minimum: 0}`,
+			current:  `{type: number, minimum: 0, exclusiveMinimum: true}`,
+			expected: []schema.OpenAPIChange{{Path: "port", Description: "minimum changed from >= 0 to > 0", Breaking: true}},
+		},
+		{
+			name:     "making a maximum inclusive (as in OpenAPI v3.0) loosens it",
+			baseline: `{type: number, maximum: 10, exclusiveMaximum: true}`,
+			current:  `{type: number, maximum: 10}`,
+			expected: []schema.OpenAPIChange{{Path: "port", Description: "maximum changed from < 10 to <= 10", Breaking: false}},
+		},
+		{
+			name:     "an exclusive minimum given either way is the same bound",
+			baseline: `{type: number, minimum: 0, exclusiveMinimum: true}`,
+			current:  `{type: number, exclusiveMinimum: 0}`,
+		},
+		{
+			name:     "raising an exclusive minimum (as in OpenAPI v3.1) tightens it",
+			baseline: `{type: number, exclusiveMinimum: 0}`,
+			current:  `{type: number, exclusiveMinimum: 1}`,
+			expected: []schema.OpenAPIChange{{Path: "port", Description: "minimum changed from > 0 to > 1", Breaking: true}},
+		},
+		{
+			name:     "adding a maximum is breaking",
+			baseline: `{type: number}`,
+			current:  `{type: number, maximum: 65535}`,
+			expected: []schema.OpenAPIChange{{Path: "port", Description: "maximum added (<= 65535)", Breaking: true}},
+		},
+		{
+			name:     "removing a minimum is not breaking",
+			baseline: `{type: number, minimum: 1}`,
+			current:  `{type: number}`,
+			expected: []schema.OpenAPIChange{{Path: "port", Description: "minimum removed (was >= 1)", Breaking: false}},
+		},
+		{
+			name:     "widening integer to number is not breaking",
+			baseline: `{type: integer}`,
+			current:  `{type: number}`,
+			expected: []schema.OpenAPIChange{{Path: "port", Description: "type changed from integer to number", Breaking: false}},
+		},
+		{
+			name:     "narrowing number to integer is breaking",
+			baseline: `{type: number}`,
+			current:  `{type: integer}`,
+			expected: []schema.OpenAPIChange{{Path: "port", Description: "type changed from number to integer", Breaking: true}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diff, err := schema.DiffOpenAPI(openAPIDocWithPort(t, test.baseline), openAPIDocWithPort(t, test.current), schema.DefaultOpenAPISchemaName)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, diff.Changes)
+		})
+	}
+}
+
+// openAPIDocWithPort produces an OpenAPI document whose only data value, "port", has the schema "portSchema" (in
+// flow-style YAML).
+func openAPIDocWithPort(t *testing.T, portSchema string) *yamlmeta.Document {
+	return parseYAML(t, fmt.Sprintf(`components:
+  schemas:
+    dataValues:
+      type: object
+      properties:
+        port: %s
+`, portSchema))
+}
@@ -0,0 +1,62 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/vmware-tanzu/carvel-ytt/pkg/schema"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/yamlmeta"
+)
+
+// BenchmarkOpenAPIDocument_large_schema compares describing the top-level values of a large schema one after another
+// with describing them concurrently.
+func BenchmarkOpenAPIDocument_large_schema(b *testing.B) {
+	docType := largeDocumentType(b, 200, 50)
+
+	for _, workers := range []int{1, 0} {
+		name := "sequential"
+		if workers != 1 {
+			name = "parallel"
+		}
+		b.Run(name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				schema.NewOpenAPIDocument(docType).WithParallelism(workers).AsDocument()
+			}
+		})
+	}
+}
+
+// largeDocumentType produces the type of a document with "keys" top-level maps, each with "fields" values of
+// assorted types.
+func largeDocumentType(b *testing.B, keys, fields int) *schema.DocumentType {
+	var schemaYAML strings.Builder
+	for k := 0; k < keys; k++ {
+		fmt.Fprintf(&schemaYAML, "component_%d:\n", k)
+		for f := 0; f < fields; f++ {
+			switch f % 4 {
+			case 0:
+				fmt.Fprintf(&schemaYAML, "  name_%d: some-name\n", f)
+			case 1:
+				fmt.Fprintf(&schemaYAML, "  replicas_%d: 3\n", f)
+			case 2:
+				fmt.Fprintf(&schemaYAML, "  enabled_%d: true\n", f)
+			default:
+				fmt.Fprintf(&schemaYAML, "  ports_%d:\n  - port: 80\n    protocol: TCP\n", f)
+			}
+		}
+	}
+
+	docSet, err := yamlmeta.NewDocumentSetFromBytes([]byte(schemaYAML.String()), yamlmeta.DocSetOpts{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	docType, err := schema.NewDocumentType(docSet.Items[0])
+	if err != nil {
+		b.Fatal(err)
+	}
+	return docType
+}
@@ -23,7 +23,7 @@ func NewDocumentType(doc *yamlmeta.Document) (*DocumentType, error) {
 		return nil, err
 	}
 
-	v, err := getValidation(doc)
+	v, err := getValidation(doc, typeOfValue)
 	if err != nil {
 		return nil, err
 	}
@@ -58,7 +58,7 @@ func NewMapItemType(item *yamlmeta.MapItem) (*MapItemType, error) {
 		return nil, err
 	}
 
-	v, err := getValidation(item)
+	v, err := getValidation(item, typeOfValue)
 	if err != nil {
 		return nil, err
 	}
@@ -98,7 +98,7 @@ func NewArrayItemType(item *yamlmeta.ArrayItem) (*ArrayItemType, error) {
 		return nil, err
 	}
 
-	v, err := getValidation(item)
+	v, err := getValidation(item, typeOfValue)
 	if err != nil {
 		return nil, err
 	}
@@ -140,6 +140,61 @@ func getType(node yamlmeta.Node) (Type, error) {
 		return nil, err
 	}
 
+	requiredIfAnn, err := processRequiredIfAnnotation(node)
+	if err != nil {
+		return nil, NewSchemaError("Invalid schema", err)
+	}
+	if requiredIfAnn != nil {
+		err = setRequiredIfFromAnn(requiredIfAnn, typeOfValue)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	formatAnn, err := processFormatAnnotation(node)
+	if err != nil {
+		return nil, NewSchemaError("Invalid schema", err)
+	}
+	if formatAnn != nil {
+		err = setFormatFromAnn(formatAnn, typeOfValue)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	unitAnn, err := processUnitAnnotation(node)
+	if err != nil {
+		return nil, NewSchemaError("Invalid schema", err)
+	}
+	if unitAnn != nil {
+		err = setUnitFromAnn(unitAnn, typeOfValue)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	discriminatorAnn, err := processDiscriminatorAnnotation(node)
+	if err != nil {
+		return nil, NewSchemaError("Invalid schema", err)
+	}
+	if discriminatorAnn != nil {
+		err = setDiscriminatorFromAnn(discriminatorAnn, typeOfValue)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	propertiesCountAnns, err := processPropertiesCountAnnotations(node)
+	if err != nil {
+		return nil, NewSchemaError("Invalid schema", err)
+	}
+	for _, ann := range propertiesCountAnns {
+		err = setPropertiesCountFromAnn(ann, typeOfValue)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return typeOfValue, nil
 }
 
@@ -155,21 +210,51 @@ func getValue(node yamlmeta.Node, t Type) (interface{}, error) {
 		}
 	}
 
+	// a scalar's type can be given explicitly (i.e. @schema/type "string"): the value must then be of that type.
+	if scalarType, ok := t.(*ScalarType); ok {
+		typeCheck := scalarType.CheckType(&yamlmeta.MapItem{Value: scalarType.GetDefaultValue(), Position: node.GetPosition()})
+		if typeCheck.HasViolations() {
+			var violations []error
+			for _, err := range typeCheck.Violations {
+				if typeCheckAssertionErr, ok := err.(schemaAssertionError); ok {
+					typeCheckAssertionErr.hints = []string{"quote the value to make it a string (e.g. \"1.0\").",
+						fmt.Sprintf("alternatively, give the default value via @%v.", AnnotationDefault)}
+					err = typeCheckAssertionErr
+				}
+				violations = append(violations, err)
+			}
+			return nil, NewSchemaError(fmt.Sprintf("Invalid schema - value is not of the type given via @%v", AnnotationType), violations...)
+		}
+	}
+
 	return t.GetDefaultValue(), nil
 }
 
-func getValidation(node yamlmeta.Node) (*validations.NodeValidation, error) {
+func getValidation(node yamlmeta.Node, typeOfValue Type) (*validations.NodeValidation, error) {
 	validationAnn, err := processValidationAnnotation(node)
 	if err != nil {
 		return nil, err
 	}
 
 	if validationAnn != nil {
+		if isDate(typeOfValue) {
+			// bounds on a date (e.g. min=) are compared chronologically.
+			return validationAnn.GetValidation().ComparingDates(), nil
+		}
 		return validationAnn.GetValidation(), nil
 	}
 	return nil, nil
 }
 
+// isDate reports whether "typeOfValue" is of strings whose format is a date (i.e. @schema/format "date").
+func isDate(typeOfValue Type) bool {
+	if nullType, ok := typeOfValue.(*NullType); ok {
+		typeOfValue = nullType.GetValueType()
+	}
+	scalarType, ok := typeOfValue.(*ScalarType)
+	return ok && scalarType.format == FormatDate
+}
+
 // getValueFromAnn extracts the value from the annotation and validates its type
 func getValueFromAnn(defaultAnn *DefaultAnnotation, t Type) (interface{}, error) {
 	var typeCheck TypeCheck
@@ -192,6 +277,11 @@ func getValueFromAnn(defaultAnn *DefaultAnnotation, t Type) (interface{}, error)
 			if typeCheckAssertionErr, ok := err.(schemaAssertionError); ok {
 				typeCheckAssertionErr.annPositions = []*filepos.Position{defaultAnn.GetPosition()}
 				typeCheckAssertionErr.found = typeCheckAssertionErr.found + fmt.Sprintf(" (at %v)", defaultAnn.GetPosition().AsCompactString())
+				if defaultValue == nil {
+					// null is a valid default only for values that admit null, i.e. nullable or of any type.
+					typeCheckAssertionErr.hints = append(typeCheckAssertionErr.hints,
+						fmt.Sprintf("to default to null, also annotate the value with @%v (or, to allow any type, @%v %v=True)", AnnotationNullable, AnnotationType, TypeAnnotationKwargAny))
+				}
 				violations = append(violations, typeCheckAssertionErr)
 			} else {
 				violations = append(violations, err)
@@ -239,6 +329,54 @@ func InferTypeFromValue(value interface{}, position *filepos.Position) (Type, er
 	}
 }
 
+// InferTypeFromExample calculates the DocumentType of a plain (i.e. not annotated as schema) example of Data Values,
+// as if that document were given as schema.
+//
+// The type of the items of each array is inferred from its first item; the rest must be of the same type (or null).
+func InferTypeFromExample(doc *yamlmeta.Document) (*DocumentType, error) {
+	example := doc.DeepCopy()
+	err := yamlmeta.Walk(example, keepFirstArrayItem{})
+	if err != nil {
+		return nil, err
+	}
+	return NewDocumentType(example)
+}
+
+// keepFirstArrayItem reduces each array to its first item: the one item from which a schema infers the type of items.
+type keepFirstArrayItem struct{}
+
+func (keepFirstArrayItem) Visit(node yamlmeta.Node) error {
+	array, ok := node.(*yamlmeta.Array)
+	if !ok {
+		return nil
+	}
+	if len(array.Items) == 0 {
+		return NewSchemaError("Unable to infer schema from example", schemaAssertionError{
+			position: array.Position,
+			expected: "at least 1 array item, from which to infer the type of items",
+			found:    "empty array",
+			hints:    []string{"include an item of the desired type in the example."},
+		})
+	}
+	itemType := yamlmeta.TypeName(array.Items[0].Value)
+	for _, item := range array.Items[1:] {
+		if item.Value == nil || yamlmeta.TypeName(item.Value) == itemType {
+			continue
+		}
+		return NewSchemaError("Unable to infer schema from example", schemaAssertionError{
+			position: item.Position,
+			expected: fmt.Sprintf("%s (like the first item), from which to infer the type of items", itemType),
+			found:    yamlmeta.TypeName(item.Value),
+			hints: []string{
+				"give all items of the example the same type.",
+				fmt.Sprintf("to allow items of several types, declare schema (rather than inferring it), annotating the array item with @%s.", AnnotationOneOf),
+			},
+		})
+	}
+	array.Items = array.Items[:1]
+	return nil
+}
+
 func valueTypeAllowsItemValue(explicitType Type, itemValue interface{}, position *filepos.Position) error {
 	switch explicitType.(type) {
 	case *AnyType:
@@ -251,7 +389,7 @@ func valueTypeAllowsItemValue(explicitType Type, itemValue interface{}, position
 		}
 		return nil
 	default:
-		if itemValue == nil {
+		if itemValue == nil && !isNullOnly(explicitType) {
 			return NewSchemaError("Invalid schema - null value not allowed here", schemaAssertionError{
 				position: position,
 				expected: "non-null value",
@@ -262,3 +400,49 @@ func valueTypeAllowsItemValue(explicitType Type, itemValue interface{}, position
 	}
 	return nil
 }
+
+// isNullOnly reports whether "t" is of a value that can only ever be null (i.e. annotated with both @schema/nullable
+// and @schema/type any=False).
+func isNullOnly(t Type) bool {
+	nullType, ok := t.(*NullType)
+	if !ok {
+		return false
+	}
+	scalarType, ok := nullType.ValueType.(*ScalarType)
+	return ok && scalarType.ValueType == NullValueType
+}
+
+// SetDefaultValuesFrom replaces the default values declared in `docType` with the corresponding values in `doc`
+// (e.g. data values that have been overlaid with those provided by the user).
+func SetDefaultValuesFrom(docType *DocumentType, doc *yamlmeta.Document) {
+	if doc == nil {
+		return
+	}
+	docType.SetDefaultValue(doc.DeepCopy().Value)
+	setDefaultValueFrom(docType.GetValueType(), doc.Value)
+}
+
+func setDefaultValueFrom(t Type, value interface{}) {
+	if node, ok := value.(yamlmeta.Node); ok {
+		value = node.DeepCopyAsInterface()
+	}
+	switch typedType := t.(type) {
+	case *MapType:
+		valueMap, ok := value.(*yamlmeta.Map)
+		if !ok {
+			return
+		}
+		for _, itemType := range typedType.Items {
+			for _, item := range valueMap.Items {
+				if item.Key == itemType.Key {
+					itemType.SetDefaultValue(item.Value)
+					setDefaultValueFrom(itemType.GetValueType(), item.Value)
+				}
+			}
+		}
+	case *NullType:
+		setDefaultValueFrom(typedType.GetValueType(), value)
+	case *ArrayType, *ScalarType, *AnyType, *CompositeType:
+		typedType.SetDefaultValue(value)
+	}
+}
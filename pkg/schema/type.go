@@ -5,6 +5,7 @@ package schema
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/vmware-tanzu/carvel-ytt/pkg/filepos"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/validations"
@@ -41,6 +42,7 @@ var _ Type = (*ArrayItemType)(nil)
 var _ Type = (*ScalarType)(nil)
 var _ Type = (*AnyType)(nil)
 var _ Type = (*NullType)(nil)
+var _ Type = (*CompositeType)(nil)
 
 type DocumentType struct {
 	Source       *yamlmeta.Document
@@ -54,6 +56,10 @@ type MapType struct {
 	Items         []*MapItemType
 	Position      *filepos.Position
 	documentation documentation
+	requiredIf    *RequiredIfAnnotation
+	minProperties *int64
+	maxProperties *int64
+	validations   *validations.NodeValidation
 }
 
 type MapItemType struct {
@@ -83,6 +89,8 @@ type ScalarType struct {
 	Position      *filepos.Position
 	defaultValue  interface{}
 	documentation documentation
+	format        string // (optional) for strings, how the content is encoded (e.g. FormatByte)
+	unit          string // (optional) in which the value is expressed (e.g. "seconds")
 }
 
 type AnyType struct {
@@ -97,6 +105,33 @@ type NullType struct {
 	documentation documentation
 }
 
+// CompositeType describes a value that must match its Alternatives in the manner given by its Composition.
+type CompositeType struct {
+	Composition   Composition
+	Alternatives  []*NamedType
+	Position      *filepos.Position
+	defaultValue  interface{}
+	documentation documentation
+	discriminator *DiscriminatorAnnotation // (optional) identifies which alternative a value is.
+}
+
+// NamedType is one of the alternatives of a CompositeType.
+type NamedType struct {
+	Name string
+	Type Type
+}
+
+// Composition determines how many of a CompositeType's alternatives a value must match.
+// Its value is the corresponding OpenAPI keyword.
+type Composition string
+
+// The total set of supported compositions.
+const (
+	CompositionOneOf Composition = "oneOf"
+	CompositionAnyOf Composition = "anyOf"
+	CompositionAllOf Composition = "allOf"
+)
+
 // The total set of supported scalars.
 const (
 	FloatType  = float64(0)
@@ -105,6 +140,10 @@ const (
 	BoolType   = false
 )
 
+// NullValueType is the ValueType of a ScalarType that admits no value other than null (which, itself, is only
+// admitted when wrapped in a NullType).
+var NullValueType interface{} = nil
+
 // GetValueType provides the type of the value
 func (t *DocumentType) GetValueType() Type {
 	return t.ValueType
@@ -145,6 +184,11 @@ func (n NullType) GetValueType() Type {
 	return n.ValueType
 }
 
+// GetValueType provides the type of the value
+func (c *CompositeType) GetValueType() Type {
+	return c
+}
+
 // GetDefaultValue provides the default value
 func (t DocumentType) GetDefaultValue() interface{} {
 	return &yamlmeta.Document{Value: t.defaultValue, Position: t.Position}
@@ -193,6 +237,14 @@ func (n NullType) GetDefaultValue() interface{} {
 	return nil
 }
 
+// GetDefaultValue provides the default value
+func (c *CompositeType) GetDefaultValue() interface{} {
+	if node, ok := c.defaultValue.(yamlmeta.Node); ok {
+		return node.DeepCopyAsInterface()
+	}
+	return c.defaultValue
+}
+
 // SetDefaultValue sets the default value of the entire document to `val`
 func (t *DocumentType) SetDefaultValue(val interface{}) {
 	t.defaultValue = val
@@ -234,6 +286,11 @@ func (n *NullType) SetDefaultValue(val interface{}) {
 	n.GetValueType().SetDefaultValue(val)
 }
 
+// SetDefaultValue sets the default value to `val`
+func (c *CompositeType) SetDefaultValue(val interface{}) {
+	c.defaultValue = val
+}
+
 // GetDefinitionPosition reports the location in source schema that contains this type definition.
 func (t *DocumentType) GetDefinitionPosition() *filepos.Position {
 	return t.Position
@@ -274,6 +331,11 @@ func (n NullType) GetDefinitionPosition() *filepos.Position {
 	return n.Position
 }
 
+// GetDefinitionPosition reports the location in source schema that contains this type definition.
+func (c *CompositeType) GetDefinitionPosition() *filepos.Position {
+	return c.Position
+}
+
 // GetDescription provides descriptive information
 func (t *DocumentType) GetDescription() string {
 	return ""
@@ -314,6 +376,11 @@ func (n *NullType) GetDescription() string {
 	return n.documentation.description
 }
 
+// GetDescription provides descriptive information
+func (c *CompositeType) GetDescription() string {
+	return c.documentation.description
+}
+
 // SetDescription sets the description of the type
 func (t *DocumentType) SetDescription(_ string) {}
 
@@ -348,6 +415,11 @@ func (n *NullType) SetDescription(desc string) {
 	n.documentation.description = desc
 }
 
+// SetDescription sets the description of the type
+func (c *CompositeType) SetDescription(desc string) {
+	c.documentation.description = desc
+}
+
 // GetTitle provides title information
 func (t *DocumentType) GetTitle() string {
 	return ""
@@ -388,6 +460,11 @@ func (n *NullType) GetTitle() string {
 	return n.documentation.title
 }
 
+// GetTitle provides title information
+func (c *CompositeType) GetTitle() string {
+	return c.documentation.title
+}
+
 // SetTitle sets the title of the type
 func (t *DocumentType) SetTitle(_ string) {}
 
@@ -422,6 +499,11 @@ func (n *NullType) SetTitle(title string) {
 	n.documentation.title = title
 }
 
+// SetTitle sets the title of the type
+func (c *CompositeType) SetTitle(title string) {
+	c.documentation.title = title
+}
+
 // GetExamples provides descriptive example information
 func (t *DocumentType) GetExamples() []Example {
 	return nil
@@ -462,6 +544,11 @@ func (n *NullType) GetExamples() []Example {
 	return n.documentation.examples
 }
 
+// GetExamples provides descriptive example information
+func (c *CompositeType) GetExamples() []Example {
+	return c.documentation.examples
+}
+
 // SetExamples sets the description and example of the type
 func (t *DocumentType) SetExamples(_ []Example) {}
 
@@ -496,6 +583,11 @@ func (n *NullType) SetExamples(exs []Example) {
 	n.documentation.examples = exs
 }
 
+// SetExamples sets the description and example of the type
+func (c *CompositeType) SetExamples(exs []Example) {
+	c.documentation.examples = exs
+}
+
 // IsDeprecated provides deprecated field information
 func (t *DocumentType) IsDeprecated() (bool, string) {
 	return false, ""
@@ -537,6 +629,11 @@ func (n *NullType) IsDeprecated() (bool, string) {
 	return n.documentation.deprecated, n.documentation.deprecationNotice
 }
 
+// IsDeprecated provides deprecated field information
+func (c *CompositeType) IsDeprecated() (bool, string) {
+	return c.documentation.deprecated, c.documentation.deprecationNotice
+}
+
 // SetDeprecated sets the deprecated field value
 func (t *DocumentType) SetDeprecated(_ bool, _ string) {}
 
@@ -576,14 +673,21 @@ func (n *NullType) SetDeprecated(deprecated bool, notice string) {
 	n.documentation.deprecated = deprecated
 }
 
+// SetDeprecated sets the deprecated field value
+func (c *CompositeType) SetDeprecated(deprecated bool, notice string) {
+	c.documentation.deprecationNotice = notice
+	c.documentation.deprecated = deprecated
+}
+
 // GetValidation provides the validation from @schema/validation for a node
 func (t *DocumentType) GetValidation() *validations.NodeValidation {
 	return t.validations
 }
 
-// GetValidation provides the validation from @schema/validation for a node
+// GetValidation provides the validation from @schema/required-if, @schema/min-properties, and @schema/max-properties
+// for a node
 func (m *MapType) GetValidation() *validations.NodeValidation {
-	return nil
+	return m.validations
 }
 
 // GetValidation provides the validation from @schema/validation for a node
@@ -616,6 +720,29 @@ func (n NullType) GetValidation() *validations.NodeValidation {
 	return nil
 }
 
+// GetValidation provides the validation from @schema/validation for a node
+func (c *CompositeType) GetValidation() *validations.NodeValidation {
+	return nil
+}
+
+func (c *CompositeType) hasAlternative(name string) bool {
+	for _, alt := range c.Alternatives {
+		if alt.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MapType) hasKey(key interface{}) bool {
+	for _, item := range m.Items {
+		if item.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
 // String produces a user-friendly name of the expected type.
 func (t *DocumentType) String() string {
 	return yamlmeta.TypeName(&yamlmeta.Document{})
@@ -655,3 +782,17 @@ func (a AnyType) String() string {
 func (n NullType) String() string {
 	return "null"
 }
+
+// String produces a user-friendly name of the expected type.
+func (c *CompositeType) String() string {
+	var names []string
+	for _, alt := range c.Alternatives {
+		names = append(names, alt.Name)
+	}
+	quantifier := map[Composition]string{
+		CompositionOneOf: "exactly one of",
+		CompositionAnyOf: "at least one of",
+		CompositionAllOf: "all of",
+	}[c.Composition]
+	return fmt.Sprintf("%s: %s", quantifier, strings.Join(names, ", "))
+}
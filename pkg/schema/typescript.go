@@ -0,0 +1,203 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/vmware-tanzu/carvel-ytt/pkg/yamlmeta"
+)
+
+// TypeScriptDocument describes data values as TypeScript type definitions (e.g. for a frontend that consumes them):
+// an interface named "DataValues".
+//
+// It is rendered from the same description of each value as is the OpenAPIDocument it wraps (and so respects how
+// that document is configured).
+type TypeScriptDocument struct {
+	openAPIDoc *OpenAPIDocument
+}
+
+// NewTypeScriptDocument creates an instance of a TypeScriptDocument that renders the values described by "openAPIDoc".
+func NewTypeScriptDocument(openAPIDoc *OpenAPIDocument) *TypeScriptDocument {
+	return &TypeScriptDocument{openAPIDoc: openAPIDoc}
+}
+
+// AsBytes renders this document as TypeScript.
+func (t *TypeScriptDocument) AsBytes() []byte {
+	var ts bytes.Buffer
+	ts.WriteString("// Types of data values, generated by ytt\n\n")
+
+	root := t.openAPIDoc.calculateDataValuesProperties()
+	writeJSDoc(&ts, "", root)
+	if hasProp(root, propertiesProp) {
+		ts.WriteString("export interface DataValues ")
+		writeObjectType(&ts, "", root)
+		ts.WriteString("\n")
+	} else {
+		ts.WriteString("export type DataValues = " + typeScriptType("", root) + ";\n")
+	}
+	return ts.Bytes()
+}
+
+// typeScriptType renders the type described by the schema "properties" (at the given level of "indent").
+func typeScriptType(indent string, properties *yamlmeta.Map) string {
+	var types []string
+	nullable := false
+	var enum, alternatives *yamlmeta.Array
+	composition := ""
+	for _, prop := range properties.Items {
+		switch prop.Key {
+		case typeProp:
+			if typeList, isList := prop.Value.(*yamlmeta.Array); isList {
+				for _, typeName := range typeList.Items {
+					types = append(types, fmt.Sprintf("%v", typeName.Value))
+				}
+			} else {
+				types = append(types, fmt.Sprintf("%v", prop.Value))
+			}
+		case nullableProp:
+			nullable = prop.Value == true
+		case enumProp:
+			enum = prop.Value.(*yamlmeta.Array)
+		case oneOfProp, anyOfProp, allOfProp:
+			alternatives = prop.Value.(*yamlmeta.Array)
+			composition = fmt.Sprintf("%v", prop.Key)
+		}
+	}
+
+	var members []string
+	switch {
+	case enum != nil:
+		for _, value := range enum.Items {
+			if value.Value == nil {
+				nullable = true
+				continue
+			}
+			members = append(members, inlineJSON(value.Value))
+		}
+	case alternatives != nil:
+		var altTypes []string
+		for _, alt := range alternatives.Items {
+			altTypes = append(altTypes, typeScriptType(indent, alt.Value.(*yamlmeta.Map)))
+		}
+		if composition == allOfProp {
+			members = append(members, strings.Join(altTypes, " & "))
+		} else {
+			members = append(members, altTypes...)
+		}
+	case len(types) == 0:
+		members = append(members, "unknown")
+	default:
+		for _, typeName := range types {
+			switch typeName {
+			case nullTypeName:
+				nullable = true
+			case "string", "boolean":
+				members = append(members, typeName)
+			case "integer", "number":
+				members = append(members, "number")
+			case "array":
+				members = append(members, typeScriptArrayType(indent, properties))
+			case "object":
+				var obj bytes.Buffer
+				writeObjectType(&obj, indent, properties)
+				members = append(members, obj.String())
+			default:
+				members = append(members, "unknown")
+			}
+		}
+	}
+	if nullable && len(members) > 0 && members[0] != "unknown" {
+		members = append(members, "null")
+	}
+	return strings.Join(members, " | ")
+}
+
+func typeScriptArrayType(indent string, properties *yamlmeta.Map) string {
+	for _, prop := range properties.Items {
+		if prop.Key == itemsProp {
+			itemType := typeScriptType(indent, prop.Value.(*yamlmeta.Map))
+			if strings.ContainsAny(itemType, "|&") && !strings.HasPrefix(itemType, "{") {
+				itemType = "(" + itemType + ")"
+			}
+			return itemType + "[]"
+		}
+	}
+	return "unknown[]"
+}
+
+// writeObjectType renders the object described by the schema "properties" as a TypeScript object type (its members
+// indented one level deeper than "indent").
+func writeObjectType(ts *bytes.Buffer, indent string, properties *yamlmeta.Map) {
+	var members *yamlmeta.Map
+	additional := false
+	for _, prop := range properties.Items {
+		switch prop.Key {
+		case propertiesProp:
+			members = prop.Value.(*yamlmeta.Map)
+		case additionalPropsProp:
+			additional = prop.Value == true
+		}
+	}
+
+	memberIndent := indent + "  "
+	ts.WriteString("{\n")
+	if members != nil {
+		for _, member := range members.Items {
+			memberProps := member.Value.(*yamlmeta.Map)
+			writeJSDoc(ts, memberIndent, memberProps)
+			// a value that can be null need not be given.
+			optional := ""
+			if admitsNull(memberProps) {
+				optional = "?"
+			}
+			fmt.Fprintf(ts, "%s%s%s: %s;\n", memberIndent, typeScriptKey(fmt.Sprintf("%v", member.Key)), optional, typeScriptType(memberIndent, memberProps))
+		}
+	}
+	if additional {
+		fmt.Fprintf(ts, "%s[key: string]: unknown;\n", memberIndent)
+	}
+	ts.WriteString(indent + "}")
+}
+
+// writeJSDoc renders the description (and deprecation) of the value described by "properties" as a JSDoc comment.
+func writeJSDoc(ts *bytes.Buffer, indent string, properties *yamlmeta.Map) {
+	var lines []string
+	for _, prop := range properties.Items {
+		switch prop.Key {
+		case descriptionProp:
+			description := strings.ReplaceAll(fmt.Sprintf("%v", prop.Value), "*/", "*\\/")
+			lines = append(lines, strings.Split(strings.TrimSpace(description), "\n")...)
+		case deprecatedProp:
+			if prop.Value == true {
+				lines = append(lines, "@deprecated")
+			}
+		}
+	}
+	switch len(lines) {
+	case 0:
+		return
+	case 1:
+		fmt.Fprintf(ts, "%s/** %s */\n", indent, lines[0])
+	default:
+		fmt.Fprintf(ts, "%s/**\n", indent)
+		for _, line := range lines {
+			fmt.Fprintf(ts, "%s * %s\n", indent, line)
+		}
+		fmt.Fprintf(ts, "%s */\n", indent)
+	}
+}
+
+var typeScriptIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// typeScriptKey renders "key" as the name of a member of an object type (quoted, when it is not an identifier).
+func typeScriptKey(key string) string {
+	if typeScriptIdentifier.MatchString(key) {
+		return key
+	}
+	return fmt.Sprintf("%q", key)
+}
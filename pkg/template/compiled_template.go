@@ -125,7 +125,9 @@ func (e *CompiledTemplate) Eval(thread *starlark.Thread, loader CompiledTemplate
 
 	updatedGlobals, val, err := e.eval(thread, globals)
 	if err != nil {
-		return nil, nil, NewCompiledTemplateMultiError(err, loader)
+		multiErr := NewCompiledTemplateMultiError(err, loader).(CompiledTemplateMultiError)
+		multiErr.cause = tplcore.ErrCause(thread, err.Error())
+		return nil, nil, multiErr
 	}
 
 	// Since load statement does not allow importing
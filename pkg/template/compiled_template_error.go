@@ -18,6 +18,7 @@ import (
 type CompiledTemplateMultiError struct {
 	errs   []CompiledTemplateError
 	loader CompiledTemplateLoader
+	cause  error // of the evaluation error, if known (see core.RecordErrCause)
 }
 
 var _ error = CompiledTemplateMultiError{}
@@ -64,6 +65,11 @@ func NewCompiledTemplateMultiError(err error, loader CompiledTemplateLoader) err
 	return e
 }
 
+// Unwrap provides the error that caused evaluation to fail, if known (e.g. data values of a library that are invalid).
+func (e CompiledTemplateMultiError) Unwrap() error {
+	return e.cause
+}
+
 func (e CompiledTemplateMultiError) Error() string {
 	result := []string{""}
 
@@ -5,6 +5,7 @@ package core
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/k14s/starlark-go/starlark"
 	"runtime/debug"
@@ -43,3 +44,22 @@ func ErrDescWrapper(desc string, wrappedFunc StarlarkFunc) StarlarkFunc {
 		return val, nil
 	}
 }
+
+// errCauseKey is the thread-local under which the error recorded by RecordErrCause is kept.
+const errCauseKey = "ytt.err-cause"
+
+// RecordErrCause keeps "err" (as returned by a builtin) on "thread". Starlark retains only the message of an error
+// that ends evaluation; the recorded error can be recovered with ErrCause (e.g. to tell what kind of failure it was).
+func RecordErrCause(thread *starlark.Thread, err error) {
+	thread.SetLocal(errCauseKey, err)
+}
+
+// ErrCause provides the error recorded on "thread" via RecordErrCause, if it is (part of) what ended evaluation with
+// the message "msg".
+func ErrCause(thread *starlark.Thread, msg string) error {
+	cause, ok := thread.Local(errCauseKey).(error)
+	if !ok || !strings.Contains(msg, cause.Error()) {
+		return nil
+	}
+	return cause
+}
@@ -5,27 +5,98 @@ package validations
 
 import (
 	"fmt"
+	"regexp"
 
 	"github.com/k14s/starlark-go/starlark"
+	"github.com/k14s/starlark-go/syntax"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/filepos"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/template"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/template/core"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/yamlmeta"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/yttlibrary"
 )
 
 // Declare @assert/... annotation and keyword argument names
 const (
 	AnnotationAssertValidate template.AnnotationName = "assert/validate"
 
-	KwargWhen       string = "when"
-	KwargMinLength  string = "min_len"
-	KwargMaxLength  string = "max_len"
-	KwargMin        string = "min"
-	KwargMax        string = "max"
-	KwargNotNull    string = "not_null"
-	KwargOneNotNull string = "one_not_null"
-	KwargOneOf      string = "one_of"
+	KwargWhen             string = "when"
+	KwargMinLength        string = "min_len"
+	KwargMaxLength        string = "max_len"
+	KwargMin              string = "min"
+	KwargMax              string = "max"
+	KwargNotNull          string = "not_null"
+	KwargOneNotNull       string = "one_not_null"
+	KwargOneOf            string = "one_of"
+	KwargNotOneOf         string = "not_one_of"
+	KwargMaxDecimals      string = "max_decimals"
+	KwargSorted           string = "sorted"
+	KwargEach             string = "each"
+	KwargLowercase        string = "lowercase"
+	KwargUppercase        string = "uppercase"
+	KwargExclusiveMin     string = "exclusive_min"
+	KwargExclusiveMax     string = "exclusive_max"
+	KwargEquals           string = "equals"
+	KwargRequiredTogether string = "required_together"
+	KwargRequiredKeys     string = "required_keys"
+	KwargPattern          string = "pattern"
+	KwargWhenNullSkip     string = "when_null_skip"
+	KwargDuration         string = "duration"
+	KwargTimestamp        string = "timestamp"
+	KwargLenEquals        string = "len_equals"
+	KwargIP               string = "ip"
+	KwargCIDR             string = "cidr"
+	KwargStartsWith       string = "starts_with"
+	KwargEndsWith         string = "ends_with"
+	KwargURL              string = "url"
+	KwargURLSchemes       string = "url_schemes"
+	KwargWhenTruthy       string = "when_truthy"
+	KwargWhenFalsy        string = "when_falsy"
+	KwargExperimental     string = "experimental"
+	KwargTolerance        string = "tolerance"
+	KwargSemver           string = "semver"
+	KwargSemverRange      string = "semver_range"
+	KwargInRange          string = "in_range"
+	KwargBitFlags         string = "bit_flags"
+	KwargValues           string = "values"
+	KwargKeyOf            string = "key_of"
+	KwargDistinctFrom     string = "distinct_from"
+	KwargValidYAML        string = "valid_yaml"
+	KwargValidJSON        string = "valid_json"
 )
 
+var builtinKwargs = []string{KwargWhen, KwargMinLength, KwargMaxLength, KwargMin, KwargMax, KwargNotNull,
+	KwargOneNotNull, KwargOneOf, KwargNotOneOf, KwargMaxDecimals, KwargSorted, KwargEach, KwargLowercase, KwargUppercase,
+	KwargExclusiveMin, KwargExclusiveMax, KwargEquals, KwargRequiredTogether, KwargPattern, KwargWhenNullSkip,
+	KwargDuration, KwargTimestamp, KwargLenEquals, KwargIP, KwargCIDR,
+	KwargStartsWith, KwargEndsWith, KwargURL, KwargURLSchemes, KwargWhenTruthy, KwargWhenFalsy,
+	KwargExperimental, KwargTolerance, KwargSemver, KwargSemverRange,
+	KwargInRange, KwargRequiredKeys, KwargBitFlags, KwargValues, KwargKeyOf,
+	KwargDistinctFrom, KwargValidYAML, KwargValidJSON}
+
+// KwargParser interprets the value given to a custom keyword argument (see RegisterKwarg), producing a description
+// of what constitutes a valid value and the assertion that enforces it.
+type KwargParser func(value starlark.Value) (msg string, assertion starlark.Callable, err error)
+
+var registeredKwargs = map[string]KwargParser{}
+
+// RegisterKwarg adds a keyword argument named "name" to validation annotations (i.e. @assert/validate and
+// @schema/validation), whose value is interpreted by "parse".
+//
+// Integrators using ytt as a Go module register their keyword arguments before executing any templates. Registering
+// the name of a built-in keyword argument, or registering the same name twice, panics.
+func RegisterKwarg(name string, parse KwargParser) {
+	for _, builtin := range builtinKwargs {
+		if name == builtin {
+			panic(fmt.Sprintf("validation keyword argument %q is built-in and cannot be registered", name))
+		}
+	}
+	if _, found := registeredKwargs[name]; found {
+		panic(fmt.Sprintf("validation keyword argument %q is already registered", name))
+	}
+	registeredKwargs[name] = parse
+}
+
 // ProcessAssertValidateAnns checks Assert annotations on data values and stores them on a Node as Validations.
 // Returns an error if any Assert annotations are malformed.
 func ProcessAssertValidateAnns(rootNode yamlmeta.Node) error {
@@ -71,7 +142,20 @@ func NewValidationFromAnn(annotation template.NodeAnnotation) (*NodeValidation,
 	for _, arg := range annotation.Args {
 		ruleTuple, ok := arg.(starlark.Tuple)
 		if !ok {
-			return nil, fmt.Errorf("expected annotation to have 2-tuple as argument(s), but found: %s (by %s)", arg.String(), annotation.Position.AsCompactString())
+			// an assertion object describes itself: its name is the message.
+			assertion, name, err := assertionFromCheckAttr(arg)
+			if err != nil {
+				return nil, fmt.Errorf("expected annotation to have 2-tuple as argument(s), but found: %s (by %s)", arg.String(), annotation.Position.AsCompactString())
+			}
+			if name == "" {
+				return nil, fmt.Errorf("expected assertion object given without a message to have a name, but it has none (hint: give a message, i.e. (\"<message>\", assertion)) (at %s)", annotation.Position.AsCompactString())
+			}
+			rules = append(rules, rule{
+				msg:       name,
+				name:      name,
+				assertion: assertion,
+			})
+			continue
 		}
 		if len(ruleTuple) != 2 {
 			return nil, fmt.Errorf("expected 2-tuple, but found tuple with length %v (by %s)", len(ruleTuple), annotation.Position.AsCompactString())
@@ -82,16 +166,18 @@ func NewValidationFromAnn(annotation template.NodeAnnotation) (*NodeValidation,
 			return nil, fmt.Errorf("expected first item in the 2-tuple to be a string describing a valid value, but was %s (at %s)", ruleTuple[0].Type(), annotation.Position.AsCompactString())
 		}
 
+		var name string
 		assertion, ok := ruleTuple[1].(starlark.Callable)
 		if !ok {
 			var err error
-			assertion, err = assertionFromCheckAttr(ruleTuple[1])
+			assertion, name, err = assertionFromCheckAttr(ruleTuple[1])
 			if err != nil {
 				return nil, fmt.Errorf("%s (at %s)", err, annotation.Position.AsCompactString())
 			}
 		}
 		rules = append(rules, rule{
 			msg:       message.GoString(),
+			name:      name,
 			assertion: assertion,
 		})
 	}
@@ -100,63 +186,333 @@ func NewValidationFromAnn(annotation template.NodeAnnotation) (*NodeValidation,
 		return nil, err
 	}
 
-	rules = append(rules, kwargs.asRules()...)
+	for _, kwargRule := range kwargs.asRules() {
+		kwargRule.fromKwarg = true
+		rules = append(rules, kwargRule)
+	}
 
 	return &NodeValidation{rules, kwargs, annotation.Position}, nil
 }
 
-func assertionFromCheckAttr(value starlark.Value) (starlark.Callable, error) {
+// NewValidationFromAssertions creates a NodeValidation whose rules are given "assertions", each described by the
+// message at the same index in "msgs".
+func NewValidationFromAssertions(msgs []string, assertions []starlark.Callable, position *filepos.Position) *NodeValidation {
+	var rules []rule
+	for idx, assertion := range assertions {
+		rules = append(rules, rule{
+			msg:       msgs[idx],
+			assertion: assertion,
+		})
+	}
+	return &NodeValidation{rules: rules, position: position}
+}
+
+// Merge produces a NodeValidation with the rules of "v" followed by those of "other". Each rule continues to report
+// the position of the validation in which it was declared; the keyword arguments (e.g. when=) of "v" apply to all.
+func Merge(v, other *NodeValidation) *NodeValidation {
+	if v == nil {
+		return other
+	}
+	if other == nil {
+		return v
+	}
+	var rules []rule
+	for _, validation := range []*NodeValidation{v, other} {
+		for _, r := range validation.rules {
+			if r.position == nil {
+				r.position = validation.position
+			}
+			rules = append(rules, r)
+		}
+	}
+	return &NodeValidation{rules: rules, kwargs: v.kwargs, position: v.position}
+}
+
+// asStrings provides the items of "seq", each of which must be a string.
+func asStrings(seq starlark.Sequence) ([]string, error) {
+	var strs []string
+	iter := seq.Iterate()
+	defer iter.Done()
+	var item starlark.Value
+	for iter.Next(&item) {
+		str, ok := item.(starlark.String)
+		if !ok {
+			return nil, fmt.Errorf("expected a string, but was %s", item.Type())
+		}
+		strs = append(strs, str.GoString())
+	}
+	return strs, nil
+}
+
+// assertionFromCheckAttr extracts the assertion function from the "check" attribute of "value" along with the name
+// of that assertion, if "value" has a "name" attribute.
+func assertionFromCheckAttr(value starlark.Value) (starlark.Callable, string, error) {
 	val, hasAttrs := value.(starlark.HasAttrs)
 	if !hasAttrs {
-		return nil, fmt.Errorf("expected second item in the 2-tuple to be an assertion function, but was %s", value.Type())
+		return nil, "", fmt.Errorf("expected second item in the 2-tuple to be an assertion function, but was %s", value.Type())
 	}
 
 	checkAttr, err := val.Attr("check")
 	if err != nil || (checkAttr == nil && err == nil) {
-		return nil, fmt.Errorf("expected second item in tuple to be an assertion function or assertion object, but was a %s", value.Type())
+		return nil, "", fmt.Errorf("expected second item in tuple to be an assertion function or assertion object, but was a %s", value.Type())
 	}
 
 	assertionFunc, ok := checkAttr.(starlark.Callable)
 	if !ok {
-		return nil, fmt.Errorf("expected struct with attribute check(), but was %s", checkAttr.Type())
+		return nil, "", fmt.Errorf("expected struct with attribute check(), but was %s", checkAttr.Type())
 	}
 
-	return assertionFunc, nil
+	var name string
+	nameAttr, err := val.Attr("name")
+	if err == nil && nameAttr != nil {
+		nameStr, ok := nameAttr.(starlark.String)
+		if !ok {
+			return nil, "", fmt.Errorf("expected attribute name of assertion object to be a string, but was %s", nameAttr.Type())
+		}
+		name = nameStr.GoString()
+	}
+
+	return assertionFunc, name, nil
 }
 
 // newValidationKwargs takes the keyword arguments from a Validation annotation,
 // and makes sure they are well-formed.
-func newValidationKwargs(kwargs []starlark.Tuple, annPos *filepos.Position) (validationKwargs, error) {
-	var processedKwargs validationKwargs
+func newValidationKwargs(kwargs []starlark.Tuple, annPos *filepos.Position) (ValidationKwargs, error) {
+	processedKwargs := ValidationKwargs{given: kwargs}
+	// in_range= is a shorthand for min= and max=: it cannot be given along with either.
+	givenBound, givenRange := false, false
 	for _, value := range kwargs {
 		kwargName := string(value[0].(starlark.String))
 		switch kwargName {
 		case KwargWhen:
 			v, ok := value[1].(starlark.Callable)
 			if !ok {
-				return validationKwargs{}, fmt.Errorf("expected keyword argument %q to be a function, but was %s (at %s)", KwargWhen, value[1].Type(), annPos.AsCompactString())
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a function, but was %s (at %s)", KwargWhen, value[1].Type(), annPos.AsCompactString())
 			}
 			processedKwargs.when = v
+		case KwargWhenTruthy, KwargWhenFalsy:
+			v, ok := value[1].(starlark.String)
+			if !ok || v.GoString() == "" {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be the path of a value (e.g. \"tls.enabled\"), but was %s (at %s)", kwargName, value[1].String(), annPos.AsCompactString())
+			}
+			if kwargName == KwargWhenTruthy {
+				processedKwargs.whenTruthy = v
+			} else {
+				processedKwargs.whenFalsy = v
+			}
+		case KwargExperimental:
+			v, ok := value[1].(starlark.Bool)
+			if !ok {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a boolean, but was %s (at %s)", KwargExperimental, value[1].Type(), annPos.AsCompactString())
+			}
+			processedKwargs.experimental = bool(v)
+		case KwargWhenNullSkip:
+			v, ok := value[1].(starlark.Bool)
+			if !ok {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a boolean, but was %s (at %s)", KwargWhenNullSkip, value[1].Type(), annPos.AsCompactString())
+			}
+			whenNullSkip := bool(v)
+			processedKwargs.whenNullSkip = &whenNullSkip
 		case KwargMinLength:
 			v, err := starlark.NumberToInt(value[1])
 			if err != nil {
-				return validationKwargs{}, fmt.Errorf("expected keyword argument %q to be a number, but was %s (at %s)", KwargMinLength, value[1].Type(), annPos.AsCompactString())
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a number, but was %s (at %s)", KwargMinLength, value[1].Type(), annPos.AsCompactString())
 			}
 			processedKwargs.minLength = &v
 		case KwargMaxLength:
 			v, err := starlark.NumberToInt(value[1])
 			if err != nil {
-				return validationKwargs{}, fmt.Errorf("expected keyword argument %q to be a number, but was %s (at %s)", KwargMaxLength, value[1].Type(), annPos.AsCompactString())
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a number, but was %s (at %s)", KwargMaxLength, value[1].Type(), annPos.AsCompactString())
 			}
 			processedKwargs.maxLength = &v
+		case KwargMaxDecimals:
+			v, err := starlark.NumberToInt(value[1])
+			if err != nil {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a number, but was %s (at %s)", KwargMaxDecimals, value[1].Type(), annPos.AsCompactString())
+			}
+			processedKwargs.maxDecimals = &v
+		case KwargSorted:
+			switch v := value[1].(type) {
+			case starlark.Bool:
+				if v {
+					processedKwargs.sorted = yttlibrary.SortedAscending
+				}
+			case starlark.String:
+				if v != yttlibrary.SortedAscending && v != yttlibrary.SortedDescending {
+					return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be True, %s, or %s, but was %s (at %s)", KwargSorted, yttlibrary.SortedAscending.String(), yttlibrary.SortedDescending.String(), v.String(), annPos.AsCompactString())
+				}
+				processedKwargs.sorted = v
+			default:
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a boolean or a string, but was %s (at %s)", KwargSorted, value[1].Type(), annPos.AsCompactString())
+			}
+		case KwargEach:
+			assertion, ok := value[1].(starlark.Callable)
+			name := ""
+			if !ok {
+				var err error
+				assertion, name, err = assertionFromCheckAttr(value[1])
+				if err != nil {
+					return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a function or assertion object, but was %s (at %s)", KwargEach, value[1].Type(), annPos.AsCompactString())
+				}
+			}
+			processedKwargs.each = assertion
+			processedKwargs.eachName = name
+		case KwargValues:
+			assertion, ok := value[1].(starlark.Callable)
+			name := ""
+			if !ok {
+				var err error
+				assertion, name, err = assertionFromCheckAttr(value[1])
+				if err != nil {
+					return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a function or assertion object, but was %s (at %s)", KwargValues, value[1].Type(), annPos.AsCompactString())
+				}
+			}
+			processedKwargs.values = assertion
+			processedKwargs.valuesName = name
+		case KwargDuration, KwargTimestamp:
+			v, ok := value[1].(starlark.Bool)
+			if !ok {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a boolean, but was %s (at %s)", kwargName, value[1].Type(), annPos.AsCompactString())
+			}
+			if kwargName == KwargDuration {
+				processedKwargs.duration = bool(v)
+			} else {
+				processedKwargs.timestamp = bool(v)
+			}
+		case KwargValidYAML, KwargValidJSON:
+			v, ok := value[1].(starlark.Bool)
+			if !ok {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a boolean, but was %s (at %s)", kwargName, value[1].Type(), annPos.AsCompactString())
+			}
+			if kwargName == KwargValidYAML {
+				processedKwargs.validYAML = bool(v)
+			} else {
+				processedKwargs.validJSON = bool(v)
+			}
+		case KwargStartsWith, KwargEndsWith:
+			v, ok := value[1].(starlark.String)
+			if !ok {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a string, but was %s (at %s)", kwargName, value[1].Type(), annPos.AsCompactString())
+			}
+			if kwargName == KwargStartsWith {
+				processedKwargs.startsWith = v
+			} else {
+				processedKwargs.endsWith = v
+			}
+		case KwargIP, KwargCIDR:
+			// either True (of any version) or the version required: "v4" or "v6".
+			var version string
+			switch v := value[1].(type) {
+			case starlark.Bool:
+				if v {
+					version = yttlibrary.IPVersionAny
+				}
+			case starlark.String:
+				if v.GoString() != yttlibrary.IPVersion4 && v.GoString() != yttlibrary.IPVersion6 {
+					return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be True, %q, or %q, but was %s (at %s)", kwargName, yttlibrary.IPVersion4, yttlibrary.IPVersion6, v.String(), annPos.AsCompactString())
+				}
+				version = v.GoString()
+			default:
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a boolean or string, but was %s (at %s)", kwargName, value[1].Type(), annPos.AsCompactString())
+			}
+			if kwargName == KwargIP {
+				processedKwargs.ip = version
+			} else {
+				processedKwargs.cidr = version
+			}
+		case KwargLowercase, KwargUppercase:
+			v, ok := value[1].(starlark.Bool)
+			if !ok {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a boolean, but was %s (at %s)", kwargName, value[1].Type(), annPos.AsCompactString())
+			}
+			if kwargName == KwargLowercase {
+				processedKwargs.lowercase = bool(v)
+			} else {
+				processedKwargs.uppercase = bool(v)
+			}
 		case KwargMin:
 			processedKwargs.min = value[1]
+			givenBound = true
 		case KwargMax:
 			processedKwargs.max = value[1]
+			givenBound = true
+		case KwargInRange:
+			var v starlark.Indexable
+			switch typed := value[1].(type) {
+			case starlark.Tuple:
+				v = typed
+			case *starlark.List:
+				v = typed
+			}
+			if v == nil || v.Len() != 2 {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a 2-tuple of the (inclusive) bounds (e.g. (1, 100)), but was %s (at %s)", KwargInRange, value[1].String(), annPos.AsCompactString())
+			}
+			reversed, err := starlark.Compare(syntax.GT, v.Index(0), v.Index(1))
+			if err != nil {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be bounds that can be compared with each other, but was %s (at %s)", KwargInRange, value[1].String(), annPos.AsCompactString())
+			}
+			if reversed {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to give the lower bound first (e.g. (1, 100)), but was %s (at %s)", KwargInRange, value[1].String(), annPos.AsCompactString())
+			}
+			processedKwargs.min = v.Index(0)
+			processedKwargs.max = v.Index(1)
+			givenRange = true
+		case KwargBitFlags:
+			flags, err := bitFlagsFrom(value[1])
+			if err != nil {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a sequence of flags (non-negative integers), but %s (at %s)", KwargBitFlags, err, annPos.AsCompactString())
+			}
+			processedKwargs.bitFlags = flags
+		case KwargExclusiveMin:
+			processedKwargs.exclusiveMin = value[1]
+		case KwargExclusiveMax:
+			processedKwargs.exclusiveMax = value[1]
+		case KwargTolerance:
+			v, ok := starlark.AsFloat(value[1])
+			if !ok || v < 0 {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a non-negative number, but was %s (at %s)", KwargTolerance, value[1].String(), annPos.AsCompactString())
+			}
+			processedKwargs.tolerance = &v
+		case KwargEquals:
+			v, ok := value[1].(starlark.String)
+			if !ok {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a string, but was %s (at %s)", KwargEquals, value[1].Type(), annPos.AsCompactString())
+			}
+			processedKwargs.equals = v
+		case KwargDistinctFrom:
+			v, ok := value[1].(starlark.String)
+			if !ok {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a string, but was %s (at %s)", KwargDistinctFrom, value[1].Type(), annPos.AsCompactString())
+			}
+			processedKwargs.distinctFrom = v
+		case KwargKeyOf:
+			v, ok := value[1].(starlark.String)
+			if !ok || v.GoString() == "" {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be the path of a map (e.g. \"databases\"), but was %s (at %s)", KwargKeyOf, value[1].String(), annPos.AsCompactString())
+			}
+			processedKwargs.keyOf = v
+		case KwargLenEquals:
+			v, ok := value[1].(starlark.String)
+			if !ok {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a string, but was %s (at %s)", KwargLenEquals, value[1].Type(), annPos.AsCompactString())
+			}
+			processedKwargs.lenEquals = v
+		case KwargPattern:
+			// the pattern may well be a constant defined elsewhere (e.g. a loaded library of named patterns);
+			// either way, it is a string by the time it arrives here.
+			v, ok := value[1].(starlark.String)
+			if !ok {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a string, but was %s (at %s)", KwargPattern, value[1].Type(), annPos.AsCompactString())
+			}
+			re, err := regexp.Compile(v.GoString())
+			if err != nil {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a valid regular expression, but %s (at %s)", KwargPattern, err, annPos.AsCompactString())
+			}
+			processedKwargs.pattern = re
 		case KwargNotNull:
 			v, ok := value[1].(starlark.Bool)
 			if !ok {
-				return validationKwargs{}, fmt.Errorf("expected keyword argument %q to be a boolean, but was %s (at %s)", KwargNotNull, value[1].Type(), annPos.AsCompactString())
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a boolean, but was %s (at %s)", KwargNotNull, value[1].Type(), annPos.AsCompactString())
 			}
 			processedKwargs.notNull = bool(v)
 		case KwargOneNotNull:
@@ -165,22 +521,201 @@ func newValidationKwargs(kwargs []starlark.Tuple, annPos *filepos.Position) (val
 				if v {
 					processedKwargs.oneNotNull = v
 				} else {
-					return validationKwargs{}, fmt.Errorf("one_not_null= cannot be False")
+					return ValidationKwargs{}, fmt.Errorf("one_not_null= cannot be False")
 				}
 			case starlark.Sequence:
+				if err := allScalars(v); err != nil {
+					return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a sequence of keys (strings or other scalars), but it %s (at %s)", KwargOneNotNull, err, annPos.AsCompactString())
+				}
 				processedKwargs.oneNotNull = v
 			default:
-				return validationKwargs{}, fmt.Errorf("expected True or a sequence of keys, but was a '%s'", value[1].Type())
+				return ValidationKwargs{}, fmt.Errorf("expected True or a sequence of keys, but was a '%s'", value[1].Type())
 			}
+		case KwargURL:
+			v, ok := value[1].(starlark.Bool)
+			if !ok {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a boolean, but was %s (at %s)", KwargURL, value[1].Type(), annPos.AsCompactString())
+			}
+			processedKwargs.url = bool(v)
+		case KwargURLSchemes:
+			v, ok := value[1].(starlark.Sequence)
+			if !ok {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a sequence of schemes, but was %s (at %s)", KwargURLSchemes, value[1].Type(), annPos.AsCompactString())
+			}
+			schemes, err := asStrings(v)
+			if err != nil || len(schemes) == 0 {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a (non-empty) sequence of schemes (e.g. [\"https\"]), but was %s (at %s)", KwargURLSchemes, value[1].String(), annPos.AsCompactString())
+			}
+			processedKwargs.urlSchemes = schemes
+		case KwargSemver:
+			v, ok := value[1].(starlark.Bool)
+			if !ok {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a boolean, but was %s (at %s)", KwargSemver, value[1].Type(), annPos.AsCompactString())
+			}
+			processedKwargs.semver = bool(v)
+		case KwargSemverRange:
+			v, ok := value[1].(starlark.String)
+			if !ok {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a string, but was %s (at %s)", KwargSemverRange, value[1].Type(), annPos.AsCompactString())
+			}
+			if _, err := yttlibrary.NewAssertSemver(v.GoString()); err != nil {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a range of versions (e.g. \">= 1.2.0, < 2.0.0\"), but %s (at %s)", KwargSemverRange, err, annPos.AsCompactString())
+			}
+			processedKwargs.semverRange = v.GoString()
+		case KwargRequiredTogether:
+			v, ok := value[1].(starlark.Sequence)
+			if !ok {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a sequence of keys, but was %s (at %s)", KwargRequiredTogether, value[1].Type(), annPos.AsCompactString())
+			}
+			if err := allScalars(v); err != nil {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a sequence of keys (strings or other scalars), but it %s (at %s)", KwargRequiredTogether, err, annPos.AsCompactString())
+			}
+			processedKwargs.requiredTogether = v
+		case KwargRequiredKeys:
+			v, ok := value[1].(starlark.Sequence)
+			if !ok {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a sequence of keys, but was %s (at %s)", KwargRequiredKeys, value[1].Type(), annPos.AsCompactString())
+			}
+			if err := allScalars(v); err != nil {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a sequence of keys (strings or other scalars), but it %s (at %s)", KwargRequiredKeys, err, annPos.AsCompactString())
+			}
+			processedKwargs.requiredKeys = v
 		case KwargOneOf:
 			v, ok := value[1].(starlark.Sequence)
 			if !ok {
-				return validationKwargs{}, fmt.Errorf("expected keyword argument %s to be a sequence, but was %s", KwargOneOf, value[1].Type())
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %s to be a sequence, but was %s", KwargOneOf, value[1].Type())
+			}
+			if err := yamlRepresentable(v); err != nil {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to contain only values that can be expressed in YAML, but it %s (at %s)", KwargOneOf, err, annPos.AsCompactString())
 			}
 			processedKwargs.oneOf = v
+		case KwargNotOneOf:
+			v, ok := value[1].(starlark.Sequence)
+			if !ok {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to be a sequence, but was %s (at %s)", KwargNotOneOf, value[1].Type(), annPos.AsCompactString())
+			}
+			if err := yamlRepresentable(v); err != nil {
+				return ValidationKwargs{}, fmt.Errorf("expected keyword argument %q to contain only values that can be expressed in YAML, but it %s (at %s)", KwargNotOneOf, err, annPos.AsCompactString())
+			}
+			processedKwargs.notOneOf = v
 		default:
-			return validationKwargs{}, fmt.Errorf("unknown keyword argument %q (at %s)", kwargName, annPos.AsCompactString())
+			parse, found := registeredKwargs[kwargName]
+			if !found {
+				return ValidationKwargs{}, fmt.Errorf("unknown keyword argument %q (at %s)", kwargName, annPos.AsCompactString())
+			}
+			msg, assertion, err := parse(value[1])
+			if err != nil {
+				return ValidationKwargs{}, fmt.Errorf("invalid keyword argument %q: %s (at %s)", kwargName, err, annPos.AsCompactString())
+			}
+			processedKwargs.custom = append(processedKwargs.custom, rule{msg: msg, assertion: assertion})
 		}
 	}
+	if len(processedKwargs.urlSchemes) > 0 && !processedKwargs.url {
+		return ValidationKwargs{}, fmt.Errorf("keyword argument %q restricts the schemes of a URL; it requires %s=True (at %s)", KwargURLSchemes, KwargURL, annPos.AsCompactString())
+	}
+	if givenRange && givenBound {
+		return ValidationKwargs{}, fmt.Errorf("keyword argument %q gives both bounds; it cannot be combined with %s= or %s= (at %s)", KwargInRange, KwargMin, KwargMax, annPos.AsCompactString())
+	}
+	inclusiveBound := KwargMin
+	if givenRange {
+		inclusiveBound = KwargInRange
+	}
+	if processedKwargs.min != nil && processedKwargs.exclusiveMin != nil {
+		return ValidationKwargs{}, fmt.Errorf("keyword argument %q gives a lower bound, as does %s=; give only one of them (at %s)", KwargExclusiveMin, inclusiveBound, annPos.AsCompactString())
+	}
+	if processedKwargs.max != nil && processedKwargs.exclusiveMax != nil {
+		if inclusiveBound == KwargMin {
+			inclusiveBound = KwargMax
+		}
+		return ValidationKwargs{}, fmt.Errorf("keyword argument %q gives an upper bound, as does %s=; give only one of them (at %s)", KwargExclusiveMax, inclusiveBound, annPos.AsCompactString())
+	}
+	if processedKwargs.semverRange != "" && !processedKwargs.semver {
+		return ValidationKwargs{}, fmt.Errorf("keyword argument %q restricts a semantic version; it requires %s=True (at %s)", KwargSemverRange, KwargSemver, annPos.AsCompactString())
+	}
+	if processedKwargs.tolerance != nil && processedKwargs.equals == "" && processedKwargs.oneOf == nil {
+		return ValidationKwargs{}, fmt.Errorf("keyword argument %q loosens comparisons of floats; it requires %s= or %s= (at %s)", KwargTolerance, KwargEquals, KwargOneOf, annPos.AsCompactString())
+	}
+	if processedKwargs.notNull && processedKwargs.whenNullSkip != nil && *processedKwargs.whenNullSkip {
+		return ValidationKwargs{}, fmt.Errorf("%s=True and %s=True contradict each other: a null value would never be checked (at %s)", KwargNotNull, KwargWhenNullSkip, annPos.AsCompactString())
+	}
 	return processedKwargs, nil
 }
+
+// bitFlagsFrom converts "value" — as given to bit_flags= — into the flags it lists.
+func bitFlagsFrom(value starlark.Value) ([]uint64, error) {
+	seq, ok := value.(starlark.Sequence)
+	if !ok {
+		return nil, fmt.Errorf("was %s", value.Type())
+	}
+	var flags []uint64
+	var item starlark.Value
+	iter := seq.Iterate()
+	defer iter.Done()
+	for iter.Next(&item) {
+		i, ok := item.(starlark.Int)
+		if !ok {
+			return nil, fmt.Errorf("included %s", item.String())
+		}
+		flag, ok := i.Uint64()
+		if !ok {
+			return nil, fmt.Errorf("included %s", item.String())
+		}
+		flags = append(flags, flag)
+	}
+	if len(flags) == 0 {
+		return nil, fmt.Errorf("was empty")
+	}
+	return flags, nil
+}
+
+// allScalars checks that each item in "seq" is a scalar (i.e. could be the key of a map), reporting the first that is not.
+func allScalars(seq starlark.Sequence) error {
+	var item starlark.Value
+	iter := seq.Iterate()
+	defer iter.Done()
+	for iter.Next(&item) {
+		switch item.(type) {
+		case starlark.String, starlark.Int, starlark.Float, starlark.Bool:
+		default:
+			return fmt.Errorf("included %s", item.Type())
+		}
+	}
+	return nil
+}
+
+// yamlRepresentable checks that "value" (including anything within it) can be expressed in YAML, reporting the first
+// thing that cannot (e.g. a function).
+func yamlRepresentable(value starlark.Value) error {
+	switch typed := value.(type) {
+	case core.UnconvertableStarlarkValue:
+		return fmt.Errorf("included %s", value.Type())
+	case starlark.NoneType, starlark.Bool, starlark.String, starlark.Int, starlark.Float, core.StarlarkValueToGoValueConversion:
+		return nil
+	case *starlark.Dict:
+		return yamlRepresentableItems(typed.Items())
+	case *core.StarlarkStruct:
+		return yamlRepresentableItems(typed.Items())
+	case *starlark.List, starlark.Tuple, *starlark.Set:
+		var item starlark.Value
+		iter := typed.(starlark.Iterable).Iterate()
+		defer iter.Done()
+		for iter.Next(&item) {
+			if err := yamlRepresentable(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("included %s", value.Type())
+}
+
+func yamlRepresentableItems(items []starlark.Tuple) error {
+	for _, item := range items {
+		for _, v := range item {
+			if err := yamlRepresentable(v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
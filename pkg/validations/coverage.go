@@ -0,0 +1,47 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package validations
+
+import (
+	"github.com/vmware-tanzu/carvel-ytt/pkg/yamlmeta"
+)
+
+// Unvalidated provides the paths (e.g. "db.port", "hosts[0]") of the leaf values within "node" (i.e. those that are
+// neither a map nor an array) that have no validations (either from @assert/validate or @schema/validation), in the
+// order they appear. Use it to audit which values are left unchecked.
+func Unvalidated(node yamlmeta.Node) ([]string, error) {
+	if node == nil {
+		return nil, nil
+	}
+	leaves := &unvalidatedLeaves{}
+	err := yamlmeta.WalkWithParent(node, nil, "", leaves)
+	if err != nil {
+		return nil, err
+	}
+	return leaves.paths, nil
+}
+
+type unvalidatedLeaves struct {
+	paths []string
+}
+
+// VisitWithParent collects the path of "node" if it holds a leaf value and has no validations.
+func (u *unvalidatedLeaves) VisitWithParent(node yamlmeta.Node, _ yamlmeta.Node, path string) error {
+	switch node.(type) {
+	case *yamlmeta.Document, *yamlmeta.MapItem, *yamlmeta.ArrayItem:
+	default:
+		return nil
+	}
+	switch node.GetValues()[0].(type) {
+	case *yamlmeta.Map, *yamlmeta.Array:
+		return nil
+	}
+	if len(Get(node)) == 0 {
+		if path == "" {
+			path = "(" + yamlmeta.TypeName(node) + ")"
+		}
+		u.paths = append(u.paths, path)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package validations
+
+import (
+	"regexp"
+	"strings"
+)
+
+// PathFilter selects values by their path (e.g. "db.port", "hosts[0]"), using globs: "*" matches any part of a single
+// key (i.e. up to a "."); "**", any part of the path. For example, "db.**" matches every value within "db".
+type PathFilter struct {
+	Include []string // (optional) a path must match one of these; when none are given, every path is included.
+	Exclude []string // a path must match none of these.
+}
+
+// Selects reports whether "path" is included and not excluded by this filter.
+func (f PathFilter) Selects(path string) bool {
+	if len(f.Include) > 0 && !matchesAny(f.Include, path) {
+		return false
+	}
+	return !matchesAny(f.Exclude, path)
+}
+
+func matchesAny(globs []string, path string) bool {
+	for _, glob := range globs {
+		if globAsRegexp(glob).MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// globAsRegexp compiles "glob" as a regular expression matching the whole of a path.
+func globAsRegexp(glob string) *regexp.Regexp {
+	var re strings.Builder
+	re.WriteString("^")
+	for idx, part := range strings.Split(glob, "**") {
+		if idx > 0 {
+			re.WriteString(".*")
+		}
+		for jdx, segment := range strings.Split(part, "*") {
+			if jdx > 0 {
+				re.WriteString(`[^.]*`)
+			}
+			re.WriteString(regexp.QuoteMeta(segment))
+		}
+	}
+	re.WriteString("$")
+	return regexp.MustCompile(re.String())
+}
@@ -5,7 +5,9 @@ package validations
 
 import (
 	"fmt"
+	"math"
 	"reflect"
+	"regexp"
 	"sort"
 	"strings"
 
@@ -21,7 +23,7 @@ import (
 // NodeValidation represents a validationRun attached to a Node via an annotation.
 type NodeValidation struct {
 	rules    []rule
-	kwargs   validationKwargs
+	kwargs   ValidationKwargs
 	position *filepos.Position
 }
 
@@ -29,9 +31,14 @@ type NodeValidation struct {
 // and a function that asserts the rule against an actual value.
 type rule struct {
 	msg        string
+	name       string // (optional) identifies the assertion, when reporting a violation.
 	assertion  starlark.Callable
-	priority   int  // how early to run this rule. 0 = order it appears; more positive: earlier, more negative: later.
-	isCritical bool // whether not satisfying this rule prevents others rules from running.
+	priority   int               // how early to run this rule. 0 = order it appears; more positive: earlier, more negative: later.
+	isCritical bool              // whether not satisfying this rule prevents others rules from running.
+	position   *filepos.Position // (optional) where this rule was declared, if not where its validation was.
+	withParent bool              // whether the assertion is also given the value's parent (e.g. to compare with a sibling).
+	withRoot   bool              // whether the assertion is also given the root (i.e. data values), after the parent.
+	fromKwarg  bool              // whether this rule was declared via a keyword argument (rather than as a rule tuple).
 }
 
 // byPriority sorts (a copy) of "rules" by priority in descending order (i.e. the order in which the rules should run)
@@ -44,16 +51,336 @@ func byPriority(rules []rule) []rule {
 	return sorted
 }
 
-// validationKwargs represent the optional keyword arguments and their values in a validationRun annotation.
-type validationKwargs struct {
-	when       starlark.Callable
+// ValidationKwargs represent the optional keyword arguments and their values in a validationRun annotation.
+type ValidationKwargs struct {
+	when starlark.Callable
+	// whenTruthy and whenFalsy are paths (e.g. "tls.enabled") of the value that must be (respectively) truthy or
+	// falsy for the rules to run. Each is resolved from the parent of the value being validated or, when the parent
+	// has no such key, from the root (i.e. data.values).
+	whenTruthy starlark.String
+	whenFalsy  starlark.String
 	minLength  *starlark.Int // 0 len("") == 0, this always passes
 	maxLength  *starlark.Int
-	min        starlark.Value
+	min        starlark.Value // given a list, min (and max, exclusiveMin, exclusiveMax) bounds each of its items.
 	max        starlark.Value
 	notNull    bool
 	oneNotNull starlark.Value // valid values are either starlark.Sequence or starlark.Bool
 	oneOf      starlark.Sequence
+	notOneOf   starlark.Sequence
+	// maxDecimals has no equivalent in OpenAPI: it is only ever enforced when validating.
+	maxDecimals *starlark.Int
+	// sorted (either yttlibrary.SortedAscending or yttlibrary.SortedDescending) has no equivalent in OpenAPI, either.
+	sorted starlark.String
+	// each is applied to every item of a list; eachName identifies it (when given an assertion object).
+	each     starlark.Callable
+	eachName string
+	// values is applied to the value of every item of a map; valuesName identifies it (when given an assertion object).
+	values     starlark.Callable
+	valuesName string
+	lowercase  bool
+	uppercase  bool
+	// exclusiveMin and exclusiveMax are bounds that the value must not equal.
+	exclusiveMin starlark.Value
+	exclusiveMax starlark.Value
+	// equals names the sibling key whose value this value must equal.
+	equals starlark.String
+	// distinctFrom names the sibling key whose value this value must not equal.
+	distinctFrom starlark.String
+	// tolerance, if given, is by how much a float may differ from the value it is compared with by equals= and
+	// one_of=, and still be considered equal. Otherwise (and for values other than floats), values must be equal
+	// exactly.
+	tolerance *float64
+	// requiredTogether are the keys (of a map) that must either all be not null or all be null.
+	requiredTogether starlark.Sequence
+	// requiredKeys are the keys (of a map) that must all be present and not null.
+	requiredKeys starlark.Sequence
+	// pattern is a regular expression that (some part of) a string value must match.
+	pattern *regexp.Regexp
+	// whenNullSkip, if given, says whether to skip the rules when the value is null; otherwise, they are skipped
+	// unless not_null= is set. (Descendants of a null value have no values of their own, so are never checked.)
+	whenNullSkip *bool
+	// dates makes min= and max= compare dates chronologically (rather than as strings); it is set for values whose
+	// format is a date (see ComparingDates()).
+	dates bool
+	// duration and timestamp require a string to be (respectively) a duration or an RFC 3339 timestamp.
+	duration  bool
+	timestamp bool
+	// validYAML and validJSON require a string to parse as (respectively) YAML or JSON (e.g. an embedded document).
+	validYAML bool
+	validJSON bool
+	// lenEquals names the sibling key whose value (a number) the length of this value must equal.
+	lenEquals starlark.String
+	// keyOf is the path (e.g. "databases") of the map among whose keys this value must be. It is resolved as are the
+	// paths of whenTruthy and whenFalsy.
+	keyOf starlark.String
+	// ip and cidr require a string to be (respectively) an IP address or a CIDR, of the version given (one of
+	// yttlibrary.IPVersionAny, yttlibrary.IPVersion4, or yttlibrary.IPVersion6); empty when not required.
+	ip   string
+	cidr string
+	// startsWith and endsWith are (respectively) the prefix and suffix a string value must have.
+	startsWith starlark.String
+	endsWith   starlark.String
+	// url requires a string to be an absolute URL; urlSchemes, if given, are the schemes it may have.
+	url        bool
+	urlSchemes []string
+	// semver requires a string to be a semantic version; semverRange, if given, is the range (e.g. ">=1.2.0") it must
+	// be in.
+	semver      bool
+	semverRange string
+	// bitFlags are the flags of which an integer must be a (bitwise-OR) combination; it has no equivalent in OpenAPI.
+	bitFlags []uint64
+	// experimental rules are only run when a validation run enables them (see RunOpts).
+	experimental bool
+	// custom holds the rules given via keyword arguments registered by integrators (see RegisterKwarg).
+	custom []rule
+	// given are the keyword arguments as they appeared in the annotation (in order), for describing them.
+	given []starlark.Tuple
+}
+
+// String describes this validation in the form in which it could have been declared: its rules, followed by its
+// keyword arguments (e.g. `("a port", <function lambda>), min=1, max=65535, not_null`).
+func (v NodeValidation) String() string {
+	var parts []string
+	for _, r := range v.rules {
+		if r.fromKwarg {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("(%q, %s)", r.msg, r.assertion.String()))
+	}
+	if kwargs := v.kwargs.String(); kwargs != "" {
+		parts = append(parts, kwargs)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// String describes these keyword arguments, in the order given (e.g. `min=1, max=65535, not_null`). Flags that are
+// set (i.e. given the value True) are described by name, only.
+func (v ValidationKwargs) String() string {
+	var parts []string
+	for _, kwarg := range v.given {
+		name := string(kwarg[0].(starlark.String))
+		if kwarg[1] == starlark.True {
+			parts = append(parts, name)
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s=%s", name, kwarg[1].String()))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// GetPosition provides the position of the annotation that declared this validation.
+func (v NodeValidation) GetPosition() *filepos.Position {
+	return v.position
+}
+
+// ComparingDates produces a copy of this validation whose min= and max= compare dates chronologically (rather than as
+// strings): for values whose format is a date (i.e. @schema/format "date").
+func (v NodeValidation) ComparingDates() *NodeValidation {
+	var rules []rule
+	for _, r := range v.rules {
+		if !r.fromKwarg {
+			rules = append(rules, r)
+		}
+	}
+	v.kwargs.dates = true
+	for _, kwargRule := range v.kwargs.asRules() {
+		kwargRule.fromKwarg = true
+		rules = append(rules, kwargRule)
+	}
+	return &NodeValidation{rules, v.kwargs, v.position}
+}
+
+// GetValidationKwargs provides the keyword arguments given to the annotation that declared this validation.
+func (v NodeValidation) GetValidationKwargs() ValidationKwargs {
+	return v.kwargs
+}
+
+// GetMin provides the lower bound given via min= (or in_range=), if any.
+func (v ValidationKwargs) GetMin() (starlark.Value, bool) {
+	return v.min, v.min != nil
+}
+
+// GetMax provides the upper bound given via max= (or in_range=), if any.
+func (v ValidationKwargs) GetMax() (starlark.Value, bool) {
+	return v.max, v.max != nil
+}
+
+// GetExclusiveMin provides the (exclusive) lower bound given via exclusive_min=, if any.
+func (v ValidationKwargs) GetExclusiveMin() (starlark.Value, bool) {
+	return v.exclusiveMin, v.exclusiveMin != nil
+}
+
+// GetExclusiveMax provides the (exclusive) upper bound given via exclusive_max=, if any.
+func (v ValidationKwargs) GetExclusiveMax() (starlark.Value, bool) {
+	return v.exclusiveMax, v.exclusiveMax != nil
+}
+
+// GetIP provides the version of IP address required via ip=, if any.
+func (v ValidationKwargs) GetIP() (string, bool) {
+	return v.ip, v.ip != ""
+}
+
+// GetCIDR provides the version of CIDR required via cidr=, if any.
+func (v ValidationKwargs) GetCIDR() (string, bool) {
+	return v.cidr, v.cidr != ""
+}
+
+// GetStartsWith provides the prefix given via starts_with=, if any.
+func (v ValidationKwargs) GetStartsWith() (starlark.String, bool) {
+	return v.startsWith, v.startsWith != ""
+}
+
+// GetEndsWith provides the suffix given via ends_with=, if any.
+func (v ValidationKwargs) GetEndsWith() (starlark.String, bool) {
+	return v.endsWith, v.endsWith != ""
+}
+
+// GetURL provides the schemes a URL is allowed to have (empty meaning any), when a URL is required via url=.
+func (v ValidationKwargs) GetURL() ([]string, bool) {
+	return v.urlSchemes, v.url
+}
+
+// GetSemver provides the range a version must be in (empty meaning any), when a semantic version is required via
+// semver=.
+func (v ValidationKwargs) GetSemver() (string, bool) {
+	return v.semverRange, v.semver
+}
+
+// GetEquals provides the sibling key given via equals=, if any.
+func (v ValidationKwargs) GetEquals() (starlark.String, bool) {
+	return v.equals, v.equals != ""
+}
+
+// GetDistinctFrom provides the sibling key given via distinct_from=, if any.
+func (v ValidationKwargs) GetDistinctFrom() (starlark.String, bool) {
+	return v.distinctFrom, v.distinctFrom != ""
+}
+
+// GetKeyOf provides the path of the map given via key_of=, if any.
+func (v ValidationKwargs) GetKeyOf() (starlark.String, bool) {
+	return v.keyOf, v.keyOf != ""
+}
+
+// GetLenEquals provides the sibling key given via len_equals=, if any.
+func (v ValidationKwargs) GetLenEquals() (starlark.String, bool) {
+	return v.lenEquals, v.lenEquals != ""
+}
+
+// GetMinLength provides the minimum length given via min_len=, if any.
+func (v ValidationKwargs) GetMinLength() (int64, bool) {
+	return intValue(v.minLength)
+}
+
+// GetMaxLength provides the maximum length given via max_len=, if any.
+func (v ValidationKwargs) GetMaxLength() (int64, bool) {
+	return intValue(v.maxLength)
+}
+
+// GetOneOf provides the allowed values given via one_of=, if any.
+func (v ValidationKwargs) GetOneOf() (starlark.Sequence, bool) {
+	return v.oneOf, v.oneOf != nil
+}
+
+// GetMaxDecimals provides the maximum number of decimal places given via max_decimals=, if any.
+func (v ValidationKwargs) GetMaxDecimals() (int64, bool) {
+	return intValue(v.maxDecimals)
+}
+
+// GetSorted provides the order (either yttlibrary.SortedAscending or yttlibrary.SortedDescending) given via sorted=,
+// if any.
+func (v ValidationKwargs) GetSorted() (starlark.String, bool) {
+	return v.sorted, v.sorted != ""
+}
+
+// GetLowercase reports whether lowercase= was set.
+func (v ValidationKwargs) GetLowercase() bool {
+	return v.lowercase
+}
+
+// GetUppercase reports whether uppercase= was set.
+func (v ValidationKwargs) GetUppercase() bool {
+	return v.uppercase
+}
+
+// GetOneNotNull provides the value given via one_not_null= (either True or a sequence of keys), if any.
+func (v ValidationKwargs) GetOneNotNull() (starlark.Value, bool) {
+	return v.oneNotNull, v.oneNotNull != nil
+}
+
+func intValue(i *starlark.Int) (int64, bool) {
+	if i == nil {
+		return 0, false
+	}
+	return i.Int64()
+}
+
+// GetExperimental reports whether experimental= was set.
+func (v ValidationKwargs) GetExperimental() bool {
+	return v.experimental
+}
+
+// GetNotNull reports whether not_null= was set.
+func (v ValidationKwargs) GetNotNull() bool {
+	return v.notNull
+}
+
+// GetRequiredTogether provides the keys given via required_together=, if any.
+func (v ValidationKwargs) GetRequiredTogether() (starlark.Sequence, bool) {
+	return v.requiredTogether, v.requiredTogether != nil
+}
+
+// GetRequiredKeys provides the keys given via required_keys=, if any.
+func (v ValidationKwargs) GetRequiredKeys() (starlark.Sequence, bool) {
+	return v.requiredKeys, v.requiredKeys != nil
+}
+
+// GetPattern provides the regular expression given via pattern=, if any.
+func (v ValidationKwargs) GetPattern() (*regexp.Regexp, bool) {
+	return v.pattern, v.pattern != nil
+}
+
+// GetDuration reports whether duration= was set.
+func (v ValidationKwargs) GetDuration() bool {
+	return v.duration
+}
+
+// GetTimestamp reports whether timestamp= was set.
+func (v ValidationKwargs) GetTimestamp() bool {
+	return v.timestamp
+}
+
+// GetValidYAML reports whether valid_yaml= was set.
+func (v ValidationKwargs) GetValidYAML() bool {
+	return v.validYAML
+}
+
+// GetValidJSON reports whether valid_json= was set.
+func (v ValidationKwargs) GetValidJSON() bool {
+	return v.validJSON
+}
+
+// GetNotOneOf provides the blocklist given via not_one_of=, if any.
+func (v ValidationKwargs) GetNotOneOf() (starlark.Sequence, bool) {
+	return v.notOneOf, v.notOneOf != nil
+}
+
+// MessageCatalog maps the message of a rule (e.g. "a value >= 1") to the (localized) text to report in its place.
+type MessageCatalog map[string]string
+
+// Localize provides the text to report for a rule whose message is "msg": the catalog entry, if there is one;
+// otherwise "msg", itself.
+func (c MessageCatalog) Localize(msg string) string {
+	if localized, found := c[msg]; found {
+		return localized
+	}
+	return msg
+}
+
+// RunOpts configures a validation run.
+type RunOpts struct {
+	MessageCatalog     MessageCatalog // (optional) text to report in place of rule messages.
+	EnableExperimental bool           // whether to run validations marked experimental (otherwise, they are skipped).
+	Paths              PathFilter     // (optional) which values to validate (by path); the others' validations are skipped.
 }
 
 // Run takes a root Node, and threadName, and validates each Node in the tree.
@@ -61,11 +388,16 @@ type validationKwargs struct {
 // When a Node's value is invalid, the errors are collected and returned in a Check.
 // Otherwise, returns empty Check and nil error.
 func Run(node yamlmeta.Node, threadName string) (Check, error) {
+	return RunWithOpts(node, threadName, RunOpts{})
+}
+
+// RunWithOpts is Run, configured by "opts".
+func RunWithOpts(node yamlmeta.Node, threadName string, opts RunOpts) (Check, error) {
 	if node == nil {
 		return Check{}, nil
 	}
 
-	validation := newValidationRun(threadName, node)
+	validation := newValidationRun(threadName, node, opts)
 	err := yamlmeta.WalkWithParent(node, nil, "", validation)
 	if err != nil {
 		return Check{}, err
@@ -78,10 +410,11 @@ type validationRun struct {
 	thread *starlark.Thread
 	chk    Check
 	root   yamlmeta.Node
+	opts   RunOpts
 }
 
-func newValidationRun(threadName string, root yamlmeta.Node) *validationRun {
-	return &validationRun{thread: &starlark.Thread{Name: threadName}, root: root}
+func newValidationRun(threadName string, root yamlmeta.Node, opts RunOpts) *validationRun {
+	return &validationRun{thread: &starlark.Thread{Name: threadName}, root: root, opts: opts}
 }
 
 // VisitWithParent if `node` has validations in its meta.
@@ -96,13 +429,32 @@ func (a *validationRun) VisitWithParent(value yamlmeta.Node, parent yamlmeta.Nod
 		return nil
 	}
 	for _, v := range validations {
-		invalid, err := v.Validate(value, parent, a.root, path, a.thread)
+		if (v.kwargs.experimental && !a.opts.EnableExperimental) || !a.opts.Paths.Selects(path) {
+			a.chk.Outcomes = append(a.chk.Outcomes, Outcome{
+				Path:             path,
+				ValueSource:      value.GetPosition(),
+				ValidationSource: v.position,
+				Skipped:          true,
+			})
+			continue
+		}
+		invalid, skipped, err := v.validate(value, parent, a.root, path, a.thread)
 		if err != nil {
 			return err
 		}
+		for idx := range invalid.Violations {
+			invalid.Violations[idx].Description = a.opts.MessageCatalog.Localize(invalid.Violations[idx].Description)
+		}
 		if len(invalid.Violations) > 0 {
 			a.chk.Invalidations = append(a.chk.Invalidations, invalid)
 		}
+		a.chk.Outcomes = append(a.chk.Outcomes, Outcome{
+			Path:             invalid.Path,
+			ValueSource:      invalid.ValueSource,
+			ValidationSource: v.position,
+			Skipped:          skipped,
+			Violations:       invalid.Violations,
+		})
 	}
 
 	return nil
@@ -114,18 +466,19 @@ func (a *validationRun) VisitWithParent(value yamlmeta.Node, parent yamlmeta.Nod
 // Returns an error if the assertion returns False (not-None), or assert.fail()s.
 // Otherwise, returns nil.
 func (v NodeValidation) Validate(node yamlmeta.Node, parent yamlmeta.Node, root yamlmeta.Node, path string, thread *starlark.Thread) (Invalidation, error) {
+	invalid, skipped, err := v.validate(node, parent, root, path, thread)
+	if skipped {
+		return Invalidation{}, err
+	}
+	return invalid, err
+}
+
+// validate is Validate, also reporting whether the rules were skipped (i.e. ValidationKwargs excluded the value).
+func (v NodeValidation) validate(node yamlmeta.Node, parent yamlmeta.Node, root yamlmeta.Node, path string, thread *starlark.Thread) (Invalidation, bool, error) {
 	nodeValue := v.newStarlarkValue(node)
 	parentValue := v.newStarlarkValue(parent)
 	rootValue := v.newStarlarkValue(root)
 
-	executeRules, err := v.kwargs.shouldValidate(nodeValue, parentValue, thread, rootValue)
-	if err != nil {
-		return Invalidation{}, fmt.Errorf("Validating %s: %s", path, err)
-	}
-	if !executeRules {
-		return Invalidation{}, nil
-	}
-
 	displayedPath := path
 	if displayedPath == "" {
 		displayedPath = fmt.Sprintf("(%s)", yamlmeta.TypeName(node))
@@ -135,13 +488,34 @@ func (v NodeValidation) Validate(node yamlmeta.Node, parent yamlmeta.Node, root
 		ValueSource: node.GetPosition(),
 	}
 
+	executeRules, err := v.kwargs.shouldValidate(nodeValue, parentValue, thread, rootValue)
+	if err != nil {
+		return Invalidation{}, false, fmt.Errorf("Validating %s: %s", path, err)
+	}
+	if !executeRules {
+		return invalid, true, nil
+	}
+
 	for _, rul := range byPriority(v.rules) {
-		result, err := starlark.Call(thread, rul.assertion, starlark.Tuple{nodeValue}, []starlark.Tuple{})
+		ruleSource := v.position
+		if rul.position != nil {
+			ruleSource = rul.position
+		}
+		args := starlark.Tuple{nodeValue}
+		if rul.withParent {
+			args = append(args, parentValue)
+		}
+		if rul.withRoot {
+			args = append(args, rootValue)
+		}
+		result, err := starlark.Call(thread, rul.assertion, args, []starlark.Tuple{})
 		if err != nil {
 			violation := Violation{
-				RuleSource:  v.position,
+				RuleSource:  ruleSource,
+				RuleName:    rul.name,
 				Description: rul.msg,
 				Results:     strings.TrimPrefix(strings.TrimPrefix(err.Error(), "fail: "), "check: "),
+				Kwargs:      v.kwargs,
 			}
 			invalid.Violations = append(invalid.Violations, violation)
 			if rul.isCritical {
@@ -150,9 +524,11 @@ func (v NodeValidation) Validate(node yamlmeta.Node, parent yamlmeta.Node, root
 		} else {
 			if !(result == starlark.True) {
 				violation := Violation{
-					RuleSource:  v.position,
+					RuleSource:  ruleSource,
+					RuleName:    rul.name,
 					Description: rul.msg,
 					Results:     "",
+					Kwargs:      v.kwargs,
 				}
 				invalid.Violations = append(invalid.Violations, violation)
 				if rul.isCritical {
@@ -161,14 +537,14 @@ func (v NodeValidation) Validate(node yamlmeta.Node, parent yamlmeta.Node, root
 			}
 		}
 	}
-	return invalid, nil
+	return invalid, false, nil
 }
 
-// shouldValidate uses validationKwargs and the node's value to run checks on the value. If the value satisfies the checks,
+// shouldValidate uses ValidationKwargs and the node's value to run checks on the value. If the value satisfies the checks,
 // then the NodeValidation's rules should execute, otherwise the rules will be skipped.
-func (v validationKwargs) shouldValidate(value starlark.Value, parent starlark.Value, thread *starlark.Thread, root starlark.Value) (bool, error) {
+func (v ValidationKwargs) shouldValidate(value starlark.Value, parent starlark.Value, thread *starlark.Thread, root starlark.Value) (bool, error) {
 	_, valueIsNull := value.(starlark.NoneType)
-	if valueIsNull && !v.notNull {
+	if valueIsNull && v.skipsNull() {
 		return false, nil
 	}
 
@@ -188,13 +564,67 @@ func (v validationKwargs) shouldValidate(value starlark.Value, parent starlark.V
 			return false, fmt.Errorf("want when= to be bool, got %s", result.Type())
 		}
 
-		return bool(resultBool), nil
+		if !resultBool {
+			return false, nil
+		}
+	}
+
+	for _, condition := range []struct {
+		kwargName string
+		path      starlark.String
+		truthy    bool
+	}{{KwargWhenTruthy, v.whenTruthy, true}, {KwargWhenFalsy, v.whenFalsy, false}} {
+		if condition.path == "" {
+			continue
+		}
+		referenced, err := valueAtPath(condition.path, parent, root)
+		if err != nil {
+			return false, fmt.Errorf("Failed to evaluate %s=: %s", condition.kwargName, err)
+		}
+		if bool(referenced.Truth()) != condition.truthy {
+			return false, nil
+		}
 	}
 
 	return true, nil
 }
 
-func (v validationKwargs) populateArgs(value starlark.Value, parent starlark.Value, root starlark.Value) ([]starlark.Value, error) {
+// valueAtPath provides the value at "path" (keys separated by "."), starting from "parent" if it has the first of
+// those keys; otherwise, from "root". Within a null value, every path leads to null.
+func valueAtPath(path starlark.String, parent starlark.Value, root starlark.Value) (starlark.Value, error) {
+	keys := strings.Split(path.GoString(), ".")
+	current := root
+	if siblings, ok := parent.(starlark.Mapping); ok {
+		if _, found, err := siblings.Get(starlark.String(keys[0])); err == nil && found {
+			current = parent
+		}
+	}
+	for idx, key := range keys {
+		if current == starlark.None {
+			return starlark.None, nil
+		}
+		mapping, ok := current.(starlark.Mapping)
+		if !ok {
+			return starlark.None, fmt.Errorf("there is no value at %s (%s is not a map)", path.GoString(), strings.Join(keys[:idx], "."))
+		}
+		value, found, err := mapping.Get(starlark.String(key))
+		if err != nil || !found {
+			return starlark.None, fmt.Errorf("there is no value at %s", path.GoString())
+		}
+		current = value
+	}
+	return current, nil
+}
+
+// skipsNull reports whether the rules are skipped when the value is null.
+func (v ValidationKwargs) skipsNull() bool {
+	if v.whenNullSkip != nil {
+		return *v.whenNullSkip
+	}
+	return !v.notNull
+}
+
+func (v ValidationKwargs) populateArgs(value starlark.Value, parent starlark.Value, root starlark.Value) ([]starlark.Value, error) {
 	args := []starlark.Value{}
 	args = append(args, value)
 
@@ -212,7 +642,7 @@ func (v validationKwargs) populateArgs(value starlark.Value, parent starlark.Val
 	return args, nil
 }
 
-func (v validationKwargs) asRules() []rule {
+func (v ValidationKwargs) asRules() []rule {
 	var rules []rule
 
 	if v.minLength != nil {
@@ -227,16 +657,203 @@ func (v validationKwargs) asRules() []rule {
 			assertion: yttlibrary.NewAssertMaxLen(*v.maxLength).CheckFunc(),
 		})
 	}
+	if v.maxDecimals != nil {
+		rules = append(rules, rule{
+			msg:       fmt.Sprintf("at most %v decimal places", *v.maxDecimals),
+			assertion: yttlibrary.NewAssertMaxDecimals(*v.maxDecimals).CheckFunc(),
+		})
+	}
+	if v.sorted != "" {
+		order := "ascending"
+		if v.sorted == yttlibrary.SortedDescending {
+			order = "descending"
+		}
+		rules = append(rules, rule{
+			msg:       fmt.Sprintf("sorted in %s order", order),
+			assertion: yttlibrary.NewAssertSorted(v.sorted).CheckFunc(),
+		})
+	}
+	if v.pattern != nil {
+		rules = append(rules, rule{
+			msg:       fmt.Sprintf("a string matching %s", v.pattern.String()),
+			assertion: yttlibrary.NewAssertPattern(v.pattern).CheckFunc(),
+		})
+	}
+	if v.lowercase {
+		rules = append(rules, rule{
+			msg:       "all lowercase",
+			assertion: yttlibrary.NewAssertLowercase().CheckFunc(),
+		})
+	}
+	if v.uppercase {
+		rules = append(rules, rule{
+			msg:       "all uppercase",
+			assertion: yttlibrary.NewAssertUppercase().CheckFunc(),
+		})
+	}
+	if v.duration {
+		rules = append(rules, rule{
+			msg:       "a duration (e.g. 30s or P30D)",
+			assertion: yttlibrary.NewAssertDuration().CheckFunc(),
+		})
+	}
+	if v.timestamp {
+		rules = append(rules, rule{
+			msg:       "an RFC 3339 timestamp (e.g. 2006-01-02T15:04:05Z)",
+			assertion: yttlibrary.NewAssertTimestamp().CheckFunc(),
+		})
+	}
+	if v.validYAML {
+		rules = append(rules, rule{
+			msg:       "a string that is valid YAML",
+			assertion: yttlibrary.NewAssertValidYAML().CheckFunc(),
+		})
+	}
+	if v.validJSON {
+		rules = append(rules, rule{
+			msg:       "a string that is valid JSON",
+			assertion: yttlibrary.NewAssertValidJSON().CheckFunc(),
+		})
+	}
+	if v.startsWith != "" {
+		rules = append(rules, rule{
+			msg:       fmt.Sprintf("a string starting with %s", v.startsWith.String()),
+			assertion: yttlibrary.NewAssertStartsWith(v.startsWith).CheckFunc(),
+		})
+	}
+	if v.endsWith != "" {
+		rules = append(rules, rule{
+			msg:       fmt.Sprintf("a string ending with %s", v.endsWith.String()),
+			assertion: yttlibrary.NewAssertEndsWith(v.endsWith).CheckFunc(),
+		})
+	}
+	if v.ip != "" {
+		rules = append(rules, rule{
+			msg:       ipDescriptions[v.ip][0],
+			assertion: yttlibrary.NewAssertIP(v.ip).CheckFunc(),
+		})
+	}
+	if v.cidr != "" {
+		rules = append(rules, rule{
+			msg:       ipDescriptions[v.cidr][1],
+			assertion: yttlibrary.NewAssertCIDR(v.cidr).CheckFunc(),
+		})
+	}
+	if v.url {
+		msg := "an absolute URL (e.g. https://example.com/path)"
+		if len(v.urlSchemes) > 0 {
+			msg = fmt.Sprintf("an absolute URL with the scheme %s", strings.Join(v.urlSchemes, " or "))
+		}
+		rules = append(rules, rule{
+			msg:       msg,
+			assertion: yttlibrary.NewAssertURL(v.urlSchemes).CheckFunc(),
+		})
+	}
+	if v.semver {
+		msg := "a semantic version (e.g. 1.2.3)"
+		if v.semverRange != "" {
+			msg = fmt.Sprintf("a semantic version in the range %s", v.semverRange)
+		}
+		assertion, err := yttlibrary.NewAssertSemver(v.semverRange)
+		if err != nil {
+			// should have been caught when args were parsed
+			panic(fmt.Sprintf("Unexpected range %q for semver_range=: %s", v.semverRange, err))
+		}
+		rules = append(rules, rule{
+			msg:       msg,
+			assertion: assertion.CheckFunc(),
+		})
+	}
+	if v.bitFlags != nil {
+		var flags []string
+		for _, flag := range v.bitFlags {
+			flags = append(flags, fmt.Sprintf("%d", flag))
+		}
+		rules = append(rules, rule{
+			msg:       fmt.Sprintf("a combination of the flags [%s]", strings.Join(flags, ", ")),
+			assertion: yttlibrary.NewAssertBitFlags(v.bitFlags).CheckFunc(),
+		})
+	}
+	if v.each != nil {
+		assertion := "the given assertion"
+		if v.eachName != "" {
+			assertion = v.eachName
+		}
+		rules = append(rules, rule{
+			msg:       fmt.Sprintf("each item satisfying %s", assertion),
+			assertion: yttlibrary.NewAssertEach(v.each).CheckFunc(),
+		})
+	}
+	if v.values != nil {
+		assertion := "the given assertion"
+		if v.valuesName != "" {
+			assertion = v.valuesName
+		}
+		rules = append(rules, rule{
+			msg:       fmt.Sprintf("each value satisfying %s", assertion),
+			assertion: yttlibrary.NewAssertValues(v.values).CheckFunc(),
+		})
+	}
 	if v.min != nil {
+		minAssertion := yttlibrary.NewAssertMin(v.min)
+		if v.dates && yttlibrary.IsDate(v.min) {
+			// compare dates chronologically (and not just as strings)
+			minAssertion = yttlibrary.NewAssertMinDate(v.min.(starlark.String))
+		}
 		rules = append(rules, rule{
 			msg:       fmt.Sprintf("a value >= %v", v.min),
-			assertion: yttlibrary.NewAssertMin(v.min).CheckFunc(),
+			assertion: boundOnItems(v.min, minAssertion),
 		})
 	}
 	if v.max != nil {
+		maxAssertion := yttlibrary.NewAssertMax(v.max)
+		if v.dates && yttlibrary.IsDate(v.max) {
+			maxAssertion = yttlibrary.NewAssertMaxDate(v.max.(starlark.String))
+		}
 		rules = append(rules, rule{
 			msg:       fmt.Sprintf("a value <= %v", v.max),
-			assertion: yttlibrary.NewAssertMax(v.max).CheckFunc(),
+			assertion: boundOnItems(v.max, maxAssertion),
+		})
+	}
+	if v.exclusiveMin != nil {
+		rules = append(rules, rule{
+			msg:       fmt.Sprintf("a value > %v", v.exclusiveMin),
+			assertion: boundOnItems(v.exclusiveMin, yttlibrary.NewAssertExclusiveMin(v.exclusiveMin)),
+		})
+	}
+	if v.exclusiveMax != nil {
+		rules = append(rules, rule{
+			msg:       fmt.Sprintf("a value < %v", v.exclusiveMax),
+			assertion: boundOnItems(v.exclusiveMax, yttlibrary.NewAssertExclusiveMax(v.exclusiveMax)),
+		})
+	}
+	if v.equals != "" {
+		rules = append(rules, rule{
+			msg:        fmt.Sprintf("equal to %s", v.equals.GoString()),
+			assertion:  newAssertEqualsSibling(v.equals, v.tolerance),
+			withParent: true,
+		})
+	}
+	if v.distinctFrom != "" {
+		rules = append(rules, rule{
+			msg:        fmt.Sprintf("distinct from %s", v.distinctFrom.GoString()),
+			assertion:  newAssertDistinctFromSibling(v.distinctFrom),
+			withParent: true,
+		})
+	}
+	if v.lenEquals != "" {
+		rules = append(rules, rule{
+			msg:        fmt.Sprintf("length equal to %s", v.lenEquals.GoString()),
+			assertion:  newAssertLenEqualsSibling(v.lenEquals),
+			withParent: true,
+		})
+	}
+	if v.keyOf != "" {
+		rules = append(rules, rule{
+			msg:        fmt.Sprintf("a key of %s", v.keyOf.GoString()),
+			assertion:  newAssertKeyOf(v.keyOf),
+			withParent: true,
+			withRoot:   true,
 		})
 	}
 	if v.notNull {
@@ -273,13 +890,40 @@ func (v validationKwargs) asRules() []rule {
 			assertion: assertion.CheckFunc(),
 		})
 	}
+	if v.requiredTogether != nil {
+		rules = append(rules, rule{
+			msg:       fmt.Sprintf("all or none of %s to be not null", v.requiredTogether.String()),
+			assertion: yttlibrary.NewAssertRequiredTogether(v.requiredTogether).CheckFunc(),
+		})
+	}
+	if v.requiredKeys != nil {
+		rules = append(rules, rule{
+			msg:       fmt.Sprintf("all of %s to be present and not null", v.requiredKeys.String()),
+			assertion: yttlibrary.NewAssertRequiredKeys(v.requiredKeys).CheckFunc(),
+		})
+	}
 	if v.oneOf != nil {
+		if v.tolerance != nil {
+			rules = append(rules, rule{
+				msg:       fmt.Sprintf("one of %s (within %v)", v.oneOf.String(), *v.tolerance),
+				assertion: newAssertOneOfWithin(v.oneOf, *v.tolerance),
+			})
+		} else {
+			rules = append(rules, rule{
+				msg:       fmt.Sprintf("one of %s", v.oneOf.String()),
+				assertion: yttlibrary.NewAssertOneOf(v.oneOf).CheckFunc(),
+			})
+		}
+	}
+	if v.notOneOf != nil {
 		rules = append(rules, rule{
-			msg:       fmt.Sprintf("one of %s", v.oneOf.String()),
-			assertion: yttlibrary.NewAssertOneOf(v.oneOf).CheckFunc(),
+			msg:       fmt.Sprintf("not one of %s", v.notOneOf.String()),
+			assertion: yttlibrary.NewAssertNotOneOf(v.notOneOf).CheckFunc(),
 		})
 	}
 
+	rules = append(rules, v.custom...)
+
 	return rules
 }
 
@@ -293,13 +937,56 @@ type Invalidation struct {
 // Violation describes how a value failed to satisfy a rule.
 type Violation struct {
 	RuleSource  *filepos.Position
+	RuleName    string
 	Description string
 	Results     string
+	Kwargs      ValidationKwargs // of the validation containing the rule (e.g. to suggest a valid value)
+}
+
+// Min provides the lower bound (given via min=) of the validation containing the rule, if any.
+func (v Violation) Min() (interface{}, bool) {
+	return goValueOf(v.Kwargs.GetMin())
+}
+
+// Max provides the upper bound (given via max=) of the validation containing the rule, if any.
+func (v Violation) Max() (interface{}, bool) {
+	return goValueOf(v.Kwargs.GetMax())
+}
+
+func goValueOf(val starlark.Value, found bool) (interface{}, bool) {
+	if !found {
+		return nil, false
+	}
+	goVal, err := core.NewStarlarkValue(val).AsGoValue()
+	if err != nil {
+		return nil, false
+	}
+	return goVal, true
 }
 
 // Check holds the complete set of Invalidations (if any) resulting from checking all validation rules.
 type Check struct {
 	Invalidations []Invalidation
+	Outcomes      []Outcome // of every validation checked (valid or not), in the order they were checked.
+}
+
+// Outcome records the checking of a value against one validation.
+type Outcome struct {
+	Path             string
+	ValueSource      *filepos.Position
+	ValidationSource *filepos.Position
+	Skipped          bool        // whether the rules did not run (e.g. the value was null, or when= was False)
+	Violations       []Violation // (empty when the value is valid)
+}
+
+// CheckError reports a Check that found invalid values (as opposed to a failure to check them, at all).
+type CheckError struct {
+	Check Check
+}
+
+// Error provides the results of the Check (see Check.ResultsAsString).
+func (e CheckError) Error() string {
+	return e.Check.ResultsAsString()
 }
 
 // ResultsAsString generates the error message composed of the total set of Check.Invalidations.
@@ -312,7 +999,11 @@ func (c Check) ResultsAsString() string {
 	for _, inval := range c.Invalidations {
 		msg += fmt.Sprintf("  %s\n    from: %s\n", inval.Path, inval.ValueSource.AsCompactString())
 		for _, viol := range inval.Violations {
-			msg += fmt.Sprintf("    - must be: %s (by: %s)\n", viol.Description, viol.RuleSource.AsCompactString())
+			description := viol.Description
+			if viol.RuleName != "" && viol.RuleName != description {
+				description = fmt.Sprintf("[%s] %s", viol.RuleName, description)
+			}
+			msg += fmt.Sprintf("    - must be: %s (by: %s)\n", description, viol.RuleSource.AsCompactString())
 			if viol.Results != "" {
 				msg += fmt.Sprintf("      found: %s\n", viol.Results)
 			}
@@ -327,6 +1018,219 @@ func (c Check) HasInvalidations() bool {
 	return len(c.Invalidations) > 0
 }
 
+// boundOnItems produces the assertion of "bound" ("assertion") that, given a list, is made of each of its items instead
+// (e.g. min=1 on a list of integers requires every integer to be at least 1).
+// When "bound" is itself a list, the assertion is made of the list as a whole (i.e. compared lexicographically).
+func boundOnItems(bound starlark.Value, assertion *yttlibrary.Assertion) starlark.Callable {
+	if isList(bound) {
+		return assertion.CheckFunc()
+	}
+	eachItem := yttlibrary.NewAssertEach(assertion.CheckFunc()).CheckFunc()
+	return starlark.NewBuiltin(assertion.CheckFunc().Name(), func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if args.Len() == 1 && isList(args[0]) {
+			return starlark.Call(thread, eachItem, args, kwargs)
+		}
+		return starlark.Call(thread, assertion.CheckFunc(), args, kwargs)
+	})
+}
+
+func isList(value starlark.Value) bool {
+	switch typedValue := value.(type) {
+	case *starlark.List, starlark.Tuple:
+		return true
+	case *yamltemplate.StarlarkFragment:
+		data, _ := typedValue.AsGoValue()
+		_, isArray := data.(*yamlmeta.Array)
+		return isArray
+	default:
+		return false
+	}
+}
+
+// newAssertEqualsSibling produces an assertion that a given value equals that of its sibling "key", given the value
+// and its parent. When that sibling is null, there is nothing to compare with: the assertion holds.
+func newAssertEqualsSibling(key starlark.String, tolerance *float64) starlark.Callable {
+	return starlark.NewBuiltin("equals", func(_ *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, _ []starlark.Tuple) (starlark.Value, error) {
+		value, parent := args[0], args[1]
+		sibling, err := siblingOf(parent, key)
+		if err != nil {
+			return starlark.None, err
+		}
+		if sibling == starlark.None {
+			return starlark.True, nil
+		}
+		if tolerance != nil {
+			if within, isFloat := floatsWithin(value, sibling, *tolerance); isFloat {
+				if !within {
+					return starlark.None, fmt.Errorf("value differs from that of %s by more than %v", key.GoString(), *tolerance)
+				}
+				return starlark.True, nil
+			}
+		}
+		valueVal, err := plainValueOf(value)
+		if err != nil {
+			return starlark.None, err
+		}
+		siblingVal, err := plainValueOf(sibling)
+		if err != nil {
+			return starlark.None, err
+		}
+		if !reflect.DeepEqual(valueVal, siblingVal) {
+			return starlark.None, fmt.Errorf("value differs from that of %s", key.GoString())
+		}
+		return starlark.True, nil
+	})
+}
+
+// newAssertDistinctFromSibling produces an assertion that a given value does not equal that of its sibling "key",
+// given the value and its parent. When that sibling is null, there is nothing to compare with: the assertion holds.
+func newAssertDistinctFromSibling(key starlark.String) starlark.Callable {
+	return starlark.NewBuiltin("distinct_from", func(_ *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, _ []starlark.Tuple) (starlark.Value, error) {
+		value, parent := args[0], args[1]
+		sibling, err := siblingOf(parent, key)
+		if err != nil {
+			return starlark.None, err
+		}
+		if sibling == starlark.None {
+			return starlark.True, nil
+		}
+		valueVal, err := plainValueOf(value)
+		if err != nil {
+			return starlark.None, err
+		}
+		siblingVal, err := plainValueOf(sibling)
+		if err != nil {
+			return starlark.None, err
+		}
+		if reflect.DeepEqual(valueVal, siblingVal) {
+			return starlark.None, fmt.Errorf("value %s is the same as that of %s (%s)",
+				core.NewGoValue(valueVal).AsStarlarkValue().String(), key.GoString(), core.NewGoValue(siblingVal).AsStarlarkValue().String())
+		}
+		return starlark.True, nil
+	})
+}
+
+// newAssertLenEqualsSibling produces an assertion that the length of a given value equals the value of its sibling
+// "key" (a number), given the value and its parent. When that sibling is null, there is nothing to compare with: the
+// assertion holds.
+func newAssertLenEqualsSibling(key starlark.String) starlark.Callable {
+	return starlark.NewBuiltin("len_equals", func(_ *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, _ []starlark.Tuple) (starlark.Value, error) {
+		value, parent := args[0], args[1]
+		sibling, err := siblingOf(parent, key)
+		if err != nil {
+			return starlark.None, err
+		}
+		if sibling == starlark.None {
+			return starlark.True, nil
+		}
+		expected, ok := sibling.(starlark.Int)
+		if !ok {
+			return starlark.None, fmt.Errorf("%s is not an integer (but a %s)", key.GoString(), sibling.Type())
+		}
+		length := starlark.Len(value)
+		if length < 0 {
+			return starlark.None, fmt.Errorf("value of type %s has no length", value.Type())
+		}
+		if expectedLen, ok := expected.Int64(); !ok || expectedLen != int64(length) {
+			return starlark.None, fmt.Errorf("length is %d, but %s is %s", length, key.GoString(), expected.String())
+		}
+		return starlark.True, nil
+	})
+}
+
+// newAssertKeyOf produces an assertion that a given value is a key of the map at "path", given the value, its parent,
+// and the root (see valueAtPath()).
+func newAssertKeyOf(path starlark.String) starlark.Callable {
+	return starlark.NewBuiltin("key_of", func(_ *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, _ []starlark.Tuple) (starlark.Value, error) {
+		value, parent, root := args[0], args[1], args[2]
+		referenced, err := valueAtPath(path, parent, root)
+		if err != nil {
+			return starlark.None, err
+		}
+		if referenced == starlark.None {
+			return starlark.None, fmt.Errorf("%s is null (so has no keys)", path.GoString())
+		}
+		mapping, ok := referenced.(starlark.IterableMapping)
+		if !ok {
+			return starlark.None, fmt.Errorf("%s is not a map (but a %s)", path.GoString(), referenced.Type())
+		}
+		if _, found, err := mapping.Get(value); err == nil && found {
+			return starlark.True, nil
+		}
+		var keys []string
+		for _, item := range mapping.Items() {
+			keys = append(keys, item[0].String())
+		}
+		return starlark.None, fmt.Errorf("%s is not a key of %s (which has: %s)", value.String(), path.GoString(), strings.Join(keys, ", "))
+	})
+}
+
+// ipDescriptions describe an IP address and a CIDR of each version (see yttlibrary.IPVersionAny, etc.).
+var ipDescriptions = map[string][2]string{
+	yttlibrary.IPVersionAny: {"an IP address (e.g. 10.0.0.1 or fd00::1)", "an IP CIDR (e.g. 10.0.0.0/8 or fd00::/8)"},
+	yttlibrary.IPVersion4:   {"an IPv4 address (e.g. 10.0.0.1)", "an IPv4 CIDR (e.g. 10.0.0.0/8)"},
+	yttlibrary.IPVersion6:   {"an IPv6 address (e.g. fd00::1)", "an IPv6 CIDR (e.g. fd00::/8)"},
+}
+
+// newAssertOneOfWithin produces an assertion that a given value is one of "enum", a float being considered equal to
+// any number in "enum" that it is within "tolerance" of.
+func newAssertOneOfWithin(enum starlark.Sequence, tolerance float64) starlark.Callable {
+	exactly := yttlibrary.NewAssertOneOf(enum).CheckFunc()
+	return starlark.NewBuiltin("one_of", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, _ []starlark.Tuple) (starlark.Value, error) {
+		iter := enum.Iterate()
+		defer iter.Done()
+		var allowed starlark.Value
+		for iter.Next(&allowed) {
+			if within, _ := floatsWithin(args[0], allowed, tolerance); within {
+				return starlark.True, nil
+			}
+		}
+		return starlark.Call(thread, exactly, args, nil)
+	})
+}
+
+// floatsWithin reports whether "a" and "b" differ by no more than "tolerance", provided they are numbers and at least
+// one of them is a float (i.e. whether tolerance applies to comparing them at all).
+func floatsWithin(a, b starlark.Value, tolerance float64) (within bool, applies bool) {
+	_, aIsFloat := a.(starlark.Float)
+	_, bIsFloat := b.(starlark.Float)
+	if !aIsFloat && !bIsFloat {
+		return false, false
+	}
+	aFloat, aIsNumber := starlark.AsFloat(a)
+	bFloat, bIsNumber := starlark.AsFloat(b)
+	if !aIsNumber || !bIsNumber {
+		return false, false
+	}
+	return math.Abs(aFloat-bFloat) <= tolerance, true
+}
+
+// siblingOf provides the value of "key" within "parent" (i.e. the sibling of a value within that parent).
+func siblingOf(parent starlark.Value, key starlark.String) (starlark.Value, error) {
+	siblings, ok := parent.(starlark.Mapping)
+	if !ok {
+		return starlark.None, fmt.Errorf("value has no siblings (it is not within a map)")
+	}
+	sibling, found, err := siblings.Get(key)
+	if err != nil {
+		return starlark.None, err
+	}
+	if !found {
+		return starlark.None, fmt.Errorf("there is no sibling %s", key.GoString())
+	}
+	return sibling, nil
+}
+
+// plainValueOf converts "value" into a Go value that can be compared for equality: maps and arrays from YAML are
+// converted as well, shedding the positions that would otherwise set apart equal values from different lines.
+func plainValueOf(value starlark.Value) (interface{}, error) {
+	val, err := core.NewStarlarkValue(value).AsGoValue()
+	if err != nil {
+		return nil, err
+	}
+	return yamlmeta.NewGoFromAST(val), nil
+}
+
 func (v NodeValidation) newStarlarkValue(node yamlmeta.Node) starlark.Value {
 	if node == nil || reflect.ValueOf(node).IsNil() {
 		return starlark.None
@@ -9,7 +9,11 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/stretchr/testify/require"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/experiments"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/filepos"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/template"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/validations"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/yamlmeta"
 	_ "github.com/vmware-tanzu/carvel-ytt/pkg/yttlibraryext"
@@ -53,6 +57,148 @@ func TestValidations(t *testing.T) {
 	ft.Run(t)
 }
 
+func TestViolationsExposeBoundsOfValidation(t *testing.T) {
+	port := &yamlmeta.MapItem{Key: "port", Value: 0, Position: filepos.NewPosition(1)}
+	doc := &yamlmeta.Document{Value: &yamlmeta.Map{Items: []*yamlmeta.MapItem{port}}, Position: filepos.NewPosition(1)}
+
+	validation, err := validations.NewValidationFromAnn(template.NodeAnnotation{
+		Kwargs: []starlark.Tuple{
+			{starlark.String("min"), starlark.MakeInt(1)},
+			{starlark.String("max"), starlark.MakeInt(65535)},
+		},
+		Position: filepos.NewUnknownPosition(),
+	})
+	require.NoError(t, err)
+	validations.Add(port, []validations.NodeValidation{*validation})
+
+	chk, err := validations.Run(doc, "test")
+	require.NoError(t, err)
+	require.Len(t, chk.Invalidations, 1)
+	require.Len(t, chk.Invalidations[0].Violations, 1)
+
+	violation := chk.Invalidations[0].Violations[0]
+	min, found := violation.Min()
+	require.True(t, found)
+	require.Equal(t, int64(1), min)
+	max, found := violation.Max()
+	require.True(t, found)
+	require.Equal(t, int64(65535), max)
+	_, found = violation.Kwargs.GetMinLength()
+	require.False(t, found)
+}
+
+func TestNodeValidationDescribesItself(t *testing.T) {
+	isPort := starlark.NewBuiltin("is_port", func(_ *starlark.Thread, _ *starlark.Builtin, _ starlark.Tuple, _ []starlark.Tuple) (starlark.Value, error) {
+		return starlark.True, nil
+	})
+	validation, err := validations.NewValidationFromAnn(template.NodeAnnotation{
+		Args: starlark.Tuple{starlark.Tuple{starlark.String("a port"), isPort}},
+		Kwargs: []starlark.Tuple{
+			{starlark.String("min"), starlark.MakeInt(1)},
+			{starlark.String("max"), starlark.MakeInt(65535)},
+			{starlark.String("not_null"), starlark.True},
+			{starlark.String("one_of"), starlark.NewList([]starlark.Value{starlark.MakeInt(80), starlark.MakeInt(443)})},
+		},
+		Position: filepos.NewUnknownPosition(),
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, `("a port", <built-in function is_port>), min=1, max=65535, not_null, one_of=[80, 443]`, validation.String())
+	require.Equal(t, `min=1, max=65535, not_null, one_of=[80, 443]`, validation.GetValidationKwargs().String())
+}
+
+func TestRunReportsLocalizedMessagesFromCatalog(t *testing.T) {
+	port := &yamlmeta.MapItem{Key: "port", Value: 0, Position: filepos.NewPosition(1)}
+	doc := &yamlmeta.Document{Value: &yamlmeta.Map{Items: []*yamlmeta.MapItem{port}}, Position: filepos.NewPosition(1)}
+
+	validation, err := validations.NewValidationFromAnn(template.NodeAnnotation{
+		Kwargs: []starlark.Tuple{
+			{starlark.String("min"), starlark.MakeInt(1)},
+			{starlark.String("one_of"), starlark.NewList([]starlark.Value{starlark.MakeInt(8080)})},
+		},
+		Position: filepos.NewUnknownPosition(),
+	})
+	require.NoError(t, err)
+	validations.Add(port, []validations.NodeValidation{*validation})
+
+	chk, err := validations.RunWithOpts(doc, "test", validations.RunOpts{
+		MessageCatalog: validations.MessageCatalog{"a value >= 1": "un valor >= 1"},
+	})
+	require.NoError(t, err)
+	require.Len(t, chk.Invalidations, 1)
+	require.Len(t, chk.Invalidations[0].Violations, 2)
+
+	require.Equal(t, "un valor >= 1", chk.Invalidations[0].Violations[0].Description)
+	// without an entry in the catalog, the message is reported as is.
+	require.Equal(t, "one of [8080]", chk.Invalidations[0].Violations[1].Description)
+}
+
+func TestUnvalidatedReportsLeavesWithoutValidations(t *testing.T) {
+	port := &yamlmeta.MapItem{Key: "port", Value: 0, Position: filepos.NewPosition(2)}
+	host := &yamlmeta.MapItem{Key: "host", Value: "", Position: filepos.NewPosition(3)}
+	first := &yamlmeta.ArrayItem{Value: "a", Position: filepos.NewPosition(5)}
+	second := &yamlmeta.ArrayItem{Value: "b", Position: filepos.NewPosition(6)}
+	doc := &yamlmeta.Document{Value: &yamlmeta.Map{Items: []*yamlmeta.MapItem{
+		{Key: "db", Value: &yamlmeta.Map{Items: []*yamlmeta.MapItem{port, host}}, Position: filepos.NewPosition(1)},
+		{Key: "tags", Value: &yamlmeta.Array{Items: []*yamlmeta.ArrayItem{first, second}}, Position: filepos.NewPosition(4)},
+		{Key: "empty", Value: &yamlmeta.Map{}, Position: filepos.NewPosition(7)},
+	}}, Position: filepos.NewPosition(1)}
+
+	validation, err := validations.NewValidationFromAnn(template.NodeAnnotation{
+		Kwargs:   []starlark.Tuple{{starlark.String("min"), starlark.MakeInt(1)}},
+		Position: filepos.NewUnknownPosition(),
+	})
+	require.NoError(t, err)
+	validations.Add(port, []validations.NodeValidation{*validation})
+	validations.Add(second, []validations.NodeValidation{*validation})
+
+	paths, err := validations.Unvalidated(doc)
+	require.NoError(t, err)
+	require.Equal(t, []string{"db.host", "tags[0]"}, paths)
+}
+
+func TestRegisteredKwargsDeclareRules(t *testing.T) {
+	validations.RegisterKwarg("multiple_of", func(value starlark.Value) (string, starlark.Callable, error) {
+		divisor, ok := value.(starlark.Int)
+		if !ok {
+			return "", nil, fmt.Errorf("expected an int, but was %s", value.Type())
+		}
+		check := starlark.NewBuiltin("multiple_of", func(_ *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, _ []starlark.Tuple) (starlark.Value, error) {
+			return starlark.Bool(args[0].(starlark.Int).Mod(divisor).Sign() == 0), nil
+		})
+		return fmt.Sprintf("a multiple of %s", divisor), check, nil
+	})
+
+	t.Run("reports violations of the rule", func(t *testing.T) {
+		replicas := &yamlmeta.MapItem{Key: "replicas", Value: 3, Position: filepos.NewPosition(1)}
+		doc := &yamlmeta.Document{Value: &yamlmeta.Map{Items: []*yamlmeta.MapItem{replicas}}, Position: filepos.NewPosition(1)}
+
+		validation, err := validations.NewValidationFromAnn(template.NodeAnnotation{
+			Kwargs:   []starlark.Tuple{{starlark.String("multiple_of"), starlark.MakeInt(2)}},
+			Position: filepos.NewUnknownPosition(),
+		})
+		require.NoError(t, err)
+		validations.Add(replicas, []validations.NodeValidation{*validation})
+
+		chk, err := validations.Run(doc, "test")
+		require.NoError(t, err)
+		require.Len(t, chk.Invalidations, 1)
+		require.Len(t, chk.Invalidations[0].Violations, 1)
+		require.Equal(t, "a multiple of 2", chk.Invalidations[0].Violations[0].Description)
+	})
+	t.Run("reports errors parsing the value", func(t *testing.T) {
+		_, err := validations.NewValidationFromAnn(template.NodeAnnotation{
+			Kwargs:   []starlark.Tuple{{starlark.String("multiple_of"), starlark.String("two")}},
+			Position: filepos.NewUnknownPosition(),
+		})
+		require.EqualError(t, err, `invalid keyword argument "multiple_of": expected an int, but was string (at ?)`)
+	})
+	t.Run("cannot replace a built-in or registered keyword argument", func(t *testing.T) {
+		require.Panics(t, func() { validations.RegisterKwarg("min", nil) })
+		require.Panics(t, func() { validations.RegisterKwarg("multiple_of", nil) })
+	})
+}
+
 func EvalAndValidateTemplate(ft filetests.FileTests) filetests.EvaluateTemplate {
 	return func(src string) (filetests.MarshalableResult, *filetests.TestErr) {
 		result, testErr := ft.DefaultEvalTemplate(src)
@@ -110,6 +110,13 @@ func (pp DataValuesSchemaPreProcessing) extractSchemaDocs(schemaFile *FileInLibr
 		return nil, err
 	}
 
+	for _, doc := range schemaDocs {
+		err := yamlmeta.Walk(doc, exampleFileEmbedder{DataLoader{libraryCtx}})
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// For simplicity's sake, prohibit mixing data value schema documents with other kinds.
 	if len(nonSchemaDocs) > 0 {
 		for _, doc := range nonSchemaDocs {
@@ -102,6 +102,14 @@ func (dvd *Envelope) Desc() string {
 // IntendedForAnotherLibrary indicates whether this Envelope is "addressed" to some other library.
 func (dvd *Envelope) IntendedForAnotherLibrary() bool { return len(dvd.libRef) > 0 }
 
+// IntendedForLibrary reports the library (a child of the current one) to which this Envelope is "addressed", if any.
+func (dvd *Envelope) IntendedForLibrary() (ref.LibraryRef, bool) {
+	if !dvd.IntendedForAnotherLibrary() {
+		return ref.LibraryRef{}, false
+	}
+	return dvd.libRef[0], true
+}
+
 // UsedInLibrary marks this Envelope as "delivered"/used if its destination is included in expectedRefPiece.
 //
 // If the Envelope should be used exactly in the specified library, returns a copy of this Envelope with no addressing
@@ -140,6 +140,15 @@ func (e *SchemaEnvelope) IntendedForAnotherLibrary() bool {
 	return len(e.libRef) > 0
 }
 
+// IntendedForLibrary reports the library (a child of the current one) to which the contained Schema is addressed, if
+// any.
+func (e *SchemaEnvelope) IntendedForLibrary() (ref.LibraryRef, bool) {
+	if !e.IntendedForAnotherLibrary() {
+		return ref.LibraryRef{}, false
+	}
+	return e.libRef[0], true
+}
+
 // UsedInLibrary marks this SchemaEnvelope as "delivered"/used if its destination is included in expectedRefPiece.
 //
 // If the SchemaEnvelope should be used exactly in the specified library, returns a copy of this SchemaEnvelope with no
@@ -4,16 +4,17 @@
 package workspace
 
 import (
-	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/k14s/starlark-go/starlark"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/cmd/ui"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/files"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/schema"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/template"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/validations"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/workspace/datavalues"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/workspace/ref"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/yamlmeta"
 )
 
@@ -24,7 +25,12 @@ type LibraryExecution struct {
 	ui                       ui.UI
 	templateLoaderOpts       TemplateLoaderOpts
 	libraryExecFactory       *LibraryExecutionFactory
-	skipDataValuesValidation bool // when true, any validation rules present on data values are skipped
+	skipDataValuesValidation bool                       // when true, any validation rules present on data values are skipped
+	validationMessages       validations.MessageCatalog // (optional) text to report in place of validation rule messages
+	experimentalValidations  bool                       // when true, validations marked experimental are also run
+	validationPaths          validations.PathFilter     // which data values to validate (by path)
+	validateFormats          bool                       // when true, strings with a declared format are checked to be so encoded
+	validationReporter       func(validations.Check)    // (optional) given the outcome of validating data values
 }
 
 type EvalResult struct {
@@ -102,12 +108,89 @@ func (ll *LibraryExecution) Values(valuesOverlays []*datavalues.Envelope, schema
 	if !ll.skipDataValuesValidation {
 		err = ll.validateValues(values)
 		if err != nil {
-			return nil, nil, fmt.Errorf("Validating final data values:\n%s", err)
+			return nil, nil, fmt.Errorf("Validating final data values:\n%w", err)
 		}
 	}
 	return values, libValues, err
 }
 
+// LibrarySchema calculates the final schema for the Data Values of the private library at "libRef" (e.g. "@lib"), as
+// that library sees it when evaluated from this one: its own schema file(s) overlaid by those in "librarySchemas"
+// addressed to it (e.g. via #@library/ref).
+func (ll *LibraryExecution) LibrarySchema(libRef ref.LibraryRef, librarySchemas []*datavalues.SchemaEnvelope) (*datavalues.Schema, error) {
+	libVal, err := ll.libraryValueAt(libRef, nil, librarySchemas)
+	if err != nil {
+		return nil, err
+	}
+	schema, _, err := libVal.librarySchemas(ll.libraryExecFactory.New(libVal.libraryCtx))
+	return schema, err
+}
+
+// LibraryValues calculates the final Data Values of the private library at "libRef" (e.g. "@lib") given its "schema"
+// (see LibrarySchema()): its own data values file(s) overlaid by those in "libraryValues" addressed to it.
+func (ll *LibraryExecution) LibraryValues(libRef ref.LibraryRef, schema *datavalues.Schema, libraryValues []*datavalues.Envelope) (*datavalues.Envelope, error) {
+	libVal, err := ll.libraryValueAt(libRef, libraryValues, nil)
+	if err != nil {
+		return nil, err
+	}
+	values, _, err := libVal.libraryValues(ll.libraryExecFactory.New(libVal.libraryCtx), schema)
+	return values, err
+}
+
+// ValidateLibraryValues calculates (and so, validates) the final Data Values of each private library to which any of
+// "libraryValues" or "librarySchemas" are addressed (and, in turn, of their private libraries), without evaluating
+// templates.
+//
+// Data Values given to a library from within templates (i.e. via library.get(...).with_data_values()) and those
+// addressed only by alias are not known until templates are evaluated: they are not validated, here.
+func (ll *LibraryExecution) ValidateLibraryValues(libraryValues []*datavalues.Envelope, librarySchemas []*datavalues.SchemaEnvelope) error {
+	var libRefs []ref.LibraryRef
+	seen := map[string]bool{}
+	addLibRef := func(libRef ref.LibraryRef, addressed bool) {
+		if addressed && libRef.Path != "" && !seen[libRef.AsString()] {
+			seen[libRef.AsString()] = true
+			libRefs = append(libRefs, libRef)
+		}
+	}
+	for _, dv := range libraryValues {
+		addLibRef(dv.IntendedForLibrary())
+	}
+	for _, docSchema := range librarySchemas {
+		addLibRef(docSchema.IntendedForLibrary())
+	}
+
+	for _, libRef := range libRefs {
+		libVal, err := ll.libraryValueAt(libRef, libraryValues, librarySchemas)
+		if err != nil {
+			return err
+		}
+		libExec := ll.libraryExecFactory.New(libVal.libraryCtx)
+
+		schema, childSchemas, err := libVal.librarySchemas(libExec)
+		if err != nil {
+			return err
+		}
+		_, childValues, err := libVal.libraryValues(libExec, schema)
+		if err != nil {
+			return err
+		}
+		err = libExec.ValidateLibraryValues(childValues, childSchemas)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ll *LibraryExecution) libraryValueAt(libRef ref.LibraryRef, libraryValues []*datavalues.Envelope, librarySchemas []*datavalues.SchemaEnvelope) (*libraryValue, error) {
+	foundLib, err := ll.libraryCtx.Current.FindAccessibleLibrary(libRef.Path)
+	if err != nil {
+		return nil, err
+	}
+	libraryCtx := LibraryExecutionContext{Current: foundLib, Root: foundLib}
+	return &libraryValue{libRef.Path, libRef.Alias, libraryValues, librarySchemas, libraryCtx, ll.libraryExecFactory}, nil
+}
+
 // validateValues runs validations on Data Values for the current library.
 // Validations are attached to data value and come from two sources:
 //  1. @schema/validation annotations in a data values schema file.
@@ -121,13 +204,27 @@ func (ll *LibraryExecution) validateValues(values *datavalues.Envelope) error {
 		return err
 	}
 
-	chk, err := validations.Run(values.Doc, "run-data-values-validations")
+	if ll.validateFormats {
+		formatChk := schema.CheckFormats(values.Doc)
+		if formatChk.HasInvalidations() {
+			return validations.CheckError{Check: formatChk}
+		}
+	}
+
+	chk, err := validations.RunWithOpts(values.Doc, "run-data-values-validations", validations.RunOpts{
+		MessageCatalog:     ll.validationMessages,
+		EnableExperimental: ll.experimentalValidations,
+		Paths:              ll.validationPaths,
+	})
 	if err != nil {
 		return err
 	}
+	if ll.validationReporter != nil {
+		ll.validationReporter(chk)
+	}
 
 	if chk.HasInvalidations() {
-		return errors.New(chk.ResultsAsString())
+		return validations.CheckError{Check: chk}
 	}
 
 	return nil
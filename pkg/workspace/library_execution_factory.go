@@ -5,6 +5,7 @@ package workspace
 
 import (
 	"github.com/vmware-tanzu/carvel-ytt/pkg/cmd/ui"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/validations"
 )
 
 // LibraryExecutionContext holds the total set of inputs that are involved in a LibraryExecution.
@@ -19,17 +20,24 @@ type LibraryExecutionFactory struct {
 	templateLoaderOpts TemplateLoaderOpts
 
 	skipDataValuesValidation bool
+	validationMessages       validations.MessageCatalog
+	experimentalValidations  bool
+	validationPaths          validations.PathFilter
+	validateFormats          bool
+	validationReporter       func(validations.Check)
 }
 
 // NewLibraryExecutionFactory configures a new instance of a LibraryExecutionFactory.
 func NewLibraryExecutionFactory(ui ui.UI, templateLoaderOpts TemplateLoaderOpts, skipDataValuesValidation bool) *LibraryExecutionFactory {
-	return &LibraryExecutionFactory{ui, templateLoaderOpts, skipDataValuesValidation}
+	return &LibraryExecutionFactory{ui: ui, templateLoaderOpts: templateLoaderOpts, skipDataValuesValidation: skipDataValuesValidation}
 }
 
 // WithTemplateLoaderOptsOverrides produces a new LibraryExecutionFactory identical to this one, except it configures
 // its TemplateLoader with the merge of the supplied TemplateLoaderOpts over this factory's configuration.
 func (f *LibraryExecutionFactory) WithTemplateLoaderOptsOverrides(overrides TemplateLoaderOptsOverrides) *LibraryExecutionFactory {
-	return NewLibraryExecutionFactory(f.ui, f.templateLoaderOpts.Merge(overrides), f.skipDataValuesValidation)
+	result := *f
+	result.templateLoaderOpts = f.templateLoaderOpts.Merge(overrides)
+	return &result
 }
 
 // ThatSkipsDataValuesValidations produces a new LibraryExecutionFactory identical to this one, except it might also
@@ -39,10 +47,58 @@ func (f *LibraryExecutionFactory) WithTemplateLoaderOptsOverrides(overrides Temp
 // no effect. This stems from the assumption that the downstream user is the most informed whether validations ought to
 // be run.
 func (f *LibraryExecutionFactory) ThatSkipsDataValuesValidations(skipDataValuesValidation bool) *LibraryExecutionFactory {
-	return NewLibraryExecutionFactory(f.ui, f.templateLoaderOpts, f.skipDataValuesValidation || skipDataValuesValidation)
+	result := *f
+	result.skipDataValuesValidation = f.skipDataValuesValidation || skipDataValuesValidation
+	return &result
+}
+
+// WithValidationMessages produces a new LibraryExecutionFactory identical to this one, except that violations of
+// Data Values validations are reported using the text in "catalog" (for those rules with an entry).
+func (f *LibraryExecutionFactory) WithValidationMessages(catalog validations.MessageCatalog) *LibraryExecutionFactory {
+	result := *f
+	result.validationMessages = catalog
+	return &result
+}
+
+// ThatRunsExperimentalValidations produces a new LibraryExecutionFactory identical to this one, except it might also
+// run the validations over Data Values that are marked experimental (via experimental=True).
+func (f *LibraryExecutionFactory) ThatRunsExperimentalValidations(experimentalValidations bool) *LibraryExecutionFactory {
+	result := *f
+	result.experimentalValidations = experimentalValidations
+	return &result
+}
+
+// WithValidationPaths produces a new LibraryExecutionFactory identical to this one, except that only the validations of
+// Data Values whose path is selected by "paths" are run (the others being skipped).
+func (f *LibraryExecutionFactory) WithValidationPaths(paths validations.PathFilter) *LibraryExecutionFactory {
+	result := *f
+	result.validationPaths = paths
+	return &result
+}
+
+// ThatValidatesFormats produces a new LibraryExecutionFactory identical to this one, except it might also check that
+// Data Values with a declared format (via @schema/format) are encoded in that format.
+func (f *LibraryExecutionFactory) ThatValidatesFormats(validateFormats bool) *LibraryExecutionFactory {
+	result := *f
+	result.validateFormats = validateFormats
+	return &result
+}
+
+// WithValidationReporter produces a new LibraryExecutionFactory identical to this one, except that the Check resulting
+// from validating each library's Data Values (valid or not) is given to "reporter".
+func (f *LibraryExecutionFactory) WithValidationReporter(reporter func(validations.Check)) *LibraryExecutionFactory {
+	result := *f
+	result.validationReporter = reporter
+	return &result
 }
 
 // New produces a new instance of a LibraryExecution, set with the configuration and dependencies of this factory.
 func (f *LibraryExecutionFactory) New(ctx LibraryExecutionContext) *LibraryExecution {
-	return NewLibraryExecution(ctx, f.ui, f.templateLoaderOpts, f, f.skipDataValuesValidation)
+	libraryExecution := NewLibraryExecution(ctx, f.ui, f.templateLoaderOpts, f, f.skipDataValuesValidation)
+	libraryExecution.validationMessages = f.validationMessages
+	libraryExecution.experimentalValidations = f.experimentalValidations
+	libraryExecution.validationPaths = f.validationPaths
+	libraryExecution.validateFormats = f.validateFormats
+	libraryExecution.validationReporter = f.validationReporter
+	return libraryExecution
 }
@@ -143,6 +143,18 @@ func (b LibraryModule) getOpts(kwargs []starlark.Tuple) (string, TemplateLoaderO
 	return alias, overrides, skipDataValuesValidation, nil
 }
 
+// withErrCause records any error of "wrappedFunc" as the cause of evaluation failing, so that the kind of failure
+// (e.g. the library's data values are invalid, see validations.CheckError) is not lost to Starlark.
+func withErrCause(wrappedFunc core.StarlarkFunc) core.StarlarkFunc {
+	return func(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		val, err := wrappedFunc(thread, f, args, kwargs)
+		if err != nil {
+			core.RecordErrCause(thread, err)
+		}
+		return val, err
+	}
+}
+
 // libraryValue is an instance of a private library.
 // Instances are immutable.
 type libraryValue struct {
@@ -166,9 +178,9 @@ func (l *libraryValue) AsStarlarkValue() starlark.Value {
 		Members: starlark.StringDict{
 			"with_data_values":        starlark.NewBuiltin("library.with_data_values", core.ErrWrapper(l.WithDataValues)),
 			"with_data_values_schema": starlark.NewBuiltin("library.with_data_values_schema", core.ErrWrapper(l.WithDataValuesSchema)),
-			"eval":                    starlark.NewBuiltin("library.eval", core.ErrWrapper(core.ErrDescWrapper(evalErrMsg, l.Eval))),
-			"export":                  starlark.NewBuiltin("library.export", core.ErrWrapper(core.ErrDescWrapper(exportErrMsg, l.Export))),
-			"data_values":             starlark.NewBuiltin("library.data_values", core.ErrWrapper(core.ErrDescWrapper(exportErrMsg, l.DataValues))),
+			"eval":                    starlark.NewBuiltin("library.eval", core.ErrWrapper(core.ErrDescWrapper(evalErrMsg, withErrCause(l.Eval)))),
+			"export":                  starlark.NewBuiltin("library.export", core.ErrWrapper(core.ErrDescWrapper(exportErrMsg, withErrCause(l.Export)))),
+			"data_values":             starlark.NewBuiltin("library.data_values", core.ErrWrapper(core.ErrDescWrapper(exportErrMsg, withErrCause(l.DataValues)))),
 		},
 	}
 }
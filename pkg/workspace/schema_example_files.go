@@ -0,0 +1,93 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package workspace
+
+import (
+	"fmt"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/schema"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/template"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/template/core"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/yamlmeta"
+)
+
+// exampleFileEmbedder replaces each example given by reference to a file (i.e. via `file=` on @schema/examples) with
+// the (parsed) contents of that file, so that it is as if the example was given inline.
+//
+// Paths are relative to the library containing the schema file (just as with `data.read()`).
+type exampleFileEmbedder struct {
+	loader DataLoader
+}
+
+// Visit embeds the example file referenced by @schema/examples on "node", if any.
+func (e exampleFileEmbedder) Visit(node yamlmeta.Node) error {
+	anns := template.NewAnnotations(node)
+	ann, found := anns[schema.AnnotationExamples]
+	if !found {
+		return nil
+	}
+
+	var kwargs []starlark.Tuple
+	var fileExample starlark.Value
+	for _, kwarg := range ann.Kwargs {
+		name, err := core.NewStarlarkValue(kwarg[0]).AsString()
+		if err != nil {
+			return err
+		}
+		if name != schema.ExamplesAnnotationKwargFile {
+			kwargs = append(kwargs, kwarg)
+			continue
+		}
+		fileExample, err = e.example(kwarg[1], ann)
+		if err != nil {
+			return err
+		}
+	}
+	if fileExample == nil {
+		return nil
+	}
+
+	ann.Args = append(append(starlark.Tuple{}, ann.Args...), fileExample)
+	ann.Kwargs = kwargs
+	anns[schema.AnnotationExamples] = ann
+	return nil
+}
+
+// example produces the (description, value) tuple of the example referenced by "fileRef" — a (description, path) tuple.
+func (e exampleFileEmbedder) example(fileRef starlark.Value, ann template.NodeAnnotation) (starlark.Value, error) {
+	errPrefix := fmt.Sprintf("@%s (at %s)", schema.AnnotationExamples, ann.Position.AsCompactString())
+
+	refTuple, ok := fileRef.(starlark.Tuple)
+	if !ok || len(refTuple) != 2 {
+		return nil, fmt.Errorf("%s: expected keyword argument %q to be a 2-tuple containing description (string) and path to the example (string), but was %s",
+			errPrefix, schema.ExamplesAnnotationKwargFile, fileRef.Type())
+	}
+	path, err := core.NewStarlarkValue(refTuple[1]).AsString()
+	if err != nil {
+		return nil, fmt.Errorf("%s: expected path to the example to be a string, but was %s", errPrefix, refTuple[1].Type())
+	}
+
+	fileBs, err := e.loader.FileData(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", errPrefix, err)
+	}
+
+	docSet, err := yamlmeta.NewDocumentSetFromBytes(fileBs, yamlmeta.DocSetOpts{AssociatedName: path})
+	if err != nil {
+		return nil, fmt.Errorf("%s: expected example file '%s' to be YAML (or JSON): %s", errPrefix, path, err)
+	}
+	var docs []*yamlmeta.Document
+	for _, doc := range docSet.Items {
+		if !doc.IsEmpty() {
+			docs = append(docs, doc)
+		}
+	}
+	if len(docs) != 1 {
+		return nil, fmt.Errorf("%s: expected example file '%s' to contain exactly one (non-empty) document, but found %d", errPrefix, path, len(docs))
+	}
+
+	value := core.NewGoValue(docs[0].AsInterface()).AsStarlarkValue()
+	return starlark.Tuple{refTuple[0], value}, nil
+}
@@ -5,6 +5,7 @@ package workspace
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/k14s/starlark-go/starlark"
@@ -33,6 +34,7 @@ type TemplateLoaderOpts struct {
 	IgnoreUnknownComments   bool
 	ImplicitMapKeyOverrides bool
 	StrictYAML              bool
+	AllowedEnvVars          []string // environment variables that may be read via @ytt:env
 }
 
 // TemplateLoaderOptsOverrides hold potential overriding values to be merged over a TemplateLoaderOpts.
@@ -196,7 +198,8 @@ func (l *TemplateLoader) EvalYAML(libraryCtx LibraryExecutionContext, file *file
 
 	yttLibrary := yttlibrary.NewAPI(compiledTemplate.TplReplaceNode,
 		yttlibrary.NewDataModule(l.values.Doc, DataLoader{libraryCtx}),
-		NewLibraryModule(libraryCtx, l.libraryExecFactory, l.libraryValuess, l.librarySchemas).AsModule(), l.ui)
+		NewLibraryModule(libraryCtx, l.libraryExecFactory, l.libraryValuess, l.librarySchemas).AsModule(),
+		yttlibrary.NewEnvModule(l.opts.AllowedEnvVars, os.LookupEnv), l.ui)
 
 	thread := l.newThread(libraryCtx, yttLibrary, file)
 
@@ -238,7 +241,8 @@ func (l *TemplateLoader) EvalText(libraryCtx LibraryExecutionContext, file *file
 
 	yttLibrary := yttlibrary.NewAPI(compiledTemplate.TplReplaceNode,
 		yttlibrary.NewDataModule(l.values.Doc, DataLoader{libraryCtx}),
-		NewLibraryModule(libraryCtx, l.libraryExecFactory, l.libraryValuess, l.librarySchemas).AsModule(), l.ui)
+		NewLibraryModule(libraryCtx, l.libraryExecFactory, l.libraryValuess, l.librarySchemas).AsModule(),
+		yttlibrary.NewEnvModule(l.opts.AllowedEnvVars, os.LookupEnv), l.ui)
 
 	thread := l.newThread(libraryCtx, yttLibrary, file)
 
@@ -268,7 +272,8 @@ func (l *TemplateLoader) EvalStarlark(libraryCtx LibraryExecutionContext, file *
 
 	yttLibrary := yttlibrary.NewAPI(compiledTemplate.TplReplaceNode,
 		yttlibrary.NewDataModule(l.values.Doc, DataLoader{libraryCtx}),
-		NewLibraryModule(libraryCtx, l.libraryExecFactory, l.libraryValuess, l.librarySchemas).AsModule(), l.ui)
+		NewLibraryModule(libraryCtx, l.libraryExecFactory, l.libraryValuess, l.librarySchemas).AsModule(),
+		yttlibrary.NewEnvModule(l.opts.AllowedEnvVars, os.LookupEnv), l.ui)
 
 	thread := l.newThread(libraryCtx, yttLibrary, file)
 
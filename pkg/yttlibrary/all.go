@@ -41,6 +41,7 @@ func NewAPI(
 	replaceNodeFunc tplcore.StarlarkFunc,
 	dataMod DataModule,
 	libraryMod starlark.StringDict,
+	envMod EnvModule,
 	ui ui.UI) API {
 
 	std := map[string]starlark.StringDict{
@@ -62,6 +63,7 @@ func NewAPI(
 		// Templating
 		"template": NewTemplateModule(replaceNodeFunc).AsModule(),
 		"data":     dataMod.AsModule(),
+		"env":      envMod.AsModule(),
 
 		// Object building
 		"struct":  StructAPI,
@@ -4,13 +4,22 @@
 package yttlibrary
 
 import (
+	"encoding/json"
 	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
+	semver "github.com/hashicorp/go-version"
 	"github.com/k14s/starlark-go/starlark"
 	"github.com/k14s/starlark-go/starlarkstruct"
 	"github.com/k14s/starlark-go/syntax"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/orderedmap"
 	"github.com/vmware-tanzu/carvel-ytt/pkg/template/core"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/yamlmeta"
 )
 
 // NewAssertModule constructs a new instance of AssertModule, respecting the "validations" experiment flag.
@@ -34,6 +43,14 @@ func (m AssertModule) AsModule() starlark.StringDict {
 	members["not_null"] = starlark.NewBuiltin("assert.not_null", core.ErrWrapper(m.NotNull))
 	members["one_not_null"] = starlark.NewBuiltin("assert.one_not_null", core.ErrWrapper(m.OneNotNull))
 	members["one_of"] = starlark.NewBuiltin("assert.one_of", core.ErrWrapper(m.OneOf))
+	members["not_one_of"] = starlark.NewBuiltin("assert.not_one_of", core.ErrWrapper(m.NotOneOf))
+	members["max_decimals"] = starlark.NewBuiltin("assert.max_decimals", core.ErrWrapper(m.MaxDecimals))
+	members["sorted"] = starlark.NewBuiltin("assert.sorted", core.ErrWrapper(m.Sorted))
+	members["contiguous"] = starlark.NewBuiltin("assert.contiguous", core.ErrWrapper(m.Contiguous))
+	members["lowercase"] = starlark.NewBuiltin("assert.lowercase", core.ErrWrapper(m.Lowercase))
+	members["uppercase"] = starlark.NewBuiltin("assert.uppercase", core.ErrWrapper(m.Uppercase))
+	members["all"] = starlark.NewBuiltin("assert.all", core.ErrWrapper(m.All))
+	members["any"] = starlark.NewBuiltin("assert.any", core.ErrWrapper(m.Any))
 	return starlark.StringDict{
 		"assert": &starlarkstruct.Module{
 			Name:    "assert",
@@ -142,7 +159,28 @@ func (a *Assertion) ConversionHint() string {
 	return a.Type() + " does not encode (did you mean to call check()?)"
 }
 
+// Binary composes this Assertion with another: "a & b" is satisfied when both are (see NewAssertAll()); "a | b", when
+// either is (see NewAssertAny()).
+func (a *Assertion) Binary(op syntax.Token, y starlark.Value, side starlark.Side) (starlark.Value, error) {
+	other, ok := y.(*Assertion)
+	if !ok {
+		return nil, nil // unhandled
+	}
+	parts := []starlark.Callable{a.check, other.check}
+	if side == starlark.Right {
+		parts = []starlark.Callable{other.check, a.check}
+	}
+	switch op {
+	case syntax.AMP:
+		return NewAssertAll(parts), nil
+	case syntax.PIPE:
+		return NewAssertAny(parts), nil
+	}
+	return nil, nil // unhandled
+}
+
 // NewAssertionFromSource creates an Assertion whose "check" attribute is the lambda expression defined in "checkSrc".
+// The Assertion is named after "funcName".
 func NewAssertionFromSource(funcName, checkSrc string, env starlark.StringDict) *Assertion {
 	expr, err := syntax.ParseExpr(funcName, checkSrc, syntax.BlockScanner)
 	if err != nil {
@@ -158,21 +196,29 @@ func NewAssertionFromSource(funcName, checkSrc string, env starlark.StringDict)
 	a := &Assertion{check: evalExpr.(*starlark.Function)}
 	m := orderedmap.NewMap()
 	m.Set("check", a.check)
+	m.Set("name", starlark.String(assertionName(funcName)))
 	a.StarlarkStruct = core.NewStarlarkStruct(m)
 	return a
 }
 
 // NewAssertionFromStarlarkFunc creates an Assertion whose "check" attribute is "checkFunc".
+// The Assertion is named after "funcName".
 func NewAssertionFromStarlarkFunc(funcName string, checkFunc core.StarlarkFunc) *Assertion {
 	a := &Assertion{check: starlark.NewBuiltin(funcName, checkFunc)}
 
 	m := orderedmap.NewMap()
 	m.Set("check", a.check)
+	m.Set("name", starlark.String(assertionName(funcName)))
 	a.StarlarkStruct = core.NewStarlarkStruct(m)
 
 	return a
 }
 
+// assertionName derives the name by which an Assertion is identified (e.g. "min" for "assert.min").
+func assertionName(funcName string) string {
+	return strings.TrimPrefix(funcName, "assert.")
+}
+
 // NewAssertMaxLen produces an Assertion that a given sequence is at most "maximum" in length.
 //
 // see also: https://github.com/google/starlark-go/blob/master/doc/spec.md#len
@@ -263,6 +309,66 @@ func (m AssertModule) Max(_ *starlark.Thread, _ *starlark.Builtin, args starlark
 	return maxFunc, nil
 }
 
+// NewAssertExclusiveMin produces an Assertion that a given value is greater than (and not equal to) "min".
+func NewAssertExclusiveMin(min starlark.Value) *Assertion {
+	return NewAssertionFromSource(
+		"assert.exclusive_min",
+		`lambda val: yaml.decode(yaml.encode(val)) > yaml.decode(yaml.encode(min)) or fail("value <= {}".format(yaml.decode(yaml.encode(min))))`,
+		starlark.StringDict{"min": min, "yaml": YAMLAPI["yaml"]},
+	)
+}
+
+// NewAssertExclusiveMax produces an Assertion that a given value is less than (and not equal to) "max".
+func NewAssertExclusiveMax(max starlark.Value) *Assertion {
+	return NewAssertionFromSource(
+		"assert.exclusive_max",
+		`lambda val: yaml.decode(yaml.encode(val)) < yaml.decode(yaml.encode(max)) or fail("value >= {}".format(yaml.decode(yaml.encode(max))))`,
+		starlark.StringDict{"max": max, "yaml": YAMLAPI["yaml"]},
+	)
+}
+
+// DateLayout is the form of dates that can be compared chronologically (see NewAssertMinDate() and
+// NewAssertMaxDate()): a full-date as defined by RFC 3339.
+const DateLayout = "2006-01-02"
+
+// IsDate reports whether "value" is a string holding a date (in the form of DateLayout).
+func IsDate(value starlark.Value) bool {
+	str, ok := value.(starlark.String)
+	if !ok {
+		return false
+	}
+	_, err := time.Parse(DateLayout, str.GoString())
+	return err == nil
+}
+
+// NewAssertMinDate produces an Assertion that a given string is a date no earlier than "min" (also a date).
+func NewAssertMinDate(min starlark.String) *Assertion {
+	return NewAssertionFromStarlarkFunc("assert.min", AssertModule{}.dateCheck(min, func(value, bound time.Time) bool { return !value.Before(bound) }, "before"))
+}
+
+// NewAssertMaxDate produces an Assertion that a given string is a date no later than "max" (also a date).
+func NewAssertMaxDate(max starlark.String) *Assertion {
+	return NewAssertionFromStarlarkFunc("assert.max", AssertModule{}.dateCheck(max, func(value, bound time.Time) bool { return !value.After(bound) }, "after"))
+}
+
+// dateCheck asserts that a date is "within" the date "bound"; if not, it is described as being "outside" of it.
+func (m AssertModule) dateCheck(bound starlark.String, within func(value, bound time.Time) bool, outside string) core.StarlarkFunc {
+	boundDate, err := time.Parse(DateLayout, bound.GoString())
+	if err != nil {
+		panic(fmt.Sprintf("Expected bound to be a date, but was %s", bound.String()))
+	}
+	return m.stringCheck(func(str string) error {
+		date, err := time.Parse(DateLayout, str)
+		if err != nil {
+			return fmt.Errorf("%q is not a date (e.g. 2006-01-02), so cannot be compared with %s", str, bound.GoString())
+		}
+		if !within(date, boundDate) {
+			return fmt.Errorf("%s is %s %s", str, outside, bound.GoString())
+		}
+		return nil
+	})
+}
+
 // NewAssertNotNull produces an Assertion that a given value is not null.
 func NewAssertNotNull() *Assertion {
 	return NewAssertionFromSource(
@@ -379,6 +485,809 @@ func (m AssertModule) oneNotNullCheck(keys starlark.Sequence) core.StarlarkFunc
 	}
 }
 
+// NewAssertRequiredIf produces an Assertion that a given value is a map in which each of the "required" keys is not null
+// whenever the item at "key" equals "value" (or, if "negate" is true, whenever it does not).
+func NewAssertRequiredIf(key, value starlark.Value, required starlark.Sequence, negate bool) *Assertion {
+	return NewAssertionFromStarlarkFunc("assert.required_if", AssertModule{}.requiredIfCheck(key, value, required, negate))
+}
+
+func (m AssertModule) requiredIfCheck(key, value starlark.Value, required starlark.Sequence, negate bool) core.StarlarkFunc {
+	return func(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if args.Len() != 1 {
+			return starlark.None, fmt.Errorf("check: got %d arguments, want %d", args.Len(), 1)
+		}
+		val, err := m.yamlEncodeDecode(args[0])
+		if err != nil {
+			return nil, err
+		}
+		dict, ok := val.(*starlark.Dict)
+		if !ok {
+			return nil, fmt.Errorf("check: value must be a map or dict, but was '%s'", val.Type())
+		}
+
+		expected, err := m.yamlEncodeDecode(value)
+		if err != nil {
+			return nil, err
+		}
+		actual, _, err := dict.Get(key)
+		if err != nil {
+			return nil, fmt.Errorf("check: unexpected error while looking up key %s in dict %s", key, dict)
+		}
+		if actual == nil {
+			actual = starlark.None
+		}
+		matches, err := starlark.Equal(actual, expected)
+		if err != nil {
+			return nil, err
+		}
+		if matches == negate {
+			return starlark.True, nil
+		}
+
+		var nulls []starlark.Value
+		var reqKey starlark.Value
+		keys := required.Iterate()
+		defer keys.Done()
+		for keys.Next(&reqKey) {
+			v, found, err := dict.Get(reqKey)
+			if err != nil {
+				return nil, fmt.Errorf("check: unexpected error while looking up key %s in dict %s", reqKey, dict)
+			}
+			if !found || v == starlark.None {
+				nulls = append(nulls, reqKey)
+			}
+		}
+		if len(nulls) > 0 {
+			return nil, fmt.Errorf("check: %s are null", starlark.NewList(nulls).String())
+		}
+		return starlark.True, nil
+	}
+}
+
+// NewAssertRequiredTogether produces an Assertion that a given value is a map in which the "keys" are either all not
+// null or all null (or absent).
+func NewAssertRequiredTogether(keys starlark.Sequence) *Assertion {
+	return NewAssertionFromStarlarkFunc("assert.required_together", AssertModule{}.requiredTogetherCheck(keys))
+}
+
+func (m AssertModule) requiredTogetherCheck(keys starlark.Sequence) core.StarlarkFunc {
+	return func(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if args.Len() != 1 {
+			return starlark.None, fmt.Errorf("check: got %d arguments, want %d", args.Len(), 1)
+		}
+		val, err := m.yamlEncodeDecode(args[0])
+		if err != nil {
+			return nil, err
+		}
+		dict, ok := val.(*starlark.Dict)
+		if !ok {
+			return nil, fmt.Errorf("check: value must be a map or dict, but was '%s'", val.Type())
+		}
+
+		var nulls, notNulls []starlark.Value
+		var key starlark.Value
+		keysIter := keys.Iterate()
+		defer keysIter.Done()
+		for keysIter.Next(&key) {
+			v, found, err := dict.Get(key)
+			if err != nil {
+				return nil, fmt.Errorf("check: unexpected error while looking up key %s in dict %s", key, dict)
+			}
+			if !found || v == starlark.None {
+				nulls = append(nulls, key)
+			} else {
+				notNulls = append(notNulls, key)
+			}
+		}
+		if len(nulls) > 0 && len(notNulls) > 0 {
+			return nil, fmt.Errorf("check: %s are null (while %s are not)", starlark.NewList(nulls).String(), starlark.NewList(notNulls).String())
+		}
+		return starlark.True, nil
+	}
+}
+
+// NewAssertRequiredKeys produces an Assertion that a given value is a map in which each of the "keys" is present and
+// not null.
+func NewAssertRequiredKeys(keys starlark.Sequence) *Assertion {
+	return NewAssertionFromStarlarkFunc("assert.required_keys", AssertModule{}.requiredKeysCheck(keys))
+}
+
+func (m AssertModule) requiredKeysCheck(keys starlark.Sequence) core.StarlarkFunc {
+	return func(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if args.Len() != 1 {
+			return starlark.None, fmt.Errorf("check: got %d arguments, want %d", args.Len(), 1)
+		}
+		val, err := m.yamlEncodeDecode(args[0])
+		if err != nil {
+			return nil, err
+		}
+		dict, ok := val.(*starlark.Dict)
+		if !ok {
+			return nil, fmt.Errorf("check: value must be a map or dict, but was '%s'", val.Type())
+		}
+
+		var missing, nulls []starlark.Value
+		var key starlark.Value
+		keysIter := keys.Iterate()
+		defer keysIter.Done()
+		for keysIter.Next(&key) {
+			v, found, err := dict.Get(key)
+			if err != nil {
+				return nil, fmt.Errorf("check: unexpected error while looking up key %s in dict %s", key, dict)
+			}
+			if !found {
+				missing = append(missing, key)
+			} else if v == starlark.None {
+				nulls = append(nulls, key)
+			}
+		}
+		switch {
+		case len(missing) > 0 && len(nulls) > 0:
+			return nil, fmt.Errorf("check: %s are missing (and %s are null)", starlark.NewList(missing).String(), starlark.NewList(nulls).String())
+		case len(missing) > 0:
+			return nil, fmt.Errorf("check: %s are missing", starlark.NewList(missing).String())
+		case len(nulls) > 0:
+			return nil, fmt.Errorf("check: %s are null", starlark.NewList(nulls).String())
+		}
+		return starlark.True, nil
+	}
+}
+
+// NewAssertMaxDecimals produces an Assertion that a given number has at most "maximum" digits after the decimal point.
+func NewAssertMaxDecimals(maximum starlark.Int) *Assertion {
+	return NewAssertionFromStarlarkFunc("assert.max_decimals", AssertModule{}.maxDecimalsCheck(maximum))
+}
+
+// MaxDecimals is a core.StarlarkFunc wrapping NewAssertMaxDecimals()
+func (m AssertModule) MaxDecimals(_ *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, _ []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() != 1 {
+		return starlark.None, fmt.Errorf("got %d arguments, want %d", args.Len(), 1)
+	}
+
+	max, err := starlark.NumberToInt(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("expected value to be an number, but was %s", args[0].Type())
+	}
+	return NewAssertMaxDecimals(max), nil
+}
+
+func (m AssertModule) maxDecimalsCheck(maximum starlark.Int) core.StarlarkFunc {
+	return func(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if args.Len() != 1 {
+			return starlark.None, fmt.Errorf("check: got %d arguments, want %d", args.Len(), 1)
+		}
+		val, err := m.yamlEncodeDecode(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		var decimals int
+		switch typedVal := val.(type) {
+		case starlark.Int:
+			decimals = 0
+		case starlark.Float:
+			// shortest representation that round-trips, so that (e.g.) 0.1 has one decimal place
+			repr := strconv.FormatFloat(float64(typedVal), 'f', -1, 64)
+			if idx := strings.Index(repr, "."); idx >= 0 {
+				decimals = len(repr) - idx - 1
+			}
+		default:
+			return nil, fmt.Errorf("check: value must be a number, but was '%s'", val.Type())
+		}
+
+		if starlark.MakeInt(decimals).Sub(maximum).Sign() > 0 {
+			return nil, fmt.Errorf("check: %d decimal places", decimals)
+		}
+		return starlark.True, nil
+	}
+}
+
+// NewAssertBitFlags produces an Assertion that a given integer is a (bitwise-OR) combination of "flags": that is, that
+// each of its bits is set by some flag none of whose bits it lacks.
+func NewAssertBitFlags(flags []uint64) *Assertion {
+	return NewAssertionFromStarlarkFunc("assert.bit_flags", AssertModule{}.bitFlagsCheck(flags))
+}
+
+func (m AssertModule) bitFlagsCheck(flags []uint64) core.StarlarkFunc {
+	return func(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if args.Len() != 1 {
+			return starlark.None, fmt.Errorf("check: got %d arguments, want %d", args.Len(), 1)
+		}
+		val, err := m.yamlEncodeDecode(args[0])
+		if err != nil {
+			return nil, err
+		}
+		i, ok := val.(starlark.Int)
+		if !ok {
+			return nil, fmt.Errorf("check: value must be an int, but was '%s'", val.Type())
+		}
+		bits, ok := i.Uint64()
+		if !ok {
+			return nil, fmt.Errorf("check: %s is not a combination of flags", i.String())
+		}
+
+		var combined uint64
+		for _, flag := range flags {
+			if flag&^bits == 0 {
+				combined |= flag
+			}
+		}
+		if invalid := bits &^ combined; invalid != 0 {
+			var invalidBits []string
+			for bit := uint64(1); bit != 0 && bit <= invalid; bit <<= 1 {
+				if invalid&bit != 0 {
+					invalidBits = append(invalidBits, strconv.FormatUint(bit, 10))
+				}
+			}
+			return nil, fmt.Errorf("check: bits %s (of %d) are not set by any of the flags", strings.Join(invalidBits, ", "), bits)
+		}
+		return starlark.True, nil
+	}
+}
+
+// Orders in which the items of a list can be expected to be sorted (see NewAssertSorted()).
+const (
+	SortedAscending  starlark.String = "asc"
+	SortedDescending starlark.String = "desc"
+)
+
+// NewAssertSorted produces an Assertion that the items of a given list are in "order" (either SortedAscending or
+// SortedDescending), as determined by comparing them.
+func NewAssertSorted(order starlark.String) *Assertion {
+	return NewAssertionFromStarlarkFunc("assert.sorted", AssertModule{}.sortedCheck(order))
+}
+
+// Sorted is a core.StarlarkFunc wrapping NewAssertSorted()
+func (m AssertModule) Sorted(_ *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	order := SortedAscending
+	if err := starlark.UnpackArgs(f.Name(), args, kwargs, "order?", &order); err != nil {
+		return starlark.None, err
+	}
+	if order != SortedAscending && order != SortedDescending {
+		return starlark.None, fmt.Errorf("expected order to be %s or %s, but was %s", SortedAscending.String(), SortedDescending.String(), order.String())
+	}
+	return NewAssertSorted(order), nil
+}
+
+func (m AssertModule) sortedCheck(order starlark.String) core.StarlarkFunc {
+	outOfOrder := syntax.LT
+	if order == SortedDescending {
+		outOfOrder = syntax.GT
+	}
+	return func(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if args.Len() != 1 {
+			return starlark.None, fmt.Errorf("check: got %d arguments, want %d", args.Len(), 1)
+		}
+		val, err := m.yamlEncodeDecode(args[0])
+		if err != nil {
+			return nil, err
+		}
+		list, ok := val.(*starlark.List)
+		if !ok {
+			return nil, fmt.Errorf("check: value must be a list, but was '%s'", val.Type())
+		}
+
+		for idx := 1; idx < list.Len(); idx++ {
+			prev, curr := list.Index(idx-1), list.Index(idx)
+			misplaced, err := starlark.Compare(outOfOrder, curr, prev)
+			if err != nil {
+				return nil, fmt.Errorf("check: %s", err)
+			}
+			if misplaced {
+				return nil, fmt.Errorf("check: item at index %d (%s) is out of order", idx, curr.String())
+			}
+		}
+		return starlark.True, nil
+	}
+}
+
+// NewAssertLowercase produces an Assertion that a given string contains no uppercase letters.
+func NewAssertLowercase() *Assertion {
+	return NewAssertionFromStarlarkFunc("assert.lowercase", AssertModule{}.caseCheck(strings.ToLower, "uppercase"))
+}
+
+// Lowercase is a core.StarlarkFunc wrapping NewAssertLowercase()
+func (m AssertModule) Lowercase(_ *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackArgs(f.Name(), args, kwargs); err != nil {
+		return starlark.None, err
+	}
+	return NewAssertLowercase(), nil
+}
+
+// NewAssertUppercase produces an Assertion that a given string contains no lowercase letters.
+func NewAssertUppercase() *Assertion {
+	return NewAssertionFromStarlarkFunc("assert.uppercase", AssertModule{}.caseCheck(strings.ToUpper, "lowercase"))
+}
+
+// Uppercase is a core.StarlarkFunc wrapping NewAssertUppercase()
+func (m AssertModule) Uppercase(_ *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackArgs(f.Name(), args, kwargs); err != nil {
+		return starlark.None, err
+	}
+	return NewAssertUppercase(), nil
+}
+
+// caseCheck asserts that a string is unchanged when converted with "toCase" (i.e. contains no "otherCase" letters).
+func (m AssertModule) caseCheck(toCase func(string) string, otherCase string) core.StarlarkFunc {
+	return func(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if args.Len() != 1 {
+			return starlark.None, fmt.Errorf("check: got %d arguments, want %d", args.Len(), 1)
+		}
+		str, ok := args[0].(starlark.String)
+		if !ok {
+			return nil, fmt.Errorf("check: value must be a string, but was '%s'", args[0].Type())
+		}
+		for idx, char := range str.GoString() {
+			if toCase(string(char)) != string(char) {
+				return nil, fmt.Errorf("check: contains %s letter %q at index %d", otherCase, char, idx)
+			}
+		}
+		return starlark.True, nil
+	}
+}
+
+// NewAssertPattern produces an Assertion that a given string contains a match of the regular expression "pattern".
+func NewAssertPattern(pattern *regexp.Regexp) *Assertion {
+	return NewAssertionFromStarlarkFunc("assert.pattern", AssertModule{}.patternCheck(pattern))
+}
+
+func (m AssertModule) patternCheck(pattern *regexp.Regexp) core.StarlarkFunc {
+	return func(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if args.Len() != 1 {
+			return starlark.None, fmt.Errorf("check: got %d arguments, want %d", args.Len(), 1)
+		}
+		str, ok := args[0].(starlark.String)
+		if !ok {
+			return nil, fmt.Errorf("check: value must be a string, but was '%s'", args[0].Type())
+		}
+		if !pattern.MatchString(str.GoString()) {
+			return nil, fmt.Errorf("check: %s does not match", str.String())
+		}
+		return starlark.True, nil
+	}
+}
+
+// iso8601Duration matches durations in the form of ISO 8601 (e.g. "P30D", "PT1H30M"), having at least one component.
+var iso8601Duration = regexp.MustCompile(`^P(?:\d+(?:\.\d+)?[YMWD])*(?:T(?:\d+(?:\.\d+)?[HMS])+)?$`)
+
+// NewAssertDuration produces an Assertion that a given string is a duration: either as understood by Go (e.g. "30s",
+// "1h30m") or in the form of ISO 8601 (e.g. "P30D").
+func NewAssertDuration() *Assertion {
+	return NewAssertionFromStarlarkFunc("assert.duration", AssertModule{}.stringCheck(func(str string) error {
+		if _, err := time.ParseDuration(str); err == nil {
+			return nil
+		}
+		if str != "P" && iso8601Duration.MatchString(str) {
+			return nil
+		}
+		return fmt.Errorf("%q is not a duration", str)
+	}))
+}
+
+// NewAssertTimestamp produces an Assertion that a given string is a timestamp as specified by RFC 3339 (i.e. the
+// profile of ISO 8601 that is the "date-time" format of OpenAPI).
+func NewAssertTimestamp() *Assertion {
+	return NewAssertionFromStarlarkFunc("assert.timestamp", AssertModule{}.stringCheck(func(str string) error {
+		if _, err := time.Parse(time.RFC3339, str); err != nil {
+			return fmt.Errorf("%q is not a timestamp", str)
+		}
+		return nil
+	}))
+}
+
+// NewAssertValidYAML produces an Assertion that a given string is well-formed YAML (one or more documents).
+func NewAssertValidYAML() *Assertion {
+	return NewAssertionFromStarlarkFunc("assert.valid_yaml", AssertModule{}.stringCheck(func(str string) error {
+		if _, err := yamlmeta.NewDocumentSetFromBytes([]byte(str), yamlmeta.DocSetOpts{}); err != nil {
+			return fmt.Errorf("not valid YAML: %s", err)
+		}
+		return nil
+	}))
+}
+
+// NewAssertValidJSON produces an Assertion that a given string is well-formed JSON.
+func NewAssertValidJSON() *Assertion {
+	return NewAssertionFromStarlarkFunc("assert.valid_json", AssertModule{}.stringCheck(func(str string) error {
+		var decoded interface{}
+		if err := json.Unmarshal([]byte(str), &decoded); err != nil {
+			if syntaxErr, ok := err.(*json.SyntaxError); ok {
+				return fmt.Errorf("not valid JSON: %s (at offset %d)", syntaxErr, syntaxErr.Offset)
+			}
+			return fmt.Errorf("not valid JSON: %s", err)
+		}
+		return nil
+	}))
+}
+
+// NewAssertStartsWith produces an Assertion that a given string starts with "prefix".
+func NewAssertStartsWith(prefix starlark.String) *Assertion {
+	return NewAssertionFromStarlarkFunc("assert.starts_with", AssertModule{}.stringCheck(func(str string) error {
+		if !strings.HasPrefix(str, prefix.GoString()) {
+			return fmt.Errorf("%q does not start with %s", str, prefix.String())
+		}
+		return nil
+	}))
+}
+
+// NewAssertEndsWith produces an Assertion that a given string ends with "suffix".
+func NewAssertEndsWith(suffix starlark.String) *Assertion {
+	return NewAssertionFromStarlarkFunc("assert.ends_with", AssertModule{}.stringCheck(func(str string) error {
+		if !strings.HasSuffix(str, suffix.GoString()) {
+			return fmt.Errorf("%q does not end with %s", str, suffix.String())
+		}
+		return nil
+	}))
+}
+
+// Versions of the Internet Protocol that an address (or CIDR) can be required to be of (see NewAssertIP() and
+// NewAssertCIDR()).
+const (
+	IPVersionAny = "any"
+	IPVersion4   = "v4"
+	IPVersion6   = "v6"
+)
+
+// NewAssertIP produces an Assertion that a given string is an IP address of the given "version" (one of IPVersionAny,
+// IPVersion4, or IPVersion6).
+func NewAssertIP(version string) *Assertion {
+	return NewAssertionFromStarlarkFunc("assert.ip", AssertModule{}.stringCheck(func(str string) error {
+		ip := net.ParseIP(str)
+		if ip == nil || !isIPVersion(str, ip, version) {
+			return fmt.Errorf("%q is not %s address", str, ipVersionName(version))
+		}
+		return nil
+	}))
+}
+
+// NewAssertCIDR produces an Assertion that a given string is an IP address range in CIDR notation (e.g. "10.0.0.0/8")
+// of the given "version" (one of IPVersionAny, IPVersion4, or IPVersion6).
+func NewAssertCIDR(version string) *Assertion {
+	return NewAssertionFromStarlarkFunc("assert.cidr", AssertModule{}.stringCheck(func(str string) error {
+		ip, _, err := net.ParseCIDR(str)
+		if err != nil || !isIPVersion(str, ip, version) {
+			return fmt.Errorf("%q is not %s CIDR", str, ipVersionName(version))
+		}
+		return nil
+	}))
+}
+
+// isIPVersion reports whether "ip" (parsed from "str") is of "version". An IPv4-mapped IPv6 address (e.g.
+// "::ffff:10.0.0.1") is written as IPv6 and so is taken to be one.
+func isIPVersion(str string, ip net.IP, version string) bool {
+	isV4 := ip.To4() != nil && !strings.Contains(str, ":")
+	switch version {
+	case IPVersion4:
+		return isV4
+	case IPVersion6:
+		return !isV4
+	default:
+		return true
+	}
+}
+
+func ipVersionName(version string) string {
+	switch version {
+	case IPVersion4:
+		return "an IPv4"
+	case IPVersion6:
+		return "an IPv6"
+	default:
+		return "an IP"
+	}
+}
+
+// NewAssertURL produces an Assertion that a given string is an absolute URL (e.g. "https://example.com/hook") and, if
+// any "schemes" are given, that its scheme is one of them.
+func NewAssertURL(schemes []string) *Assertion {
+	return NewAssertionFromStarlarkFunc("assert.url", AssertModule{}.stringCheck(func(str string) error {
+		u, err := url.Parse(str)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("%q is not an absolute URL", str)
+		}
+		if len(schemes) == 0 {
+			return nil
+		}
+		for _, scheme := range schemes {
+			if strings.EqualFold(u.Scheme, scheme) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%q has the scheme %q", str, u.Scheme)
+	}))
+}
+
+// NewAssertSemver produces an Assertion that a given string is a semantic version (e.g. "1.2.3") and, if a
+// "versionRange" is given (e.g. ">= 1.2.0, < 2.0.0"), that it is a version in that range.
+func NewAssertSemver(versionRange string) (*Assertion, error) {
+	var constraints semver.Constraints
+	if versionRange != "" {
+		var err error
+		constraints, err = semver.NewConstraint(versionRange)
+		if err != nil {
+			return nil, err
+		}
+	}
+	semverRegex := regexp.MustCompile(SemverRegex)
+	return NewAssertionFromStarlarkFunc("assert.semver", AssertModule{}.stringCheck(func(str string) error {
+		if !semverRegex.MatchString(str) {
+			return fmt.Errorf("%q is not a semantic version", str)
+		}
+		version, err := semver.NewVersion(str)
+		if err != nil {
+			return fmt.Errorf("%q is not a semantic version: %s", str, err)
+		}
+		if !constraints.Check(version) {
+			return fmt.Errorf("%q is not in the range %s", str, versionRange)
+		}
+		return nil
+	})), nil
+}
+
+// stringCheck asserts that a value is a string that satisfies "check".
+func (m AssertModule) stringCheck(check func(string) error) core.StarlarkFunc {
+	return func(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if args.Len() != 1 {
+			return starlark.None, fmt.Errorf("check: got %d arguments, want %d", args.Len(), 1)
+		}
+		str, ok := args[0].(starlark.String)
+		if !ok {
+			return nil, fmt.Errorf("check: value must be a string, but was '%s'", args[0].Type())
+		}
+		if err := check(str.GoString()); err != nil {
+			return nil, fmt.Errorf("check: %s", err)
+		}
+		return starlark.True, nil
+	}
+}
+
+// NewAssertContiguous produces an Assertion that the items of a given list — or, if "key" is given, the values of
+// that key in each item — are unique integers that, together, form the range starting at "start" with no gaps.
+func NewAssertContiguous(start starlark.Int, key starlark.Value) *Assertion {
+	return NewAssertionFromStarlarkFunc("assert.contiguous", AssertModule{}.contiguousCheck(start, key))
+}
+
+// Contiguous is a core.StarlarkFunc wrapping NewAssertContiguous()
+func (m AssertModule) Contiguous(_ *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	start := starlark.MakeInt(1)
+	var key starlark.Value = starlark.None
+	if err := starlark.UnpackArgs(f.Name(), args, kwargs, "start?", &start, "key?", &key); err != nil {
+		return starlark.None, err
+	}
+	return NewAssertContiguous(start, key), nil
+}
+
+func (m AssertModule) contiguousCheck(start starlark.Int, key starlark.Value) core.StarlarkFunc {
+	return func(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if args.Len() != 1 {
+			return starlark.None, fmt.Errorf("check: got %d arguments, want %d", args.Len(), 1)
+		}
+		val, err := m.yamlEncodeDecode(args[0])
+		if err != nil {
+			return nil, err
+		}
+		list, ok := val.(*starlark.List)
+		if !ok {
+			return nil, fmt.Errorf("check: value must be a list, but was '%s'", val.Type())
+		}
+
+		first, ok := start.Int64()
+		if !ok {
+			return nil, fmt.Errorf("check: start must fit in 64 bits, but was %s", start.String())
+		}
+		indexOf := map[int64]int{}
+		for idx := 0; idx < list.Len(); idx++ {
+			num, err := m.contiguousItem(list.Index(idx), key)
+			if err != nil {
+				return nil, fmt.Errorf("check: item at index %d %s", idx, err)
+			}
+			if prevIdx, found := indexOf[num]; found {
+				return nil, fmt.Errorf("check: %d appears more than once (at index %d and %d)", num, prevIdx, idx)
+			}
+			indexOf[num] = idx
+		}
+		for num := first; num < first+int64(list.Len()); num++ {
+			if _, found := indexOf[num]; !found {
+				return nil, fmt.Errorf("check: %d is missing (expected %d through %d)", num, first, first+int64(list.Len())-1)
+			}
+		}
+		return starlark.True, nil
+	}
+}
+
+// contiguousItem provides the number that "item" contributes to a range: itself or, if "key" is not None, the value
+// at that key.
+func (m AssertModule) contiguousItem(item starlark.Value, key starlark.Value) (int64, error) {
+	if key != starlark.None {
+		mapping, ok := item.(starlark.Mapping)
+		if !ok {
+			return 0, fmt.Errorf("must be a map, but was '%s'", item.Type())
+		}
+		val, found, err := mapping.Get(key)
+		if err != nil {
+			return 0, err
+		}
+		if !found {
+			return 0, fmt.Errorf("has no %s", key.String())
+		}
+		item = val
+	}
+	num, ok := item.(starlark.Int)
+	if !ok {
+		return 0, fmt.Errorf("must be an int, but was '%s'", item.Type())
+	}
+	val, ok := num.Int64()
+	if !ok {
+		return 0, fmt.Errorf("must fit in 64 bits, but was %s", num.String())
+	}
+	return val, nil
+}
+
+// NewAssertEach produces an Assertion that each item of a given list satisfies "assertion".
+func NewAssertEach(assertion starlark.Callable) *Assertion {
+	return NewAssertionFromStarlarkFunc("assert.each", AssertModule{}.eachCheck(assertion))
+}
+
+func (m AssertModule) eachCheck(assertion starlark.Callable) core.StarlarkFunc {
+	return func(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if args.Len() != 1 {
+			return starlark.None, fmt.Errorf("check: got %d arguments, want %d", args.Len(), 1)
+		}
+		val, err := m.yamlEncodeDecode(args[0])
+		if err != nil {
+			return nil, err
+		}
+		list, ok := val.(*starlark.List)
+		if !ok {
+			return nil, fmt.Errorf("check: value must be a list, but was '%s'", val.Type())
+		}
+
+		for idx := 0; idx < list.Len(); idx++ {
+			result, err := starlark.Call(thread, assertion, starlark.Tuple{list.Index(idx)}, []starlark.Tuple{})
+			if err != nil {
+				return nil, fmt.Errorf("check: item at index %d: %s", idx, strings.TrimPrefix(strings.TrimPrefix(err.Error(), "fail: "), "check: "))
+			}
+			if result != starlark.True {
+				return nil, fmt.Errorf("check: item at index %d (%s) is not valid", idx, list.Index(idx).String())
+			}
+		}
+		return starlark.True, nil
+	}
+}
+
+// NewAssertValues produces an Assertion that the value of each item of a given map satisfies "assertion".
+func NewAssertValues(assertion starlark.Callable) *Assertion {
+	return NewAssertionFromStarlarkFunc("assert.values", AssertModule{}.valuesCheck(assertion))
+}
+
+func (m AssertModule) valuesCheck(assertion starlark.Callable) core.StarlarkFunc {
+	return func(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if args.Len() != 1 {
+			return starlark.None, fmt.Errorf("check: got %d arguments, want %d", args.Len(), 1)
+		}
+		val, err := m.yamlEncodeDecode(args[0])
+		if err != nil {
+			return nil, err
+		}
+		dict, ok := val.(*starlark.Dict)
+		if !ok {
+			return nil, fmt.Errorf("check: value must be a map or dict, but was '%s'", val.Type())
+		}
+
+		for _, item := range dict.Items() {
+			key, value := item[0], item[1]
+			result, err := starlark.Call(thread, assertion, starlark.Tuple{value}, []starlark.Tuple{})
+			if err != nil {
+				return nil, fmt.Errorf("check: value at key %s: %s", key.String(), strings.TrimPrefix(strings.TrimPrefix(err.Error(), "fail: "), "check: "))
+			}
+			if result != starlark.True {
+				return nil, fmt.Errorf("check: value at key %s (%s) is not valid", key.String(), value.String())
+			}
+		}
+		return starlark.True, nil
+	}
+}
+
+// NewAssertAll produces an Assertion that a given value satisfies every one of "assertions" (checked in order).
+func NewAssertAll(assertions []starlark.Callable) *Assertion {
+	return NewAssertionFromStarlarkFunc("assert.all", AssertModule{}.allCheck(assertions))
+}
+
+// All is a core.StarlarkFunc wrapping NewAssertAll()
+func (m AssertModule) All(_ *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, _ []starlark.Tuple) (starlark.Value, error) {
+	assertions, err := m.assertionsArg(args)
+	if err != nil {
+		return nil, err
+	}
+	return NewAssertAll(assertions), nil
+}
+
+func (m AssertModule) allCheck(assertions []starlark.Callable) core.StarlarkFunc {
+	return func(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if args.Len() != 1 {
+			return starlark.None, fmt.Errorf("check: got %d arguments, want %d", args.Len(), 1)
+		}
+		for _, assertion := range assertions {
+			if err := m.satisfies(thread, assertion, args[0]); err != nil {
+				return nil, fmt.Errorf("check: %s", err)
+			}
+		}
+		return starlark.True, nil
+	}
+}
+
+// NewAssertAny produces an Assertion that a given value satisfies at least one of "assertions".
+func NewAssertAny(assertions []starlark.Callable) *Assertion {
+	return NewAssertionFromStarlarkFunc("assert.any", AssertModule{}.anyCheck(assertions))
+}
+
+// Any is a core.StarlarkFunc wrapping NewAssertAny()
+func (m AssertModule) Any(_ *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, _ []starlark.Tuple) (starlark.Value, error) {
+	assertions, err := m.assertionsArg(args)
+	if err != nil {
+		return nil, err
+	}
+	return NewAssertAny(assertions), nil
+}
+
+func (m AssertModule) anyCheck(assertions []starlark.Callable) core.StarlarkFunc {
+	return func(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if args.Len() != 1 {
+			return starlark.None, fmt.Errorf("check: got %d arguments, want %d", args.Len(), 1)
+		}
+		var failures []string
+		for _, assertion := range assertions {
+			err := m.satisfies(thread, assertion, args[0])
+			if err == nil {
+				return starlark.True, nil
+			}
+			failures = append(failures, err.Error())
+		}
+		return nil, fmt.Errorf("check: satisfies none of the assertions (%s)", strings.Join(failures, "; "))
+	}
+}
+
+// assertionsArg unpacks the single argument of All() and Any(): a list of Assertions and/or functions.
+func (m AssertModule) assertionsArg(args starlark.Tuple) ([]starlark.Callable, error) {
+	if args.Len() != 1 {
+		return nil, fmt.Errorf("got %d arguments, want %d", args.Len(), 1)
+	}
+	seq, ok := args[0].(starlark.Sequence)
+	if !ok {
+		return nil, fmt.Errorf("expected a list of assertions, but was a '%s'", args[0].Type())
+	}
+
+	var assertions []starlark.Callable
+	var item starlark.Value
+	items := seq.Iterate()
+	defer items.Done()
+	for idx := 0; items.Next(&item); idx++ {
+		switch typedItem := item.(type) {
+		case *Assertion:
+			assertions = append(assertions, typedItem.CheckFunc())
+		case starlark.Callable:
+			assertions = append(assertions, typedItem)
+		default:
+			return nil, fmt.Errorf("expected item at index %d to be an assertion or a function, but was a '%s'", idx, item.Type())
+		}
+	}
+	if len(assertions) == 0 {
+		return nil, fmt.Errorf("expected at least one assertion, but the list is empty")
+	}
+	return assertions, nil
+}
+
+// satisfies calls "assertion" on "value", describing why it is not satisfied (if that is the case).
+func (m AssertModule) satisfies(thread *starlark.Thread, assertion starlark.Callable, value starlark.Value) error {
+	result, err := starlark.Call(thread, assertion, starlark.Tuple{value}, []starlark.Tuple{})
+	if err != nil {
+		return fmt.Errorf("%s", strings.TrimPrefix(strings.TrimPrefix(err.Error(), "fail: "), "check: "))
+	}
+	if result != starlark.True {
+		return fmt.Errorf("value is not valid")
+	}
+	return nil
+}
+
 // NewAssertOneOf produces an Assertion that a given value is one of a pre-defined set.
 //
 // see also:https://github.com/google/starlark-go/blob/master/doc/spec.md#membership-tests
@@ -410,6 +1319,36 @@ func (m AssertModule) OneOf(_ *starlark.Thread, _ *starlark.Builtin, args starla
 	return maxFunc, nil
 }
 
+// NewAssertNotOneOf produces an Assertion that a given value is none of a pre-defined set.
+//
+// see also:https://github.com/google/starlark-go/blob/master/doc/spec.md#membership-tests
+func NewAssertNotOneOf(blocklist starlark.Sequence) *Assertion {
+	return NewAssertionFromSource(
+		"assert.not_one_of",
+		`lambda val: yaml.decode(yaml.encode(val)) not in yaml.decode(yaml.encode(blocklist)) or fail("one of disallowed values")`,
+		starlark.StringDict{"blocklist": blocklist, "yaml": YAMLAPI["yaml"]},
+	)
+}
+
+// NotOneOf is a core.StarlarkFunc wrapping NewAssertNotOneOf()
+func (m AssertModule) NotOneOf(_ *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, _ []starlark.Tuple) (starlark.Value, error) {
+	if args.Len() == 0 {
+		return starlark.None, fmt.Errorf("got %d arguments, want at least %d", args.Len(), 1)
+	}
+
+	blocklist := args[0]
+	if args.Len() > 1 {
+		blocklist = args
+	}
+
+	seq, ok := blocklist.(starlark.Sequence)
+	if !ok {
+		return nil, fmt.Errorf("expected a sequence, but was a '%s'", blocklist.Type())
+	}
+
+	return NewAssertNotOneOf(seq), nil
+}
+
 func (m AssertModule) yamlEncodeDecode(val starlark.Value) (starlark.Value, error) {
 	yaml := yamlModule{}
 	value, err := core.NewStarlarkValue(val).AsGoValue()
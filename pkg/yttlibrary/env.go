@@ -0,0 +1,76 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package yttlibrary
+
+import (
+	"fmt"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/k14s/starlark-go/starlarkstruct"
+	"github.com/vmware-tanzu/carvel-ytt/pkg/template/core"
+)
+
+// EnvModule contains the definition of the @ytt:env module.
+//
+// Templates are hermetic: the only environment variables that can be read are those explicitly allowed (typically,
+// via the --allow-env-defaults flag). This is intended for sourcing default values in schema:
+//
+//	#@ load("@ytt:env", "env")
+//	#@data/values-schema
+//	---
+//	#@schema/default env.get("DB_HOST", default="localhost")
+//	db_host: ""
+type EnvModule struct {
+	allowed   []string
+	lookupEnv func(string) (string, bool)
+}
+
+// NewEnvModule constructs a new instance of EnvModule that reads only the `allowed` variables, using `lookupEnv`.
+func NewEnvModule(allowed []string, lookupEnv func(string) (string, bool)) EnvModule {
+	return EnvModule{allowed: allowed, lookupEnv: lookupEnv}
+}
+
+// AsModule produces the corresponding Starlark module definition suitable for use in running a Starlark program.
+func (m EnvModule) AsModule() starlark.StringDict {
+	return starlark.StringDict{
+		"env": &starlarkstruct.Module{
+			Name: "env",
+			Members: starlark.StringDict{
+				"get": starlark.NewBuiltin("env.get", core.ErrWrapper(m.Get)),
+			},
+		},
+	}
+}
+
+// Get is a core.StarlarkFunc that provides the value of an (allowed) environment variable.
+func (m EnvModule) Get(thread *starlark.Thread, f *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	var defaultVal starlark.Value
+	if err := starlark.UnpackArgs(f.Name(), args, kwargs, "name", &name, "default?", &defaultVal); err != nil {
+		return starlark.None, err
+	}
+
+	if !m.isAllowed(name) {
+		return starlark.None, fmt.Errorf("Reading environment variable '%s' is not allowed "+
+			"(hint: to allow it, also specify --allow-env-defaults=%s)", name, name)
+	}
+
+	val, found := m.lookupEnv(name)
+	if !found {
+		if defaultVal != nil {
+			return defaultVal, nil
+		}
+		return starlark.None, fmt.Errorf("Expected environment variable '%s' to be set (hint: specify default= for when it is not)", name)
+	}
+	return starlark.String(val), nil
+}
+
+func (m EnvModule) isAllowed(name string) bool {
+	for _, allowed := range m.allowed {
+		if allowed == name {
+			return true
+		}
+	}
+	return false
+}
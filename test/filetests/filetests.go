@@ -253,7 +253,7 @@ func (l DefaultTemplateLoader) FindCompiledTemplate(_ string) *template.Compiled
 // DefaultTemplateLoader.DataValues)
 func (l DefaultTemplateLoader) Load(_ *starlark.Thread, module string) (starlark.StringDict, error) {
 	api := yttlibrary.NewAPI(l.CompiledTemplate.TplReplaceNode,
-		yttlibrary.NewDataModule(&l.DataValues, nil), nil, nil)
+		yttlibrary.NewDataModule(&l.DataValues, nil), nil, yttlibrary.NewEnvModule(nil, os.LookupEnv), nil)
 	return api.FindModule(strings.TrimPrefix(module, "@ytt:"))
 }
 